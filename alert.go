@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertSuppressor deduplicates alerts for final errors, so an outage that fails
+// many retry loops with the same error raises a single alert per window.
+// Errors are grouped by a fingerprint of the types in their chain and their
+// normalized message.
+//
+// It is safe for concurrent use.
+type AlertSuppressor struct {
+	window    time.Duration
+	normalize func(msg string) string
+	now       func() time.Time
+
+	lock      sync.Mutex
+	alerted   map[string]time.Time
+	lastPrune time.Time
+}
+
+// AlertSuppressorOption is an option that configures an [AlertSuppressor].
+type AlertSuppressorOption func(s *AlertSuppressor)
+
+// WithAlertNormalizer sets the function that removes volatile parts, such as
+// request IDs, from error messages before fingerprinting. It defaults to
+// [NormalizeErrorMessage].
+func WithAlertNormalizer(normalize func(msg string) string) AlertSuppressorOption {
+	return func(s *AlertSuppressor) {
+		if normalize != nil {
+			s.normalize = normalize
+		}
+	}
+}
+
+// WithAlertNowFunc sets the function used by the suppressor to read the current
+// time. It defaults to [time.Now] and is primarily useful for driving time in
+// tests.
+func WithAlertNowFunc(now func() time.Time) AlertSuppressorOption {
+	return func(s *AlertSuppressor) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// NewAlertSuppressor creates a new alert suppressor that allows one alert per
+// fingerprint every window.
+func NewAlertSuppressor(window time.Duration, opts ...AlertSuppressorOption) *AlertSuppressor {
+	s := &AlertSuppressor{
+		window:    window,
+		normalize: NormalizeErrorMessage,
+		now:       time.Now,
+		alerted:   make(map[string]time.Time),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ShouldAlert reports whether err should raise an alert. It returns true for
+// the first error with a given fingerprint, and false for errors with the same
+// fingerprint until the window since that alert has passed. It returns false
+// for a nil error.
+func (s *AlertSuppressor) ShouldAlert(err error) bool {
+	if err == nil {
+		return false
+	}
+	fp := s.fingerprint(err)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	if at, ok := s.alerted[fp]; ok && now.Sub(at) < s.window {
+		return false
+	}
+	s.alerted[fp] = now
+	return true
+}
+
+// prune removes fingerprints whose window has passed, at most once per window.
+func (s *AlertSuppressor) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < s.window {
+		return
+	}
+	s.lastPrune = now
+
+	for fp, at := range s.alerted {
+		if now.Sub(at) >= s.window {
+			delete(s.alerted, fp)
+		}
+	}
+}
+
+// fingerprint returns the types in err's chain, depth first, followed by its
+// normalized message.
+func (s *AlertSuppressor) fingerprint(err error) string {
+	var b strings.Builder
+	var walk func(err error)
+	walk = func(err error) {
+		fmt.Fprintf(&b, "%T;", err)
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			if e := x.Unwrap(); e != nil {
+				walk(e)
+			}
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				if e != nil {
+					walk(e)
+				}
+			}
+		}
+	}
+	walk(err)
+
+	b.WriteString(s.normalize(err.Error()))
+	return b.String()
+}
+
+var (
+	uuidPattern = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	ipv4Pattern = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	ipv6Pattern = regexp.MustCompile(`\[[0-9a-zA-Z:.%]*:[0-9a-zA-Z:.%]*\](?::\d+)?`)
+	hexPattern  = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`)
+)
+
+// NormalizeErrorMessage replaces the volatile parts of an error message, such
+// as UUIDs, IP addresses and ports, and hexadecimal pointers, with
+// placeholders. It is the default normalizer of [AlertSuppressor].
+func NormalizeErrorMessage(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = ipv6Pattern.ReplaceAllString(msg, "<addr>")
+	msg = ipv4Pattern.ReplaceAllString(msg, "<addr>")
+	msg = hexPattern.ReplaceAllString(msg, "<hex>")
+	return msg
+}
+
+// WithOnGiveUpSuppressed registers a function that is called when [Do] or
+// [DoValue] gives up, like [WithStopHook], but only if s reports that the final
+// error should raise an alert.
+func WithOnGiveUpSuppressed(s *AlertSuppressor, fn func(reason StopReason, err error)) DoOption {
+	return WithStopHook(func(reason StopReason, err error) {
+		if s.ShouldAlert(err) {
+			fn(reason, err)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAlertSuppressor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("window", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		s := retry.NewAlertSuppressor(time.Minute, retry.WithAlertNowFunc(clock.Now))
+
+		err := fmt.Errorf("dial: %w", io.EOF)
+		if !s.ShouldAlert(err) {
+			t.Error("expected first error to alert")
+		}
+
+		clock.Advance(59 * time.Second)
+		if s.ShouldAlert(err) {
+			t.Error("expected error within window to be suppressed")
+		}
+
+		clock.Advance(1 * time.Second)
+		if !s.ShouldAlert(err) {
+			t.Error("expected error after window to alert")
+		}
+		if s.ShouldAlert(err) {
+			t.Error("expected error in new window to be suppressed")
+		}
+	})
+
+	t.Run("distinct", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		s := retry.NewAlertSuppressor(time.Minute, retry.WithAlertNowFunc(clock.Now))
+
+		errs := []error{
+			io.EOF,
+			fmt.Errorf("read: %w", io.EOF),
+			fmt.Errorf("read: %w", io.ErrUnexpectedEOF),
+			&net.OpError{Op: "dial", Net: "tcp", Err: io.EOF},
+		}
+		for i, err := range errs {
+			if !s.ShouldAlert(err) {
+				t.Errorf("%d: expected %v to alert", i, err)
+			}
+		}
+		for i, err := range errs {
+			if s.ShouldAlert(err) {
+				t.Errorf("%d: expected %v to be suppressed", i, err)
+			}
+		}
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewAlertSuppressor(time.Minute)
+
+		if !s.ShouldAlert(fmt.Errorf("request 9b2f4c3e-6a7d-4e1f-8c2b-0d9e8f7a6b5c to 10.0.0.1:8080 failed: %w", io.EOF)) {
+			t.Error("expected first error to alert")
+		}
+		if s.ShouldAlert(fmt.Errorf("request 0f1e2d3c-4b5a-4968-8776-a5b4c3d2e1f0 to 10.0.0.2:9090 failed: %w", io.EOF)) {
+			t.Error("expected error differing by volatile parts to be suppressed")
+		}
+	})
+
+	t.Run("custom_normalizer", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewAlertSuppressor(time.Minute, retry.WithAlertNormalizer(func(string) string {
+			return ""
+		}))
+
+		if !s.ShouldAlert(errors.New("a")) {
+			t.Error("expected first error to alert")
+		}
+		if s.ShouldAlert(errors.New("b")) {
+			t.Error("expected error with the same normalized message to be suppressed")
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if retry.NewAlertSuppressor(time.Minute).ShouldAlert(nil) {
+			t.Error("expected nil to not alert")
+		}
+	})
+}
+
+func TestNormalizeErrorMessage(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		msg  string
+		exp  string
+	}{
+		{
+			name: "uuid",
+			msg:  "job 9B2F4C3E-6A7D-4E1F-8C2B-0D9E8F7A6B5C failed",
+			exp:  "job <uuid> failed",
+		},
+		{
+			name: "ipv4",
+			msg:  "dial tcp 192.168.1.10:5432: connection refused",
+			exp:  "dial tcp <addr>: connection refused",
+		},
+		{
+			name: "ipv6",
+			msg:  "dial tcp [::1]:5432: connection refused",
+			exp:  "dial tcp <addr>: connection refused",
+		},
+		{
+			name: "hex",
+			msg:  "bad handle 0xc000123456",
+			exp:  "bad handle <hex>",
+		},
+		{
+			name: "unchanged",
+			msg:  "status 503",
+			exp:  "status 503",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := retry.NormalizeErrorMessage(tc.msg), tc.exp; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithOnGiveUpSuppressed(t *testing.T) {
+	t.Parallel()
+
+	s := retry.NewAlertSuppressor(time.Hour)
+
+	var alerts int
+	for i := 0; i < 5; i++ {
+		_ = retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(fmt.Errorf("dial 10.0.0.%d:443: %w", i, io.EOF))
+		}, retry.WithOnGiveUpSuppressed(s, func(_ retry.StopReason, _ error) {
+			alerts++
+		}))
+	}
+	if got, want := alerts, 1; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+package retry
+
+import (
+	"math"
+	"time"
+)
+
+// InfiniteDuration is returned by [Analysis] methods when a duration is
+// unbounded.
+const InfiniteDuration = time.Duration(math.MaxInt64)
+
+// Analysis describes the worst-case and expected behavior of a [Policy]. It is
+// computed analytically from the policy's delays and does not account for the
+// time spent in each attempt.
+type Analysis struct {
+	// MaxAttempts is the maximum number of attempts, including the first,
+	// ignoring jitter. It is +Inf if the policy never stops.
+	MaxAttempts float64
+
+	// MaxTotalSleep is the worst-case total time spent sleeping between
+	// attempts, using the largest possible jitter. It is [InfiniteDuration] if
+	// the policy never stops.
+	MaxTotalSleep time.Duration
+
+	// nominal is the schedule of delays ignoring jitter.
+	nominal schedule
+}
+
+// Analyze computes the analysis of the policy p.
+func Analyze(p Policy) Analysis {
+	nominal := p.schedule(false)
+	worst := p.schedule(true)
+
+	return Analysis{
+		MaxAttempts:   1 + nominal.len(),
+		MaxTotalSleep: worst.total(),
+		nominal:       nominal,
+	}
+}
+
+// ExpectedSleep returns the expected total time spent sleeping between attempts
+// if each attempt independently succeeds with probability successProb, ignoring
+// jitter. It returns [InfiniteDuration] if the policy never stops and
+// successProb is 0.
+func (a Analysis) ExpectedSleep(successProb float64) time.Duration {
+	if math.IsNaN(successProb) || successProb <= 0 {
+		return a.nominal.total()
+	}
+	if successProb >= 1 {
+		return 0
+	}
+
+	// The sleep before retry i happens only if the first i attempts failed,
+	// which has probability r^i.
+	r := 1 - successProb
+	s := a.nominal
+
+	var expected float64
+	var i float64
+	for _, d := range s.prefix {
+		i++
+		expected += math.Pow(r, i) * float64(d)
+	}
+
+	// Geometric series for the steady sleeps from i+1 to i+count.
+	if s.count > 0 {
+		first := math.Pow(r, i+1)
+		tail := 0.0
+		if !math.IsInf(s.count, 1) {
+			tail = math.Pow(r, i+1+s.count)
+		}
+		expected += float64(s.steady) * (first - tail) / (1 - r)
+		i += s.count
+	}
+
+	if s.final > 0 && !math.IsInf(i, 1) {
+		i++
+		expected += math.Pow(r, i) * float64(s.final)
+	}
+
+	if expected >= math.MaxInt64 {
+		return InfiniteDuration
+	}
+	return time.Duration(expected)
+}
+
+// schedule is a compact description of the sleeps between attempts: the prefix
+// sleeps, followed by count sleeps of the steady duration, followed by a final
+// sleep that was truncated by the maximum duration.
+type schedule struct {
+	prefix []time.Duration
+	steady time.Duration
+	count  float64
+	final  time.Duration
+}
+
+// len returns the number of sleeps, or +Inf if unbounded.
+func (s schedule) len() float64 {
+	n := float64(len(s.prefix)) + s.count
+	if s.final > 0 {
+		n++
+	}
+	return n
+}
+
+// total returns the total duration of all sleeps, or InfiniteDuration if
+// unbounded.
+func (s schedule) total() time.Duration {
+	if math.IsInf(s.count, 1) {
+		return InfiniteDuration
+	}
+
+	var total time.Duration
+	for _, d := range s.prefix {
+		total = addDuration(total, d)
+	}
+	if s.count > 0 {
+		if float64(s.steady)*s.count >= float64(math.MaxInt64-total) {
+			return InfiniteDuration
+		}
+		total = addDuration(total, time.Duration(float64(s.steady)*s.count))
+	}
+	return addDuration(total, s.final)
+}
+
+// schedule computes the sleeps between attempts for the policy. Delays are
+// computed one at a time until they reach a steady state, where every later
+// delay is the same, and the remainder is computed arithmetically.
+func (p Policy) schedule(worst bool) schedule {
+	var s schedule
+	var sum time.Duration
+
+	maxRetries := math.Inf(1)
+	if p.MaxAttempts > 0 {
+		maxRetries = float64(p.MaxAttempts - 1)
+	}
+
+	i := uint64(1)
+	for {
+		if float64(i) > maxRetries {
+			return s
+		}
+
+		d := p.delay(i, worst)
+		if p.MaxDuration > 0 && d >= p.MaxDuration-sum {
+			s.final = p.MaxDuration - sum
+			return s
+		}
+
+		if p.Algorithm == AlgorithmConstant || d == math.MaxInt64 || (p.Cap > 0 && d == p.Cap) {
+			s.steady = d
+			break
+		}
+
+		s.prefix = append(s.prefix, d)
+		sum += d
+		i++
+	}
+
+	// Every delay from retry i onward is s.steady.
+	remaining := maxRetries - float64(i) + 1
+	if p.MaxDuration <= 0 {
+		s.count = remaining
+		return s
+	}
+
+	budget := p.MaxDuration - sum
+	full := float64(budget / s.steady)
+	rem := budget % s.steady
+	if full >= remaining {
+		s.count = remaining
+		return s
+	}
+	s.count = full
+	s.final = rem
+	return s
+}
@@ -0,0 +1,243 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	inf := math.Inf(1)
+
+	cases := []struct {
+		name          string
+		policy        retry.Policy
+		maxAttempts   float64
+		maxTotalSleep time.Duration
+		expected      map[float64]time.Duration
+	}{
+		{
+			name: "constant",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				MaxAttempts: 4,
+			},
+			maxAttempts:   4,
+			maxTotalSleep: 3 * time.Second,
+			expected: map[float64]time.Duration{
+				0:   3 * time.Second,
+				0.5: 875 * time.Millisecond, // 1/2 + 1/4 + 1/8
+				1:   0,
+			},
+		},
+		{
+			name: "exponential",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmExponential,
+				Base:        1 * time.Second,
+				MaxAttempts: 5,
+			},
+			maxAttempts:   5,
+			maxTotalSleep: 15 * time.Second, // 1 + 2 + 4 + 8
+			expected: map[float64]time.Duration{
+				0.5: 2 * time.Second, // 1/2 + 2/4 + 4/8 + 8/16
+			},
+		},
+		{
+			name: "fibonacci_capped",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmFibonacci,
+				Base:        1 * time.Second,
+				Cap:         4 * time.Second,
+				MaxAttempts: 6,
+			},
+			maxAttempts:   6,
+			maxTotalSleep: 14 * time.Second, // 1 + 2 + 3 + 4 + 4
+			expected: map[float64]time.Duration{
+				0.5: 1750 * time.Millisecond, // 1/2 + 2/4 + 3/8 + 4/16 + 4/32
+			},
+		},
+		{
+			name: "jitter_worst_case",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				Jitter:      500 * time.Millisecond,
+				MaxAttempts: 3,
+			},
+			maxAttempts:   3,
+			maxTotalSleep: 3 * time.Second, // 1.5 + 1.5
+			expected: map[float64]time.Duration{
+				0: 2 * time.Second, // jitter is ignored
+			},
+		},
+		{
+			name: "jitter_percent_worst_case",
+			policy: retry.Policy{
+				Algorithm:     retry.AlgorithmConstant,
+				Base:          1 * time.Second,
+				JitterPercent: 10,
+				MaxAttempts:   3,
+			},
+			maxAttempts:   3,
+			maxTotalSleep: 2200 * time.Millisecond, // 1.1 + 1.1
+		},
+		{
+			name: "exponential_max_duration",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmExponential,
+				Base:        1 * time.Second,
+				MaxDuration: 10 * time.Second,
+			},
+			maxAttempts:   5,                // sleeps of 1, 2, 4, and 3 (truncated)
+			maxTotalSleep: 10 * time.Second, // 1 + 2 + 4 + 3
+			expected: map[float64]time.Duration{
+				0.5: 1687500 * time.Microsecond, // 1/2 + 2/4 + 4/8 + 3/16
+			},
+		},
+		{
+			name: "constant_max_duration",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				MaxDuration: 2500 * time.Millisecond,
+			},
+			maxAttempts:   4, // sleeps of 1, 1, and 0.5 (truncated)
+			maxTotalSleep: 2500 * time.Millisecond,
+			expected: map[float64]time.Duration{
+				0.5: 812500 * time.Microsecond, // 1/2 + 1/4 + 0.5/8
+			},
+		},
+		{
+			name: "max_attempts_before_max_duration",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				MaxAttempts: 3,
+				MaxDuration: 1 * time.Hour,
+			},
+			maxAttempts:   3,
+			maxTotalSleep: 2 * time.Second,
+		},
+		{
+			name: "single_attempt",
+			policy: retry.Policy{
+				Algorithm:   retry.AlgorithmExponential,
+				Base:        1 * time.Second,
+				MaxAttempts: 1,
+			},
+			maxAttempts:   1,
+			maxTotalSleep: 0,
+			expected: map[float64]time.Duration{
+				0: 0,
+			},
+		},
+		{
+			name: "unbounded_constant",
+			policy: retry.Policy{
+				Algorithm: retry.AlgorithmConstant,
+				Base:      1 * time.Second,
+			},
+			maxAttempts:   inf,
+			maxTotalSleep: retry.InfiniteDuration,
+			expected: map[float64]time.Duration{
+				0:   retry.InfiniteDuration,
+				0.5: 1 * time.Second, // 1/2 + 1/4 + 1/8 + ...
+			},
+		},
+		{
+			name: "unbounded_capped_exponential",
+			policy: retry.Policy{
+				Algorithm: retry.AlgorithmExponential,
+				Base:      1 * time.Second,
+				Cap:       4 * time.Second,
+			},
+			maxAttempts:   inf,
+			maxTotalSleep: retry.InfiniteDuration,
+			expected: map[float64]time.Duration{
+				0:   retry.InfiniteDuration,
+				0.5: 2 * time.Second, // 1/2 + 2/4 + 4/8 + 4/16 + 4/32 + ...
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := retry.Analyze(tc.policy)
+
+			if got, want := a.MaxAttempts, tc.maxAttempts; got != want {
+				t.Errorf("MaxAttempts: expected %v to be %v", got, want)
+			}
+			if got, want := a.MaxTotalSleep, tc.maxTotalSleep; got != want {
+				t.Errorf("MaxTotalSleep: expected %v to be %v", got, want)
+			}
+			for p, want := range tc.expected {
+				got := a.ExpectedSleep(p)
+				if diff := got - want; diff < -time.Microsecond || diff > time.Microsecond {
+					t.Errorf("ExpectedSleep(%v): expected %v to be %v", p, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyze_matchesBackoff(t *testing.T) {
+	t.Parallel()
+
+	policies := []retry.Policy{
+		{Algorithm: retry.AlgorithmConstant, Base: 1 * time.Second, MaxAttempts: 4},
+		{Algorithm: retry.AlgorithmExponential, Base: 1 * time.Second, MaxAttempts: 10},
+		{Algorithm: retry.AlgorithmFibonacci, Base: 1 * time.Second, Cap: 10 * time.Second, MaxAttempts: 12},
+	}
+
+	for _, p := range policies {
+		b := p.Backoff()
+
+		var attempts float64 = 1
+		var total time.Duration
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			attempts++
+			total += val
+		}
+
+		a := retry.Analyze(p)
+		if got, want := a.MaxAttempts, attempts; got != want {
+			t.Errorf("%v: expected %v to be %v", p.Algorithm, got, want)
+		}
+		if got, want := a.MaxTotalSleep, total; got != want {
+			t.Errorf("%v: expected %v to be %v", p.Algorithm, got, want)
+		}
+	}
+}
+
+func ExampleAnalyze() {
+	a := retry.Analyze(retry.Policy{
+		Algorithm:   retry.AlgorithmExponential,
+		Base:        1 * time.Second,
+		Cap:         30 * time.Second,
+		MaxAttempts: 8,
+	})
+
+	fmt.Println(a.MaxAttempts)
+	fmt.Println(a.MaxTotalSleep)
+	fmt.Println(a.ExpectedSleep(0.5))
+
+	// Output:
+	// 8
+	// 1m31s
+	// 3.203125s
+}
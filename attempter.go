@@ -0,0 +1,260 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Attempter is an inversion-of-control version of [Do]. Instead of calling a
+// function and sleeping between attempts, the caller performs each attempt,
+// reports its result to Next, and is responsible for waiting before the next
+// attempt. This allows a worker to pick up other tasks while a task waits.
+//
+// An Attempter classifies errors, counts attempts, and unwraps errors exactly
+// like Do. It is not safe for concurrent use.
+type Attempter struct {
+	b           Backoff
+	cfg         *doConfig
+	maxAttempts uint64
+
+	attempt uint64
+	lastErr error
+	err     error
+	done    bool
+	reason  StopReason
+
+	// cachedErr is the most recent error classified by walking its chain,
+	// along with the result.
+	cachedErr  error
+	cachedRerr *retryableError
+	cachedOK   bool
+}
+
+// Begin starts a new sequence of attempts using the backoff b.
+func Begin(b Backoff) *Attempter {
+	return newAttempter(b, newDoConfig(nil), 0)
+}
+
+func newAttempter(b Backoff, cfg *doConfig, maxAttempts uint64) *Attempter {
+	return &Attempter{
+		b:           b,
+		cfg:         cfg,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Next records the error returned by the most recent attempt. If done is true,
+// no further attempts should be made and the final error is available from
+// [Attempter.Err]. Otherwise, the caller should wait for resumeAfter before
+// making the next attempt.
+//
+// A nil error marks the sequence as successful. An error that is not wrapped
+// with [RetryableError] is permanent and ends the sequence. Once done, all
+// subsequent calls return done.
+func (a *Attempter) Next(err error) (resumeAfter time.Duration, done bool) {
+	if a.done {
+		return 0, true
+	}
+	a.attempt++
+
+	if err == nil {
+		return a.finish(nil)
+	}
+
+	// Not retryable
+	rerr, ok := a.classify(err)
+	if !ok {
+		if a.cfg.retryIf == nil || !a.cfg.retryIf(err) {
+			return a.finish(err)
+		}
+		rerr = &retryableError{err: err}
+	}
+	a.lastErr = rerr.Unwrap()
+
+	if a.maxAttempts > 0 && a.attempt >= a.maxAttempts {
+		a.reason = ReasonMaxAttempts
+		return a.finish(rerr.Unwrap())
+	}
+	if n := a.cfg.maxIterations; n > 0 && a.attempt >= n {
+		a.reason = ReasonRunaway
+		return a.finish(fmt.Errorf("%w: %w", ErrRunaway, rerr.Unwrap()))
+	}
+
+	var next time.Duration
+	var stop bool
+	if eb, ok := a.b.(ErrorBackoff); ok {
+		next, stop = eb.NextError(rerr.Unwrap())
+	} else {
+		next, stop = a.b.Next()
+	}
+	if stop {
+		a.reason = stopReasonOf(a.b)
+		if sentinel := stopReasonError(a.reason); sentinel != nil {
+			return a.finish(fmt.Errorf("%w: %w", sentinel, rerr.Unwrap()))
+		}
+		return a.finish(rerr.Unwrap())
+	}
+	if rerr.hasDelay {
+		next = limitDelay(a.b, rerr.delay)
+	}
+	return next, false
+}
+
+// Err returns the final error once the sequence is done. It returns nil if the
+// sequence succeeded or is not yet done.
+func (a *Attempter) Err() error {
+	return a.err
+}
+
+// StopReason returns why the sequence stopped retrying a retryable error. It
+// returns [ReasonNone] if the sequence is not done, succeeded, or ended with a
+// permanent error.
+func (a *Attempter) StopReason() StopReason {
+	return a.reason
+}
+
+// Attempts returns the number of attempts recorded so far.
+func (a *Attempter) Attempts() uint64 {
+	return a.attempt
+}
+
+// abort ends the sequence early for the given reason, returning the unwrapped
+// error from the most recent attempt, wrapped with the reason's sentinel error,
+// if any.
+func (a *Attempter) abort(reason StopReason) {
+	if a.done {
+		return
+	}
+	a.reason = reason
+	if sentinel := stopReasonError(reason); sentinel != nil {
+		a.finish(fmt.Errorf("%w: %w", sentinel, a.lastErr))
+		return
+	}
+	a.finish(a.lastErr)
+}
+
+// closeGate ends the sequence because the global gate is closed. Unless a
+// retryable error was retried, nothing was exhausted, so the sequence ends
+// without a stop reason.
+func (a *Attempter) closeGate() {
+	if a.done {
+		return
+	}
+	if a.lastErr != nil {
+		a.reason = ReasonGateClosed
+	}
+	a.finish(gateClosedError(a.lastErr))
+}
+
+func (a *Attempter) finish(err error) (time.Duration, bool) {
+	if a.reason != ReasonNone && err != nil {
+		err = &exhaustedError{err: err}
+	}
+	a.err = err
+	a.done = true
+	releaseLocks(a.b)
+	if a.reason != ReasonNone {
+		if a.cfg.site != nil {
+			a.cfg.site.exhaustions.Add(1)
+		}
+		if x := a.cfg.exhaustion; x != nil {
+			x.end = a.cfg.clock.Now()
+			x.attempts = a.attempt
+			x.reason = a.reason
+		}
+		for _, fn := range a.cfg.onStop {
+			fn(a.reason, err)
+		}
+	}
+	return 0, true
+}
+
+// classify returns the first *retryableError in err's chain, like asRetryable.
+// The result for an error that is not returned directly from [RetryableError]
+// is cached, so an attempt that fails with the same error value as the previous
+// one does not walk its chain again.
+func (a *Attempter) classify(err error) (*retryableError, bool) {
+	if rerr, ok := err.(*retryableError); ok {
+		return rerr, true
+	}
+
+	// The cached error is a pointer, so the comparison cannot panic even if err
+	// has an incomparable type.
+	if a.cachedErr != nil && err == a.cachedErr {
+		return a.cachedRerr, a.cachedOK
+	}
+
+	rerr, ok := walkRetryable(err, a.cfg.walkDepth)
+	if reflect.TypeOf(err).Kind() == reflect.Pointer {
+		a.cachedErr, a.cachedRerr, a.cachedOK = err, rerr, ok
+	}
+	return rerr, ok
+}
+
+// defaultWalkDepth is the number of wrapped errors walked by asRetryable
+// before it falls back to errors.As.
+const defaultWalkDepth = 32
+
+// asRetryable returns the first *retryableError in err's chain. The common case
+// of an error returned directly from [RetryableError] is checked without
+// calling [errors.As], which would allocate.
+func asRetryable(err error) (*retryableError, bool) {
+	if rerr, ok := err.(*retryableError); ok {
+		return rerr, true
+	}
+	return walkRetryable(err, 0)
+}
+
+// walkRetryable finds the first *retryableError in err's chain by following
+// single wrapped errors with type assertions, which is much cheaper than
+// [errors.As] for deep chains. It falls back to errors.As once it reaches an
+// error with an As method or multiple wrapped errors, which errors.As handles,
+// or after depth errors. A depth less than or equal to zero means
+// defaultWalkDepth.
+func walkRetryable(err error, depth int) (*retryableError, bool) {
+	if depth <= 0 {
+		depth = defaultWalkDepth
+	}
+
+	for i := 0; err != nil && i < depth; i++ {
+		switch x := err.(type) {
+		case retryMarker:
+			// A marker that is not a *retryableError makes the error
+			// permanent, even if it wraps one.
+			rerr, ok := x.(*retryableError)
+			return rerr, ok
+		case interface{ As(any) bool }, interface{ Unwrap() []error }:
+			return asRetryableSlow(err)
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+	if err == nil {
+		return nil, false
+	}
+	return asRetryableSlow(err)
+}
+
+func asRetryableSlow(err error) (*retryableError, bool) {
+	var rerr *retryableError
+	ok := errors.As(err, &rerr)
+	return rerr, ok
+}
+
+// WithErrorWalkDepth sets how many wrapped errors [Do] and [DoValue] follow
+// with cheap type assertions to find an error marked with [RetryableError],
+// before falling back to [errors.As]. Errors with an As method or multiple
+// wrapped errors always fall back. A value less than or equal to zero means
+// the default of 32, which suits all but the deepest chains.
+//
+// When an attempt fails with the same error value, compared by pointer, as the
+// previous attempt, its classification is reused without walking the chain.
+func WithErrorWalkDepth(n int) DoOption {
+	return func(c *doConfig) {
+		c.walkDepth = n
+	}
+}
@@ -0,0 +1,170 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAttempter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		a := retry.Begin(retry.WithMaxRetries(3, retry.NewConstant(1*time.Second)))
+
+		resumeAfter, done := a.Next(retry.RetryableError(fmt.Errorf("oops")))
+		if done {
+			t.Fatal("should not be done")
+		}
+		if got, want := resumeAfter, 1*time.Second; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		if _, done := a.Next(nil); !done {
+			t.Fatal("should be done")
+		}
+		if err := a.Err(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if got, want := a.Attempts(), uint64(2); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		a := retry.Begin(retry.WithMaxRetries(3, retry.NewConstant(1*time.Second)))
+
+		var attempts int
+		for {
+			attempts++
+			if _, done := a.Next(retry.RetryableError(io.EOF)); done {
+				break
+			}
+		}
+
+		if got, want := attempts, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := a.Err(), io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		t.Parallel()
+
+		a := retry.Begin(retry.WithMaxRetries(3, retry.NewConstant(1*time.Second)))
+
+		if _, done := a.Next(io.EOF); !done {
+			t.Fatal("should be done")
+		}
+		if got, want := a.Err(), io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("sticky_done", func(t *testing.T) {
+		t.Parallel()
+
+		a := retry.Begin(retry.NewConstant(1 * time.Second))
+
+		if _, done := a.Next(io.EOF); !done {
+			t.Fatal("should be done")
+		}
+		resumeAfter, done := a.Next(retry.RetryableError(io.ErrUnexpectedEOF))
+		if !done {
+			t.Error("should still be done")
+		}
+		if resumeAfter != 0 {
+			t.Errorf("expected %v to be %v", resumeAfter, 0)
+		}
+		if got, want := a.Err(), io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("matches_do", func(t *testing.T) {
+		t.Parallel()
+
+		errPermanent := errors.New("permanent")
+
+		for name, results := range map[string][]error{
+			"success":   {retry.RetryableError(io.EOF), nil},
+			"exhausted": {retry.RetryableError(io.EOF), retry.RetryableError(io.EOF), retry.RetryableError(io.EOF)},
+			"permanent": {retry.RetryableError(io.EOF), errPermanent},
+		} {
+			newBackoff := func() retry.Backoff {
+				return retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+			}
+
+			var doCalls int
+			doErr := retry.Do(context.Background(), newBackoff(), func(_ context.Context) error {
+				err := results[doCalls]
+				doCalls++
+				return err
+			})
+
+			a := retry.Begin(newBackoff())
+			var calls int
+			for {
+				err := results[calls]
+				calls++
+				if _, done := a.Next(err); done {
+					break
+				}
+			}
+
+			if got, want := calls, doCalls; got != want {
+				t.Errorf("%s: expected %v to be %v", name, got, want)
+			}
+			if got, want := fmt.Sprint(a.Err()), fmt.Sprint(doErr); got != want {
+				t.Errorf("%s: expected %v to be %v", name, got, want)
+			}
+			if got, want := errors.Is(a.Err(), retry.ErrExhausted), errors.Is(doErr, retry.ErrExhausted); got != want {
+				t.Errorf("%s: expected %v to be %v", name, got, want)
+			}
+		}
+	})
+}
+
+func ExampleBegin() {
+	b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+	a := retry.Begin(b)
+
+	i := 0
+	for {
+		// The caller performs the attempt...
+		var err error
+		if i < 2 {
+			err = retry.RetryableError(fmt.Errorf("oops"))
+		}
+		fmt.Printf("attempt %d\n", i)
+		i++
+
+		// ...and reports the result. A worker pool could schedule the task to
+		// resume after the delay instead of sleeping.
+		resumeAfter, done := a.Next(err)
+		if done {
+			break
+		}
+		time.Sleep(resumeAfter)
+	}
+
+	if err := a.Err(); err != nil {
+		// handle error
+	}
+
+	// Output:
+	// attempt 0
+	// attempt 1
+	// attempt 2
+}
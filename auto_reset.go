@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// WithAutoReset resets next before a call to Next made more than idle after the
+// previous call, so the delays start over from the base once retrying has
+// paused for a while. This suits a backoff shared by a long-lived loop, such as
+// one that reconnects whenever a connection drops: after the connection has
+// been healthy for idle, the next failure is retried after the base delay
+// instead of continuing the previous streak.
+//
+// A reset also clears a stop, so unlike other backoffs, the returned backoff
+// resumes after it has stopped once it has been idle for longer than idle.
+//
+// Every backoff in the chain of next, as visited by [Walk], must have a Reset
+// method, since a middleware only resets the backoff it wraps if that backoff
+// has one. The built-in backoffs and the stateless middlewares, such as
+// [WithJitter] and [WithCappedDuration], do. The returned backoff has a Reset
+// method, which resets next. Use [WithNowFunc] to control the time in tests.
+//
+// It panics if idle is less than or equal to zero, or next is nil or has a
+// backoff without a Reset method in its chain. It is safe for concurrent use
+// if next is safe for concurrent use.
+func WithAutoReset(idle time.Duration, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithAutoResetE(idle, next, opts...))
+}
+
+// WithAutoResetE is like [WithAutoReset], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithAutoResetE(idle time.Duration, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validatePositive("idle", idle); err != nil {
+		return nil, err
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+	if !resettable(next) {
+		return nil, &ValidationError{Field: "next", Reason: "must have a Reset method on every backoff in its chain"}
+	}
+
+	return &autoResetBackoff{
+		idle:  idle,
+		next:  next,
+		reset: next.(interface{ Reset() }).Reset,
+		now:   newBackoffConfig(opts).now,
+	}, nil
+}
+
+// resettable reports whether every backoff in the chain b has a Reset method, so
+// resetting b resets the whole chain.
+func resettable(b Backoff) bool {
+	ok := true
+	Walk(b, func(node Backoff) bool {
+		_, ok = node.(interface{ Reset() })
+		return ok
+	})
+	return ok
+}
+
+type autoResetBackoff struct {
+	idle  time.Duration
+	next  Backoff
+	reset func()
+	now   func() time.Time
+
+	// lock is held while calling next, so a reset is never interleaved with
+	// another call to Next.
+	lock sync.Mutex
+	last time.Time
+}
+
+// Next implements Backoff.
+func (b *autoResetBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() && now.Sub(b.last) > b.idle {
+		b.reset()
+	}
+	b.last = now
+	return b.next.Next()
+}
+
+// Reset resets next.
+func (b *autoResetBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.reset()
+}
+
+// Idle returns the configured idle period.
+func (b *autoResetBackoff) Idle() time.Duration {
+	return b.idle
+}
+
+// Unwrap implements Wrapper.
+func (b *autoResetBackoff) Unwrap() Backoff {
+	return b.next
+}
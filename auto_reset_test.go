@@ -0,0 +1,127 @@
+package retry_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithAutoReset(t *testing.T) {
+	t.Parallel()
+
+	next := func(tb testing.TB, b retry.Backoff, want time.Duration) {
+		tb.Helper()
+
+		val, stop := b.Next()
+		if stop {
+			tb.Fatal("should not stop")
+		}
+		if got := val; got != want {
+			tb.Errorf("expected %v to be %v", got, want)
+		}
+	}
+
+	t.Run("idle", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithAutoReset(1*time.Minute,
+			retry.NewScheduleRepeatLast(1*time.Second, 2*time.Second, 3*time.Second),
+			retry.WithNowFunc(clock.Now))
+
+		next(t, b, 1*time.Second)
+		next(t, b, 2*time.Second)
+		next(t, b, 3*time.Second)
+
+		clock.Advance(1*time.Minute + 1*time.Nanosecond)
+		next(t, b, 1*time.Second)
+		next(t, b, 2*time.Second)
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithAutoReset(1*time.Minute,
+			retry.NewScheduleRepeatLast(1*time.Second, 2*time.Second, 3*time.Second),
+			retry.WithNowFunc(clock.Now))
+
+		// The idle period is measured from the previous call, not the first.
+		for _, want := range []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+			next(t, b, want)
+			clock.Advance(1 * time.Minute)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithAutoReset(1*time.Minute,
+			retry.NewScheduleRepeatLast(1*time.Second, 2*time.Second),
+			retry.WithNowFunc(newFakeClock().Now))
+
+		next(t, b, 1*time.Second)
+		next(t, b, 2*time.Second)
+
+		b.(interface{ Reset() }).Reset()
+		next(t, b, 1*time.Second)
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithAutoReset(1*time.Minute,
+			retry.WithMaxDuration(1*time.Hour, retry.NewExponential(1*time.Second),
+				retry.WithNowFunc(clock.Now)),
+			retry.WithNowFunc(clock.Now))
+
+		next(t, b, 1*time.Second)
+		next(t, b, 2*time.Second)
+		next(t, b, 4*time.Second)
+
+		clock.Advance(2 * time.Minute)
+		next(t, b, 1*time.Second)
+		next(t, b, 2*time.Second)
+	})
+
+	t.Run("through_middleware", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithAutoReset(1*time.Minute,
+			retry.WithMaxSleep(3*time.Second, retry.NewConstant(1*time.Second)),
+			retry.WithNowFunc(clock.Now))
+
+		for i := 0; i < 3; i++ {
+			next(t, b, 1*time.Second)
+		}
+		if _, stop := b.Next(); !stop {
+			t.Fatal("expected the budget to stop the backoff")
+		}
+
+		// The reset restores the budget of WithMaxSleep.
+		clock.Advance(2 * time.Minute)
+		next(t, b, 1*time.Second)
+	})
+}
+
+func ExampleWithAutoReset() {
+	// Delays grow while the connection keeps failing, and start over once it
+	// has been healthy for 5 minutes.
+	b := retry.WithAutoReset(5*time.Minute,
+		retry.NewScheduleRepeatLast(1*time.Second, 5*time.Second, 30*time.Second))
+
+	for i := 0; i < 4; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+
+	// Output:
+	// 1s
+	// 5s
+	// 30s
+	// 30s
+}
@@ -1,19 +1,125 @@
 package retry
 
 import (
+	"math"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Backoff is an interface that backs off.
+//
+// Stopping is sticky: once Next returns stop, every later call must also
+// return stop, until the backoff is reset. [Do] treats the first stop as final,
+// but other callers, such as an [Attempter] or a scheduler, may call Next
+// again. Every backoff in this package is sticky, provided the backoff it
+// wraps is, except that one from [WithAutoReset] resets itself, and so
+// resumes, once it has been idle long enough.
 type Backoff interface {
 	// Next returns the time duration to wait and whether to stop.
 	Next() (next time.Duration, stop bool)
 }
 
+// ErrorBackoff is a Backoff that also considers the error returned by the most
+// recent attempt. When the backoff given to [Do] implements ErrorBackoff,
+// NextError is called with the unwrapped retryable error instead of Next.
+//
+// Wrapping an ErrorBackoff with a middleware that only implements Backoff hides
+// the error-aware behavior, so error-aware wrappers should be applied last.
+type ErrorBackoff interface {
+	Backoff
+
+	// NextError returns the time duration to wait and whether to stop, given the
+	// error from the most recent attempt.
+	NextError(err error) (next time.Duration, stop bool)
+}
+
+// ObserverBackoff is a Backoff that is also told the outcome of every attempt,
+// including successful ones, which never reach Next. When the backoff given to
+// [Do], or any backoff it wraps according to [Wrapper], implements
+// ObserverBackoff, Observe is called after every attempt, after the observers
+// registered with [WithOutcomeObserver].
+type ObserverBackoff interface {
+	Backoff
+
+	// Observe records the outcome of an attempt.
+	Observe(o Outcome)
+}
+
 var _ Backoff = (BackoffFunc)(nil)
 
-// BackoffFunc is a backoff expressed as a function.
+// BackoffOption is an option that configures the construction of a backoff
+// wrapper. Options that do not apply to a given wrapper are ignored.
+type BackoffOption func(c *backoffConfig)
+
+type backoffConfig struct {
+	now     func() time.Time
+	seed    int64
+	src     rand.Source
+	resplay bool
+	loc     *time.Location
+
+	truncate  bool
+	lazyStart bool
+}
+
+func newBackoffConfig(opts []BackoffOption) *backoffConfig {
+	c := &backoffConfig{
+		now:  time.Now,
+		seed: time.Now().UnixNano(),
+		loc:  time.Local,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithNowFunc sets the function used by time-dependent wrappers to read the
+// current time. It defaults to [time.Now] and is primarily useful for driving
+// time in tests.
+func WithNowFunc(now func() time.Time) BackoffOption {
+	return func(c *backoffConfig) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// WithRandSeed sets the seed of the random source used by randomized wrappers,
+// such as [WithJitter] and [WithStartupSplay]. It defaults to the current time
+// and is primarily useful for deterministic tests.
+func WithRandSeed(seed int64) BackoffOption {
+	return func(c *backoffConfig) {
+		c.seed = seed
+	}
+}
+
+// WithRandSource sets the random source used by randomized wrappers, such as
+// [WithJitter] and [WithStartupSplay], instead of a source seeded with
+// [WithRandSeed]. It is useful for sources backed by crypto/rand, or for
+// deterministic tests of code that composes wrappers. The wrapper guards its
+// use of src with a mutex, so src must only be shared between wrappers if it is
+// safe for concurrent use. A nil src is ignored.
+func WithRandSource(src rand.Source) BackoffOption {
+	return func(c *backoffConfig) {
+		if src != nil {
+			c.src = src
+		}
+	}
+}
+
+// random returns the random source for a randomized wrapper.
+func (c *backoffConfig) random() *lockedSource {
+	if c.src != nil {
+		return newLockedSource(c.src)
+	}
+	return newLockedRandom(c.seed)
+}
+
+// BackoffFunc is a backoff expressed as a function. The function must keep
+// returning stop once it has returned stop.
 type BackoffFunc func() (time.Duration, bool)
 
 // Next implements Backoff.
@@ -23,112 +129,861 @@ func (b BackoffFunc) Next() (time.Duration, bool) {
 
 // WithJitter wraps a backoff function and adds the specified jitter. j can be
 // interpreted as "+/- j". For example, if j were 5 seconds and the backoff
-// returned 20s, the value could be between 15 and 25 seconds. The value can
-// never be less than 0.
-func WithJitter(j time.Duration, next Backoff) Backoff {
-	r := newLockedRandom(time.Now().UnixNano())
+// returned 20s, the value could be between 15 and 25 seconds.
+//
+// When the backoff returns a delay shorter than j, the jitter is reduced to
+// "+/- delay", so a delay of 100ms with a jitter of 500ms is between 0 and
+// 200ms. The jittered delays then remain centered on the delay, rather than
+// many of them being clamped to 0, which would cause bursts of immediate
+// retries. A delay of 0 is not jittered.
+//
+// A jitter of 0 leaves the delays unchanged, which suits a jitter read from
+// configuration that may disable it.
+//
+// It panics if j is negative, greater than half the maximum duration, or next
+// is nil. It is safe for concurrent use if next is safe for
+// concurrent use.
+func WithJitter(j time.Duration, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithJitterE(j, next, opts...))
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		val, stop := next.Next()
-		if stop {
-			return 0, true
-		}
+// WithJitterE is like [WithJitter], but returns an error instead of panicking
+// if the arguments are invalid.
+func WithJitterE(j time.Duration, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validateJitter("j", j); err != nil {
+		return nil, err
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
 
-		diff := time.Duration(r.Int63n(int64(j)*2) - int64(j))
-		val = val + diff
-		if val < 0 {
-			val = 0
-		}
-		return val, false
-	})
+	return &jitterBackoff{
+		j:    j,
+		next: next,
+		r:    newBackoffConfig(opts).random(),
+	}, nil
+}
+
+type jitterBackoff struct {
+	j    time.Duration
+	next Backoff
+	r    *lockedSource
+}
+
+// Next implements Backoff.
+func (b *jitterBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+
+	// Narrow the jitter for delays shorter than it, so the distribution stays
+	// symmetric around the delay instead of piling up at 0.
+	j := min(b.j, val)
+	if j <= 0 {
+		return max(val, 0), false
+	}
+	return val + time.Duration(b.r.Int63n(int64(j)*2)-int64(j)), false
+}
+
+// Jitter returns the configured jitter.
+func (b *jitterBackoff) Jitter() time.Duration {
+	return b.j
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *jitterBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *jitterBackoff) Unwrap() Backoff {
+	return b.next
 }
 
 // WithJitterPercent wraps a backoff function and adds the specified jitter
 // percentage. j can be interpreted as "+/- j%". For example, if j were 5 and
 // the backoff returned 20s, the value could be between 19 and 21 seconds. The
-// value can never be less than 0 or greater than 100.
-func WithJitterPercent(j uint64, next Backoff) Backoff {
-	r := newLockedRandom(time.Now().UnixNano())
+// value can never be less than 0 or greater than 100. A value of 0 leaves the
+// delays unchanged.
+//
+// It panics if j is greater than 100, or next is nil. It is safe for
+// concurrent use if next is safe for concurrent use.
+func WithJitterPercent(j uint64, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithJitterPercentE(j, next, opts...))
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		val, stop := next.Next()
-		if stop {
-			return 0, true
-		}
+// WithJitterPercentE is like [WithJitterPercent], but returns an error instead
+// of panicking if the arguments are invalid.
+func WithJitterPercentE(j uint64, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if j > 100 {
+		return nil, &ValidationError{Field: "j", Reason: "must not be greater than 100"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
 
-		// Get a value between -j and j, the convert to a percentage
-		top := r.Int63n(int64(j)*2) - int64(j)
-		pct := 1 - float64(top)/100.0
+	return &jitterPercentBackoff{
+		j:    j,
+		next: next,
+		r:    newBackoffConfig(opts).random(),
+	}, nil
+}
 
-		val = time.Duration(float64(val) * pct)
-		if val < 0 {
-			val = 0
-		}
+type jitterPercentBackoff struct {
+	j    uint64
+	next Backoff
+	r    *lockedSource
+}
+
+// Next implements Backoff.
+func (b *jitterPercentBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+	if b.j == 0 {
 		return val, false
-	})
+	}
+
+	// Get a value between -j and j, the convert to a percentage
+	top := b.r.Int63n(int64(b.j)*2) - int64(b.j)
+	pct := 1 - float64(top)/100.0
+
+	val = time.Duration(float64(val) * pct)
+	if val < 0 {
+		val = 0
+	}
+	return val, false
+}
+
+// JitterPercent returns the configured jitter percentage.
+func (b *jitterPercentBackoff) JitterPercent() uint64 {
+	return b.j
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *jitterPercentBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *jitterPercentBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+// WithFullJitter wraps a backoff function and replaces each delay d with a
+// random delay between 0 and d, the "full jitter" strategy from the AWS
+// Architecture Blog post "Exponential Backoff and Jitter". It spreads out
+// competing clients better than [WithJitter], whose jitter has a fixed
+// magnitude, at the cost of sometimes retrying almost immediately.
+//
+// It panics if next is nil. It is safe for concurrent use if next is safe for
+// concurrent use.
+func WithFullJitter(next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithFullJitterE(next, opts...))
+}
+
+// WithFullJitterE is like [WithFullJitter], but returns an error instead of
+// panicking if next is nil.
+func WithFullJitterE(next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &fullJitterBackoff{
+		next: next,
+		r:    newBackoffConfig(opts).random(),
+	}, nil
+}
+
+// WithEqualJitter wraps a backoff function and replaces each delay d with a
+// random delay between d/2 and d, the "equal jitter" strategy from the same
+// post as [WithFullJitter]. It always waits at least half of each delay.
+//
+// It panics if next is nil. It is safe for concurrent use if next is safe for
+// concurrent use.
+func WithEqualJitter(next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithEqualJitterE(next, opts...))
+}
+
+// WithEqualJitterE is like [WithEqualJitter], but returns an error instead of
+// panicking if next is nil.
+func WithEqualJitterE(next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &fullJitterBackoff{
+		next:  next,
+		r:     newBackoffConfig(opts).random(),
+		equal: true,
+	}, nil
+}
+
+type fullJitterBackoff struct {
+	next  Backoff
+	r     *lockedSource
+	equal bool
+}
+
+// Next implements Backoff.
+func (b *fullJitterBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+	if val <= 0 {
+		return 0, false
+	}
+
+	if b.equal {
+		half := val / 2
+		return val - half + b.upTo(half), false
+	}
+	return b.upTo(val), false
+}
+
+// upTo returns a random duration between 0 and d, inclusive.
+func (b *fullJitterBackoff) upTo(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	if d == math.MaxInt64 {
+		return time.Duration(b.r.Int63())
+	}
+	return time.Duration(b.r.Int63n(int64(d) + 1))
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *fullJitterBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *fullJitterBackoff) Unwrap() Backoff {
+	return b.next
 }
 
 // WithMaxRetries executes the backoff function up until the maximum attempts.
+// The returned backoff has a Reset method, which restores the full number of
+// retries and resets next, if it has a Reset method.
+//
+// It panics if next is nil. It is safe for concurrent use if next is safe for
+// concurrent use.
 func WithMaxRetries(max uint64, next Backoff) Backoff {
-	var l sync.Mutex
-	var attempt uint64
+	return must(WithMaxRetriesE(max, next))
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		l.Lock()
-		defer l.Unlock()
+// WithMaxRetriesE is like [WithMaxRetries], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithMaxRetriesE(max uint64, next Backoff) (Backoff, error) {
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
 
-		if attempt >= max {
-			return 0, true
-		}
-		attempt++
+	return &maxRetriesBackoff{
+		max:  max,
+		next: next,
+	}, nil
+}
 
-		val, stop := next.Next()
-		if stop {
-			return 0, true
-		}
+type maxRetriesBackoff struct {
+	max  uint64
+	next Backoff
 
-		return val, false
-	})
+	lock    sync.Mutex
+	attempt uint64
+}
+
+// Next implements Backoff.
+func (b *maxRetriesBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.attempt >= b.max {
+		return 0, true
+	}
+	b.attempt++
+
+	val, stop := b.next.Next()
+	if stop {
+		// Keep stopping even if next does not.
+		b.attempt = b.max
+		return 0, true
+	}
+
+	return val, false
+}
+
+// MaxRetries returns the configured maximum number of retries.
+func (b *maxRetriesBackoff) MaxRetries() uint64 {
+	return b.max
+}
+
+// Reset restores the full number of retries, clears any stop, and resets next,
+// if it has a Reset method.
+func (b *maxRetriesBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.attempt = 0
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *maxRetriesBackoff) Unwrap() Backoff {
+	return b.next
 }
 
 // WithCappedDuration sets a maximum on the duration returned from the next
 // backoff. This is NOT a total backoff time, but rather a cap on the maximum
 // value a backoff can return. Without another middleware, the backoff will
 // continue infinitely.
+//
+// It panics if next is nil. It is safe for concurrent use if next is safe for
+// concurrent use.
 func WithCappedDuration(cap time.Duration, next Backoff) Backoff {
-	return BackoffFunc(func() (time.Duration, bool) {
-		val, stop := next.Next()
-		if stop {
-			return 0, true
-		}
+	return must(WithCappedDurationE(cap, next))
+}
 
-		if val <= 0 || val > cap {
-			val = cap
-		}
-		return val, false
-	})
+// WithCappedDurationE is like [WithCappedDuration], but returns an error
+// instead of panicking if the arguments are invalid.
+func WithCappedDurationE(cap time.Duration, next Backoff) (Backoff, error) {
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &cappedDurationBackoff{
+		cap:  cap,
+		next: next,
+	}, nil
+}
+
+type cappedDurationBackoff struct {
+	cap  time.Duration
+	next Backoff
+}
+
+// Next implements Backoff.
+func (b *cappedDurationBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+
+	if val <= 0 || val > b.cap {
+		val = b.cap
+	}
+	return val, false
+}
+
+// Cap returns the configured maximum delay.
+func (b *cappedDurationBackoff) Cap() time.Duration {
+	return b.cap
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *cappedDurationBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *cappedDurationBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+// WithMinDuration sets a minimum on the duration returned from the next
+// backoff, raising any smaller delay to min. It is the counterpart of
+// [WithCappedDuration], and keeps subtractive jitter from collapsing delays to
+// zero.
+//
+// If next has a Reset method, so does the returned backoff, and it resets next.
+//
+// It panics if min is negative or next is nil. It is safe for concurrent use if
+// next is safe for concurrent use.
+func WithMinDuration(min time.Duration, next Backoff) Backoff {
+	return must(WithMinDurationE(min, next))
+}
+
+// WithMinDurationE is like [WithMinDuration], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithMinDurationE(min time.Duration, next Backoff) (Backoff, error) {
+	if min < 0 {
+		return nil, &ValidationError{Field: "min", Reason: "must not be negative"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &minDurationBackoff{
+		min:  min,
+		next: next,
+	}, nil
+}
+
+type minDurationBackoff struct {
+	min  time.Duration
+	next Backoff
+}
+
+// Next implements Backoff.
+func (b *minDurationBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+	return max(val, b.min), false
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *minDurationBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// MinDuration returns the configured minimum delay.
+func (b *minDurationBackoff) MinDuration() time.Duration {
+	return b.min
+}
+
+// Unwrap implements Wrapper.
+func (b *minDurationBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+var (
+	_ Backoff      = (*maxDurationBackoff)(nil)
+	_ StopReasoner = (*maxDurationBackoff)(nil)
+)
+
+// UnboundedDuration is the smallest timeout that [WithMaxDuration] treats as
+// unbounded, about 146 years. Callers often pass math.MaxInt64 to mean "no
+// limit", and timeouts this large never run out.
+const UnboundedDuration time.Duration = 1 << 62
+
+type maxDurationBackoff struct {
+	timeout time.Duration
+	next    Backoff
+	now     func() time.Time
+	lazy    bool
+
+	// start is nil until the first call to Next if lazy.
+	start     atomic.Pointer[time.Time]
+	truncated atomic.Bool
+	reason    atomic.Int32
+}
+
+// WithLazyStart causes a backoff from [WithMaxDuration] to start its clock on
+// the first call to Next, rather than when it is constructed. This suits
+// backoffs that are built once and used later, such as by a worker pool.
+func WithLazyStart() BackoffOption {
+	return func(c *backoffConfig) {
+		c.lazyStart = true
+	}
 }
 
 // WithMaxDuration sets a maximum on the total amount of time a backoff should
 // execute. It's best-effort, and should not be used to guarantee an exact
 // amount of time.
-func WithMaxDuration(timeout time.Duration, next Backoff) Backoff {
-	start := time.Now()
+//
+// The returned backoff implements [StopReasoner]. When the final delay was
+// truncated to fit the remaining time, the following stop is reported as
+// [ReasonBudgetTruncatedFinalSleep] rather than [ReasonMaxDuration].
+//
+// A timeout of [UnboundedDuration] or more never runs out, and a clock that
+// moves backward counts as no time elapsed.
+//
+// If [Do] wakes up from a sleep late enough that the timeout elapsed, such as
+// under severe CPU starvation, it stops without making another attempt; see
+// [WithoutBudgetRecheck].
+//
+// The clock starts when the backoff is constructed, or on the first call to
+// Next if [WithLazyStart] is given. Elapsed time is read from the now function
+// set with [WithNowFunc], independently of the attempt durations measured by
+// [Do]; when [WithClock] is given, pass the clock's Now method to keep the two
+// consistent. The returned backoff has a Reset method, which restores the full
+// budget, clears any stop, and resets next if it has a Reset method.
+//
+// It panics if next is nil. It is safe for concurrent use if next and the
+// configured now function are safe for concurrent use.
+func WithMaxDuration(timeout time.Duration, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithMaxDurationE(timeout, next, opts...))
+}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		diff := timeout - time.Since(start)
-		if diff <= 0 {
-			return 0, true
-		}
+// WithMaxDurationE is like [WithMaxDuration], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithMaxDurationE(timeout time.Duration, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	cfg := newBackoffConfig(opts)
+
+	b := &maxDurationBackoff{
+		timeout: timeout,
+		next:    next,
+		now:     cfg.now,
+		lazy:    cfg.lazyStart,
+	}
+	if !b.lazy {
+		start := cfg.now()
+		b.start.Store(&start)
+	}
+	return b, nil
+}
+
+// Next implements Backoff.
+func (b *maxDurationBackoff) Next() (time.Duration, bool) {
+	// Keep stopping even if the clock moves backward or next resumes.
+	if reason := StopReason(b.reason.Load()); reason != ReasonNone {
+		return 0, true
+	}
 
-		val, stop := next.Next()
+	if b.timeout >= UnboundedDuration {
+		val, stop := b.next.Next()
 		if stop {
-			return 0, true
+			return b.stop(stopReasonOf(b.next))
 		}
+		return max(val, 0), false
+	}
 
-		if val <= 0 || val > diff {
-			val = diff
+	diff := b.remaining()
+	if diff <= 0 {
+		if b.truncated.Load() {
+			return b.stop(ReasonBudgetTruncatedFinalSleep)
 		}
+		return b.stop(ReasonMaxDuration)
+	}
+
+	val, stop := b.next.Next()
+	if stop {
+		return b.stop(stopReasonOf(b.next))
+	}
+
+	// Remember whether this delay was cut short, since the next call will stop
+	// for lack of budget.
+	b.truncated.Store(val > diff)
+	if val <= 0 || val > diff {
+		val = diff
+	}
+	return val, false
+}
+
+// remaining returns the time left before the timeout, which is never negative.
+func (b *maxDurationBackoff) remaining() time.Duration {
+	// A clock that moved backward counts as no time elapsed, which also keeps
+	// the subtraction from overflowing.
+	elapsed := max(b.now().Sub(b.started()), 0)
+	if elapsed >= b.timeout {
+		return 0
+	}
+	return b.timeout - elapsed
+}
+
+// Exceeded reports whether the timeout elapsed before now. It returns false if
+// the clock has not started. [Do] calls it after waking up late, so that no
+// attempt is made once the oversleep used up the budget.
+func (b *maxDurationBackoff) Exceeded(now time.Time) bool {
+	start := b.start.Load()
+	if start == nil || b.timeout >= UnboundedDuration {
+		return false
+	}
+	return now.Sub(*start) > b.timeout
+}
+
+// started returns the start time of the clock, starting it if it has not
+// started yet. Concurrent first calls agree on a single start time.
+func (b *maxDurationBackoff) started() time.Time {
+	if start := b.start.Load(); start != nil {
+		return *start
+	}
+
+	now := b.now()
+	if b.start.CompareAndSwap(nil, &now) {
+		return now
+	}
+	return *b.start.Load()
+}
+
+// Reset restarts the clock, immediately or on the next call to Next if
+// [WithLazyStart] was given, clears any stop, and resets next, if it has a
+// Reset method.
+func (b *maxDurationBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+
+	if b.lazy {
+		b.start.Store(nil)
+	} else {
+		now := b.now()
+		b.start.Store(&now)
+	}
+	b.truncated.Store(false)
+	b.reason.Store(int32(ReasonNone))
+}
+
+// StopReason implements StopReasoner.
+func (b *maxDurationBackoff) StopReason() StopReason {
+	return StopReason(b.reason.Load())
+}
+
+func (b *maxDurationBackoff) stop(reason StopReason) (time.Duration, bool) {
+	b.reason.CompareAndSwap(int32(ReasonNone), int32(reason))
+	return 0, true
+}
+
+// MaxDuration returns the configured maximum total duration.
+func (b *maxDurationBackoff) MaxDuration() time.Duration {
+	return b.timeout
+}
+
+// Unwrap implements Wrapper.
+func (b *maxDurationBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+// RoundMode is the rounding mode used by [WithQuantizedDelay].
+type RoundMode int
+
+const (
+	// RoundUp rounds delays up to the next multiple of the quantum.
+	RoundUp RoundMode = iota
+
+	// RoundDown rounds delays down to the previous multiple of the quantum, but
+	// never rounds a positive delay down to 0.
+	RoundDown
+
+	// RoundNearest rounds delays to the nearest multiple of the quantum, rounding
+	// halfway values up.
+	RoundNearest
+)
+
+// WithQuantizedDelay rounds the duration returned from the next backoff to a
+// multiple of quantum using the given rounding mode. This aligns wake-ups to
+// coarse boundaries so the operating system can batch timers.
+//
+// Quantization should be the outermost wrapper; applying jitter or caps after
+// quantization will move delays off of the quantum boundaries.
+//
+// It panics if quantum is less than or equal to zero or next is nil. It is safe
+// for concurrent use if next is safe for concurrent use.
+func WithQuantizedDelay(quantum time.Duration, mode RoundMode, next Backoff) Backoff {
+	return must(WithQuantizedDelayE(quantum, mode, next))
+}
+
+// WithQuantizedDelayE is like [WithQuantizedDelay], but returns an error
+// instead of panicking if the arguments are invalid.
+func WithQuantizedDelayE(quantum time.Duration, mode RoundMode, next Backoff) (Backoff, error) {
+	if err := validatePositive("quantum", quantum); err != nil {
+		return nil, err
+	}
+	if mode < RoundUp || mode > RoundNearest {
+		return nil, &ValidationError{Field: "mode", Reason: "is not a known rounding mode"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &quantizedDelayBackoff{
+		quantum: quantum,
+		mode:    mode,
+		next:    next,
+	}, nil
+}
+
+type quantizedDelayBackoff struct {
+	quantum time.Duration
+	mode    RoundMode
+	next    Backoff
+}
+
+// Next implements Backoff.
+func (b *quantizedDelayBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+
+	if val <= 0 {
+		return 0, false
+	}
+
+	rem := val % b.quantum
+	if rem == 0 {
+		return val, false
+	}
+	down := val - rem
+
+	var up bool
+	switch b.mode {
+	case RoundDown:
+		up = false
+	case RoundNearest:
+		up = rem >= b.quantum-rem
+	default:
+		up = true
+	}
+
+	// Rounding up must not overflow; fall back to rounding down.
+	if up && down <= math.MaxInt64-b.quantum {
+		return down + b.quantum, false
+	}
+	if down == 0 {
+		return b.quantum, false
+	}
+	return down, false
+}
+
+// Quantum returns the configured quantum.
+func (b *quantizedDelayBackoff) Quantum() time.Duration {
+	return b.quantum
+}
+
+// RoundMode returns the configured rounding mode.
+func (b *quantizedDelayBackoff) RoundMode() RoundMode {
+	return b.mode
+}
+
+// Reset resets next, if it has a Reset method.
+func (b *quantizedDelayBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *quantizedDelayBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+var _ ErrorBackoff = (*errorBudgetsBackoff)(nil)
+
+type errorBudgetsBackoff struct {
+	budgets  map[string]uint64
+	classify func(error) string
+	next     Backoff
+
+	lock    sync.Mutex
+	counts  map[string]uint64
+	stopped bool
+}
+
+// WithErrorBudgets limits the number of retries separately for each class of
+// error. The classify function maps an error to a class name, and budgets
+// contains the maximum number of retries for each class. Errors whose class is
+// not present in budgets use the budget for the empty class "", if any;
+// otherwise they are limited only by next. Once any class exhausts its budget,
+// the backoff stops for every class.
+//
+// Counters are tracked for the lifetime of the returned backoff, which is
+// typically a single call to [Do]. The returned backoff implements
+// [ErrorBackoff] and should be the outermost wrapper. When called through Next
+// without an error, it defers to next without consuming any budget.
+//
+// It panics if classify or next is nil. It is safe for concurrent use if next
+// is safe for concurrent use.
+func WithErrorBudgets(budgets map[string]uint64, classify func(error) string, next Backoff) Backoff {
+	return must(WithErrorBudgetsE(budgets, classify, next))
+}
+
+// WithErrorBudgetsE is like [WithErrorBudgets], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithErrorBudgetsE(budgets map[string]uint64, classify func(error) string, next Backoff) (Backoff, error) {
+	if classify == nil {
+		return nil, &ValidationError{Field: "classify", Reason: "must not be nil"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	copied := make(map[string]uint64, len(budgets))
+	for k, v := range budgets {
+		copied[k] = v
+	}
+
+	return &errorBudgetsBackoff{
+		budgets:  copied,
+		classify: classify,
+		next:     next,
+		counts:   make(map[string]uint64, len(copied)),
+	}, nil
+}
+
+// Next implements Backoff.
+func (b *errorBudgetsBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	stopped := b.stopped
+	b.lock.Unlock()
+	if stopped {
+		return 0, true
+	}
+
+	return b.checkStop(b.next.Next())
+}
+
+// NextError implements ErrorBackoff. Once any class exhausts its budget, it
+// keeps stopping for every class.
+func (b *errorBudgetsBackoff) NextError(err error) (time.Duration, bool) {
+	class := b.classify(err)
+
+	b.lock.Lock()
+	if b.stopped {
+		b.lock.Unlock()
+		return 0, true
+	}
+	budget, ok := b.budgets[class]
+	if !ok {
+		class = ""
+		budget, ok = b.budgets[class]
+	}
+	if ok {
+		if b.counts[class] >= budget {
+			b.stopped = true
+			b.lock.Unlock()
+			return 0, true
+		}
+		b.counts[class]++
+	}
+	b.lock.Unlock()
+
+	return b.checkStop(b.next.Next())
+}
+
+// checkStop records a stop from next.
+func (b *errorBudgetsBackoff) checkStop(val time.Duration, stop bool) (time.Duration, bool) {
+	if !stop {
 		return val, false
-	})
+	}
+
+	b.lock.Lock()
+	b.stopped = true
+	b.lock.Unlock()
+	return 0, true
+}
+
+// Budgets returns a copy of the configured budgets.
+func (b *errorBudgetsBackoff) Budgets() map[string]uint64 {
+	copied := make(map[string]uint64, len(b.budgets))
+	for k, v := range b.budgets {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Unwrap implements Wrapper.
+func (b *errorBudgetsBackoff) Unwrap() Backoff {
+	return b.next
 }
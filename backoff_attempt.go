@@ -0,0 +1,64 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// AttemptBackoffFunc is a backoff expressed as a function of the retry number,
+// starting at 1 for the delay before the second attempt. The retry number
+// matches [GetRetryCount] in the attempt that follows the delay.
+type AttemptBackoffFunc func(retry uint64) (time.Duration, bool)
+
+// NewAttemptBackoff creates a new backoff that counts its calls to Next and
+// passes the retry number to f, so f can vary its delay by attempt without
+// keeping its own counter. Once f returns stop, f is no longer called and the
+// backoff keeps stopping. Reset restarts the count and clears the stop.
+//
+// It panics if f is nil. It is safe for concurrent use if f is safe for
+// concurrent use.
+func NewAttemptBackoff(f AttemptBackoffFunc) Backoff {
+	return must(NewAttemptBackoffE(f))
+}
+
+// NewAttemptBackoffE is like [NewAttemptBackoff], but returns an error instead
+// of panicking if f is nil.
+func NewAttemptBackoffE(f AttemptBackoffFunc) (Backoff, error) {
+	if f == nil {
+		return nil, &ValidationError{Field: "f", Reason: "must not be nil"}
+	}
+	return &attemptBackoff{f: f}, nil
+}
+
+type attemptBackoff struct {
+	f       AttemptBackoffFunc
+	retry   uint64
+	stopped atomic.Bool
+}
+
+// Next implements Backoff.
+func (b *attemptBackoff) Next() (time.Duration, bool) {
+	if b.stopped.Load() {
+		return 0, true
+	}
+
+	val, stop := b.f(atomic.AddUint64(&b.retry, 1))
+	if stop {
+		b.stopped.Store(true)
+		return 0, true
+	}
+	return val, false
+}
+
+// Retries returns the number of calls to Next since the backoff was created or
+// reset.
+func (b *attemptBackoff) Retries() uint64 {
+	return atomic.LoadUint64(&b.retry)
+}
+
+// Reset restarts the retry count, so the next call to Next passes 1, and clears
+// any stop.
+func (b *attemptBackoff) Reset() {
+	atomic.StoreUint64(&b.retry, 0)
+	b.stopped.Store(false)
+}
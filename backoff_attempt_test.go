@@ -0,0 +1,104 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestAttemptBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retry_number", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+			return time.Duration(retry) * time.Second, retry > 3
+		})
+
+		var got []time.Duration
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			got = append(got, val)
+		}
+		if want := []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		type retrier interface {
+			Retries() uint64
+			Reset()
+		}
+		r := b.(retrier)
+		if got, want := r.Retries(), uint64(4); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		r.Reset()
+		if val, _ := b.Next(); val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+	})
+
+	t.Run("matches_context", func(t *testing.T) {
+		t.Parallel()
+
+		var retries []uint64
+		b := retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+			retries = append(retries, retry)
+			return 1 * time.Nanosecond, retry >= 3
+		})
+
+		var attempts, counts []uint64
+		_ = retry.Do(context.Background(), b, func(ctx context.Context) error {
+			count, _ := retry.GetRetryCount(ctx)
+			counts = append(counts, count)
+			attempts = append(attempts, retry.AttemptFromContext(ctx))
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+
+		if got, want := attempts, []uint64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		// The retry number passed to the backoff is the retry count of the
+		// attempt that follows.
+		if got, want := retries[:2], counts[1:]; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("outside_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		if got, want := retry.AttemptFromContext(context.Background()), uint64(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleNewAttemptBackoff() {
+	// Retry quickly twice, then slowly.
+	b := retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+		if retry <= 2 {
+			return 10 * time.Millisecond, false
+		}
+		return 1 * time.Second, retry > 5
+	})
+
+	for i := 0; i < 4; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 10ms
+	// 10ms
+	// 1s
+	// 1s
+}
@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// CalendarStep is a delay in calendar units, applied with the semantics of
+// [time.Time.AddDate] followed by [time.Time.Add]. Unlike a time.Duration, a
+// step of one day is always the same wall clock time on the next day, even
+// across a daylight saving time transition, and a step of one month always
+// lands on the same day of the next month, normalized like AddDate.
+type CalendarStep struct {
+	Years, Months, Days int
+
+	// Duration is added after the calendar units.
+	Duration time.Duration
+}
+
+// isForward reports whether s moves time forward. Steps mixing signs are
+// rejected, since whether they move forward depends on the date.
+func (s CalendarStep) isForward() bool {
+	if s.Years < 0 || s.Months < 0 || s.Days < 0 || s.Duration < 0 {
+		return false
+	}
+	return s.Years > 0 || s.Months > 0 || s.Days > 0 || s.Duration > 0
+}
+
+// WithLocation sets the location in which calendar-based backoffs, such as
+// [NewCalendarBackoff], apply calendar units. It defaults to [time.Local].
+func WithLocation(loc *time.Location) BackoffOption {
+	return func(c *backoffConfig) {
+		if loc != nil {
+			c.loc = loc
+		}
+	}
+}
+
+// CalendarBackoff is a backoff whose delays are expressed in calendar units,
+// for job systems that retry over hours or days. Create one with
+// [NewCalendarBackoff].
+//
+// Because the process sleeping between attempts may not survive that long,
+// callers typically compute and persist the time of the next attempt with
+// [CalendarBackoff.NextAttemptAt], along with [CalendarBackoff.Step], and
+// restore the step with [CalendarBackoff.SetStep] after a restart.
+//
+// It is safe for concurrent use.
+type CalendarBackoff struct {
+	steps []CalendarStep
+	loc   *time.Location
+	now   func() time.Time
+
+	lock sync.Mutex
+	step int
+}
+
+// NewCalendarBackoff creates a new backoff that waits for each step in turn,
+// and then keeps waiting for the last step. To stop after a number of steps,
+// wrap it with [WithMaxRetries]. Calendar units are applied in the location
+// set with [WithLocation], and the current time is read with the function set
+// with [WithNowFunc].
+//
+// It panics if steps is empty or a step does not move time forward; see
+// [NewCalendarBackoffE].
+func NewCalendarBackoff(steps []CalendarStep, opts ...BackoffOption) *CalendarBackoff {
+	b, err := NewCalendarBackoffE(steps, opts...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
+
+// NewCalendarBackoffE is like [NewCalendarBackoff], but returns an error instead
+// of panicking if the steps are invalid.
+func NewCalendarBackoffE(steps []CalendarStep, opts ...BackoffOption) (*CalendarBackoff, error) {
+	if len(steps) == 0 {
+		return nil, &ValidationError{Field: "steps", Reason: "must not be empty"}
+	}
+	for _, s := range steps {
+		if !s.isForward() {
+			return nil, &ValidationError{Field: "steps", Reason: "must each move time forward"}
+		}
+	}
+
+	cfg := newBackoffConfig(opts)
+
+	return &CalendarBackoff{
+		steps: append([]CalendarStep(nil), steps...),
+		loc:   cfg.loc,
+		now:   cfg.now,
+	}, nil
+}
+
+// Next implements Backoff. It returns the time from now until the next
+// attempt, as computed by [CalendarBackoff.NextAttemptAt].
+func (b *CalendarBackoff) Next() (time.Duration, bool) {
+	now := b.now()
+	return b.NextAttemptAt(now).Sub(now), false
+}
+
+// NextAttemptAt returns the time of the next attempt after an attempt failed at
+// from, and advances to the next step.
+func (b *CalendarBackoff) NextAttemptAt(from time.Time) time.Time {
+	b.lock.Lock()
+	s := b.steps[min(b.step, len(b.steps)-1)]
+	if b.step < len(b.steps) {
+		b.step++
+	}
+	b.lock.Unlock()
+
+	return from.In(b.loc).AddDate(s.Years, s.Months, s.Days).Add(s.Duration)
+}
+
+// Step returns the number of steps taken so far, which can be persisted and
+// later restored with [CalendarBackoff.SetStep].
+func (b *CalendarBackoff) Step() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.step
+}
+
+// SetStep sets the number of steps taken so far, such as to restore a value
+// returned by [CalendarBackoff.Step] after a restart. It is clamped to the
+// number of steps.
+func (b *CalendarBackoff) SetStep(step int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.step = max(min(step, len(b.steps)), 0)
+}
+
+// Reset returns to the first step.
+func (b *CalendarBackoff) Reset() {
+	b.SetStep(0)
+}
@@ -0,0 +1,149 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestCalendarBackoff(t *testing.T) {
+	t.Parallel()
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := retry.CalendarStep{Days: 1}
+	week := retry.CalendarStep{Days: 7}
+	month := retry.CalendarStep{Months: 1}
+
+	cases := []struct {
+		name  string
+		steps []retry.CalendarStep
+		from  time.Time
+		exp   []time.Time
+	}{
+		{
+			// Clocks spring forward on 2024-03-10, so the day is 23 hours long.
+			name:  "dst_spring_forward",
+			steps: []retry.CalendarStep{day},
+			from:  time.Date(2024, 3, 9, 9, 0, 0, 0, ny),
+			exp: []time.Time{
+				time.Date(2024, 3, 10, 9, 0, 0, 0, ny),
+				time.Date(2024, 3, 11, 9, 0, 0, 0, ny),
+			},
+		},
+		{
+			// Clocks fall back on 2024-11-03, so the day is 25 hours long.
+			name:  "dst_fall_back",
+			steps: []retry.CalendarStep{day, week},
+			from:  time.Date(2024, 11, 2, 9, 0, 0, 0, ny),
+			exp: []time.Time{
+				time.Date(2024, 11, 3, 9, 0, 0, 0, ny),
+				time.Date(2024, 11, 10, 9, 0, 0, 0, ny),
+				time.Date(2024, 11, 17, 9, 0, 0, 0, ny),
+			},
+		},
+		{
+			name:  "month_boundary",
+			steps: []retry.CalendarStep{month},
+			from:  time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+			exp: []time.Time{
+				time.Date(2024, 2, 15, 12, 0, 0, 0, time.UTC),
+				time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// Like AddDate, January 31 plus one month normalizes to March 2 in a
+			// leap year.
+			name:  "month_end",
+			steps: []retry.CalendarStep{month},
+			from:  time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC),
+			exp: []time.Time{
+				time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "duration",
+			steps: []retry.CalendarStep{{Days: 1, Duration: 30 * time.Minute}},
+			from:  time.Date(2024, 12, 31, 23, 45, 0, 0, time.UTC),
+			exp: []time.Time{
+				time.Date(2025, 1, 2, 0, 15, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewCalendarBackoff(tc.steps, retry.WithLocation(tc.from.Location()))
+
+			from := tc.from
+			for i, want := range tc.exp {
+				got := b.NextAttemptAt(from)
+				if !got.Equal(want) {
+					t.Errorf("%d: expected %v to be %v", i, got, want)
+				}
+				from = got
+			}
+		})
+	}
+
+	t.Run("next_across_dst", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2024, 3, 9, 9, 0, 0, 0, ny)
+		b := retry.NewCalendarBackoff([]retry.CalendarStep{day},
+			retry.WithLocation(ny), retry.WithNowFunc(func() time.Time { return now }))
+
+		val, stop := b.Next()
+		if stop {
+			t.Fatal("should not stop")
+		}
+		if got, want := val, 23*time.Hour; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("location", func(t *testing.T) {
+		t.Parallel()
+
+		// A failure at 03:00 UTC is still the previous day in New York.
+		b := retry.NewCalendarBackoff([]retry.CalendarStep{day}, retry.WithLocation(ny))
+		got := b.NextAttemptAt(time.Date(2024, 3, 10, 3, 0, 0, 0, time.UTC))
+		if want := time.Date(2024, 3, 10, 22, 0, 0, 0, ny); !got.Equal(want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		t.Parallel()
+
+		steps := []retry.CalendarStep{day, week}
+		from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+		b := retry.NewCalendarBackoff(steps, retry.WithLocation(time.UTC))
+		b.NextAttemptAt(from)
+		if got, want := b.Step(), 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// A new process restores the step.
+		b = retry.NewCalendarBackoff(steps, retry.WithLocation(time.UTC))
+		b.SetStep(1)
+		if got, want := b.NextAttemptAt(from), from.AddDate(0, 0, 7); !got.Equal(want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		b.Reset()
+		if got, want := b.NextAttemptAt(from), from.AddDate(0, 0, 1); !got.Equal(want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
@@ -14,12 +14,37 @@ func Constant(ctx context.Context, t time.Duration, f RetryFunc) error {
 // NewConstant creates a new constant backoff using the value t. The wait time
 // is the provided constant value. It panics if the given base is less than
 // zero.
+//
+// It is safe for concurrent use.
 func NewConstant(t time.Duration) Backoff {
-	if t <= 0 {
-		panic("t must be greater than 0")
+	return must(NewConstantE(t))
+}
+
+// NewConstantE is like [NewConstant], but returns an error instead of panicking
+// if t is less than or equal to zero.
+func NewConstantE(t time.Duration) (Backoff, error) {
+	if err := validatePositive("t", t); err != nil {
+		return nil, err
 	}
 
-	return BackoffFunc(func() (time.Duration, bool) {
-		return t, false
-	})
+	return &constantBackoff{t: t}, nil
+}
+
+type constantBackoff struct {
+	t time.Duration
+}
+
+// Next implements Backoff.
+func (b *constantBackoff) Next() (time.Duration, bool) {
+	return b.t, false
+}
+
+// Reset does nothing, since a constant backoff has no state. It lets a constant
+// backoff be used wherever a backoff must be resettable, such as with
+// [WithAutoReset].
+func (b *constantBackoff) Reset() {}
+
+// Base returns the constant delay.
+func (b *constantBackoff) Base() time.Duration {
+	return b.t
 }
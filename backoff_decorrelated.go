@@ -0,0 +1,91 @@
+package retry
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// NewDecorrelatedJitter creates a new backoff using "decorrelated jitter", as
+// described in the AWS Architecture Blog post "Exponential Backoff and Jitter".
+// Each delay is a random value between base and three times the previous delay,
+// limited to cap:
+//
+//	sleep = min(cap, random_between(base, sleep*3))
+//
+// The first delay is between base and three times base. Delays are never less
+// than base nor greater than cap, and the backoff never stops. The returned
+// backoff has a Reset method, which starts over from the first delay.
+//
+// It panics if base is less than or equal to zero or cap is less than base. It
+// is safe for concurrent use.
+func NewDecorrelatedJitter(base, cap time.Duration, opts ...BackoffOption) Backoff {
+	return must(NewDecorrelatedJitterE(base, cap, opts...))
+}
+
+// NewDecorrelatedJitterE is like [NewDecorrelatedJitter], but returns an error
+// instead of panicking if the arguments are invalid.
+func NewDecorrelatedJitterE(base, cap time.Duration, opts ...BackoffOption) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
+	}
+	if cap < base {
+		return nil, &ValidationError{Field: "cap", Reason: "must not be less than base"}
+	}
+
+	cfg := newBackoffConfig(opts)
+
+	return &decorrelatedJitterBackoff{
+		base: base,
+		cap:  cap,
+		r:    cfg.random(),
+		prev: base,
+	}, nil
+}
+
+type decorrelatedJitterBackoff struct {
+	base time.Duration
+	cap  time.Duration
+	r    *lockedSource
+
+	lock sync.Mutex
+	prev time.Duration
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *decorrelatedJitterBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	// Saturate rather than overflow when tripling.
+	hi := time.Duration(math.MaxInt64)
+	if b.prev <= hi/3 {
+		hi = b.prev * 3
+	}
+
+	next := b.base + time.Duration(b.r.Int63n(int64(hi-b.base)+1))
+	if next > b.cap {
+		next = b.cap
+	}
+	b.prev = next
+	return next, false
+}
+
+// Reset makes the next delay between base and three times base again. It is
+// safe for concurrent use.
+func (b *decorrelatedJitterBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.prev = b.base
+}
+
+// Base returns the minimum delay.
+func (b *decorrelatedJitterBackoff) Base() time.Duration {
+	return b.base
+}
+
+// Cap returns the maximum delay.
+func (b *decorrelatedJitterBackoff) Cap() time.Duration {
+	return b.cap
+}
@@ -0,0 +1,125 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		base time.Duration
+		cap  time.Duration
+	}{
+		{
+			name: "seconds",
+			base: 1 * time.Second,
+			cap:  1 * time.Minute,
+		},
+		{
+			name: "equal",
+			base: 5 * time.Millisecond,
+			cap:  5 * time.Millisecond,
+		},
+		{
+			name: "nanoseconds",
+			base: 1 * time.Nanosecond,
+			cap:  10 * time.Nanosecond,
+		},
+		{
+			name: "overflow",
+			base: 1 * time.Hour,
+			cap:  math.MaxInt64,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewDecorrelatedJitter(tc.base, tc.cap)
+
+			prev := tc.base
+			for i := 0; i < 10_000; i++ {
+				val, stop := b.Next()
+				if stop {
+					t.Fatalf("should not stop")
+				}
+				if val < tc.base || val > tc.cap {
+					t.Fatalf("expected %v to be between %v and %v", val, tc.base, tc.cap)
+				}
+				if prev <= math.MaxInt64/3 && val > 3*prev {
+					t.Fatalf("expected %v to be at most three times %v", val, prev)
+				}
+				prev = val
+			}
+		})
+	}
+
+	t.Run("non_deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		run := func() []time.Duration {
+			b := retry.NewDecorrelatedJitter(1*time.Millisecond, 1*time.Hour)
+			delays := make([]time.Duration, 20)
+			for i := range delays {
+				delays[i], _ = b.Next()
+			}
+			return delays
+		}
+		if a, b := run(), run(); reflect.DeepEqual(a, b) {
+			t.Errorf("expected %v to differ from %v", a, b)
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		t.Parallel()
+
+		run := func() []time.Duration {
+			b := retry.NewDecorrelatedJitter(1*time.Millisecond, 1*time.Hour, retry.WithRandSeed(1))
+			delays := make([]time.Duration, 20)
+			for i := range delays {
+				delays[i], _ = b.Next()
+			}
+			return delays
+		}
+		if a, b := run(), run(); !reflect.DeepEqual(a, b) {
+			t.Errorf("expected %v to be %v", a, b)
+		}
+	})
+
+	t.Run("invalid_panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if got, want := recover(), "cap must not be less than base"; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}()
+		retry.NewDecorrelatedJitter(2*time.Second, 1*time.Second)
+	})
+}
+
+func ExampleNewDecorrelatedJitter() {
+	b := retry.NewDecorrelatedJitter(1*time.Second, 30*time.Second)
+
+	for i := 0; i < 5; i++ {
+		val, _ := b.Next()
+		fmt.Println(val >= 1*time.Second && val <= 30*time.Second)
+	}
+	// Output:
+	// true
+	// true
+	// true
+	// true
+	// true
+}
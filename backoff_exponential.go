@@ -22,26 +22,114 @@ func Exponential(ctx context.Context, base time.Duration, f RetryFunc) error {
 // base and doubling on each failure (1, 2, 4, 8, 16, 32, 64...), up to max.
 //
 // Once it overflows, the function constantly returns the maximum time.Duration
-// for a 64-bit integer.
+// for a 64-bit integer. The returned backoff has a Reset method, which starts
+// the sequence over from base.
 //
 // It panics if the given base is less than zero.
+//
+// It is safe for concurrent use.
 func NewExponential(base time.Duration) Backoff {
-	if base <= 0 {
-		panic("base must be greater than 0")
+	return must(NewExponentialE(base))
+}
+
+// NewExponentialE is like [NewExponential], but returns an error instead of panicking if base
+// is less than or equal to zero.
+func NewExponentialE(base time.Duration) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
 	}
 
 	return &exponentialBackoff{
 		base: base,
-	}
+	}, nil
 }
 
 // Next implements Backoff. It is safe for concurrent use.
 func (b *exponentialBackoff) Next() (time.Duration, bool) {
-	next := b.base << (atomic.AddUint64(&b.attempt, 1) - 1)
-	if next <= 0 {
+	// Check the shift before making it, since a shifted value that wrapped is not
+	// necessarily negative, such as when concurrent calls skip past the first
+	// overflowing attempt.
+	shift := atomic.AddUint64(&b.attempt, 1) - 1
+	if shift >= 63 || b.base > time.Duration(math.MaxInt64)>>shift {
 		atomic.AddUint64(&b.attempt, ^uint64(0))
-		next = math.MaxInt64
+		return math.MaxInt64, false
+	}
+
+	return b.base << shift, false
+}
+
+// Reset starts the sequence over from base. It is safe for concurrent use.
+func (b *exponentialBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Base returns the base delay.
+func (b *exponentialBackoff) Base() time.Duration {
+	return b.base
+}
+
+// NewExponentialWithFactor creates a new exponential backoff using the starting
+// value of base and multiplying it by factor on each failure. For example, a
+// factor of 1.5 yields 1, 1.5, 2.25, 3.375... times base. NewExponential is
+// equivalent to a factor of 2.
+//
+// Once it overflows, the function constantly returns the maximum time.Duration
+// for a 64-bit integer. The returned backoff has a Reset method, which starts
+// the sequence over from base.
+//
+// It panics if base is less than or equal to zero or factor is not greater than
+// 1.
+//
+// It is safe for concurrent use.
+func NewExponentialWithFactor(base time.Duration, factor float64) Backoff {
+	return must(NewExponentialWithFactorE(base, factor))
+}
+
+// NewExponentialWithFactorE is like [NewExponentialWithFactor], but returns an
+// error instead of panicking if the arguments are invalid.
+func NewExponentialWithFactorE(base time.Duration, factor float64) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
 	}
+	if !(factor > 1) || math.IsInf(factor, 1) {
+		return nil, &ValidationError{Field: "factor", Reason: "must be a finite number greater than 1"}
+	}
+
+	return &factorBackoff{
+		base:   base,
+		factor: factor,
+	}, nil
+}
+
+type factorBackoff struct {
+	base    time.Duration
+	factor  float64
+	attempt uint64
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *factorBackoff) Next() (time.Duration, bool) {
+	n := atomic.AddUint64(&b.attempt, 1)
+	next := float64(b.base) * math.Pow(b.factor, float64(n-1))
+	if next >= math.MaxInt64 {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		return math.MaxInt64, false
+	}
+
+	return time.Duration(next), false
+}
+
+// Reset starts the sequence over from base. It is safe for concurrent use.
+func (b *factorBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Base returns the base delay.
+func (b *factorBackoff) Base() time.Duration {
+	return b.base
+}
 
-	return next, false
+// Factor returns the growth factor.
+func (b *factorBackoff) Factor() float64 {
+	return b.factor
 }
@@ -104,6 +104,68 @@ func TestExponentialBackoff(t *testing.T) {
 	}
 }
 
+func TestExponentialBackoff_overflowBoundary(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		base time.Duration
+		exp  map[int]time.Duration
+	}{
+		{
+			name: "one",
+			base: 1 * time.Nanosecond,
+			exp: map[int]time.Duration{
+				62: 1 << 61,
+				63: 1 << 62,
+				64: math.MaxInt64,
+				65: math.MaxInt64,
+			},
+		},
+		{
+			name: "odd",
+			base: 5 * time.Nanosecond,
+			exp: map[int]time.Duration{
+				60: 5 << 59,
+				61: 5 << 60,
+				62: math.MaxInt64,
+				63: math.MaxInt64,
+				64: math.MaxInt64,
+			},
+		},
+		{
+			name: "max",
+			base: math.MaxInt64,
+			exp: map[int]time.Duration{
+				1: math.MaxInt64,
+				2: math.MaxInt64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewExponential(tc.base)
+			for attempt := 1; attempt <= 65; attempt++ {
+				val, stop := b.Next()
+				if stop {
+					t.Fatalf("attempt %d: should not stop", attempt)
+				}
+				if val <= 0 {
+					t.Fatalf("attempt %d: expected %v to be positive", attempt, val)
+				}
+				if want, ok := tc.exp[attempt]; ok && val != want {
+					t.Errorf("attempt %d: expected %v to be %v", attempt, val, want)
+				}
+			}
+		})
+	}
+}
+
 func ExampleNewExponential() {
 	b := retry.NewExponential(1 * time.Second)
 
@@ -118,3 +180,130 @@ func ExampleNewExponential() {
 	// 8s
 	// 16s
 }
+
+func TestExponentialWithFactorBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		base   time.Duration
+		factor float64
+		tries  int
+		exp    []time.Duration
+	}{
+		{
+			name:   "factor_1.5",
+			base:   1 * time.Second,
+			factor: 1.5,
+			tries:  6,
+			exp: []time.Duration{
+				1 * time.Second,
+				1500 * time.Millisecond,
+				2250 * time.Millisecond,
+				3375 * time.Millisecond,
+				5062500 * time.Microsecond,
+				7593750 * time.Microsecond,
+			},
+		},
+		{
+			name:   "factor_3",
+			base:   1 * time.Millisecond,
+			factor: 3,
+			tries:  5,
+			exp: []time.Duration{
+				1 * time.Millisecond,
+				3 * time.Millisecond,
+				9 * time.Millisecond,
+				27 * time.Millisecond,
+				81 * time.Millisecond,
+			},
+		},
+		{
+			name:   "overflow",
+			base:   100_000 * time.Hour,
+			factor: 3,
+			tries:  6,
+			exp: []time.Duration{
+				100_000 * time.Hour,
+				300_000 * time.Hour,
+				900_000 * time.Hour,
+				math.MaxInt64,
+				math.MaxInt64,
+				math.MaxInt64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewExponentialWithFactor(tc.base, tc.factor)
+
+			resultsCh := make(chan time.Duration, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				go func() {
+					r, _ := b.Next()
+					resultsCh <- r
+				}()
+			}
+
+			results := make([]time.Duration, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				select {
+				case val := <-resultsCh:
+					results[i] = val
+				case <-time.After(5 * time.Second):
+					t.Fatal("timeout")
+				}
+			}
+			sort.Slice(results, func(i, j int) bool {
+				return results[i] < results[j]
+			})
+
+			if !reflect.DeepEqual(results, tc.exp) {
+				t.Errorf("expected \n\n%v\n\n to be \n\n%v\n\n", results, tc.exp)
+			}
+		})
+	}
+}
+
+func TestExponentialWithFactorBackoff_invalidFactor(t *testing.T) {
+	t.Parallel()
+
+	for _, factor := range []float64{1, 0.5, -2, math.NaN(), math.Inf(1)} {
+		factor := factor
+
+		t.Run(fmt.Sprint(factor), func(t *testing.T) {
+			t.Parallel()
+
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatal("expected panic")
+				}
+				if got, want := fmt.Sprint(r), "factor must be a finite number greater than 1"; got != want {
+					t.Errorf("expected %q to be %q", got, want)
+				}
+			}()
+			retry.NewExponentialWithFactor(1*time.Second, factor)
+		})
+	}
+}
+
+func ExampleNewExponentialWithFactor() {
+	b := retry.NewExponentialWithFactor(1*time.Second, 1.5)
+
+	for i := 0; i < 5; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 1s
+	// 1.5s
+	// 2.25s
+	// 3.375s
+	// 5.0625s
+}
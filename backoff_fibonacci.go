@@ -11,6 +11,8 @@ import (
 type state [2]time.Duration
 
 type fibonacciBackoff struct {
+	base  time.Duration
+	max   time.Duration
 	state unsafe.Pointer
 }
 
@@ -25,28 +27,90 @@ func Fibonacci(ctx context.Context, base time.Duration, f RetryFunc) error {
 // attempt (1, 1, 2, 3, 5, 8, 13...).
 //
 // Once it overflows, the function constantly returns the maximum time.Duration
-// for a 64-bit integer.
+// for a 64-bit integer. Use [NewFibonacciCapped] to stop growing at a smaller
+// value. The returned backoff has a Reset method, which starts the sequence
+// over from base.
 //
 // It panics if the given base is less than zero.
+//
+// It is safe for concurrent use.
 func NewFibonacci(base time.Duration) Backoff {
-	if base <= 0 {
-		panic("base must be greater than 0")
+	return must(NewFibonacciE(base))
+}
+
+// NewFibonacciE is like [NewFibonacci], but returns an error instead of panicking if base
+// is less than or equal to zero.
+func NewFibonacciE(base time.Duration) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
+	}
+
+	return newFibonacci(base, math.MaxInt64), nil
+}
+
+// NewFibonacciCapped creates a new Fibonacci backoff like [NewFibonacci], but
+// whose delays stop growing at max. Unlike wrapping a Fibonacci backoff with
+// [WithCappedDuration], the sequence itself plateaus, so it never approaches
+// the maximum time.Duration, however many times it is called.
+//
+// It panics if the given base is less than or equal to zero, or max is less
+// than base.
+//
+// It is safe for concurrent use.
+func NewFibonacciCapped(base, max time.Duration) Backoff {
+	return must(NewFibonacciCappedE(base, max))
+}
+
+// NewFibonacciCappedE is like [NewFibonacciCapped], but returns an error instead of
+// panicking if base is less than or equal to zero, or max is less than base.
+func NewFibonacciCappedE(base, max time.Duration) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
+	}
+	if max < base {
+		return nil, &ValidationError{Field: "max", Reason: "must not be less than base"}
 	}
 
+	return &fibonacciCappedBackoff{newFibonacci(base, max)}, nil
+}
+
+type fibonacciCappedBackoff struct {
+	*fibonacciBackoff
+}
+
+// Cap returns the delay at which the sequence stops growing.
+func (b *fibonacciCappedBackoff) Cap() time.Duration {
+	return b.max
+}
+
+func newFibonacci(base, max time.Duration) *fibonacciBackoff {
 	return &fibonacciBackoff{
+		base:  base,
+		max:   max,
 		state: unsafe.Pointer(&state{0, base}),
 	}
 }
 
+// Reset starts the sequence over from base. It is safe for concurrent use.
+func (b *fibonacciBackoff) Reset() {
+	atomic.StorePointer(&b.state, unsafe.Pointer(&state{0, b.base}))
+}
+
 // Next implements Backoff. It is safe for concurrent use.
 func (b *fibonacciBackoff) Next() (time.Duration, bool) {
 	for {
 		curr := atomic.LoadPointer(&b.state)
 		currState := (*state)(curr)
-		next := currState[0] + currState[1]
 
-		if next <= 0 {
-			return math.MaxInt64, false
+		// Once both terms reach the maximum, the state no longer changes.
+		if currState[0] == b.max {
+			return b.max, false
+		}
+
+		// Both terms are at most max, so the subtraction cannot overflow.
+		next := b.max
+		if currState[0] < b.max-currState[1] {
+			next = currState[0] + currState[1]
 		}
 
 		if atomic.CompareAndSwapPointer(&b.state, curr, unsafe.Pointer(&state{currState[1], next})) {
@@ -54,3 +118,8 @@ func (b *fibonacciBackoff) Next() (time.Duration, bool) {
 		}
 	}
 }
+
+// Base returns the base delay.
+func (b *fibonacciBackoff) Base() time.Duration {
+	return b.base
+}
@@ -0,0 +1,48 @@
+package retry
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFibonacciBackoff_state(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		b    *fibonacciBackoff
+		exp  state
+	}{
+		{
+			name: "uncapped",
+			b:    newFibonacci(100_000*time.Hour, math.MaxInt64),
+			exp:  state{math.MaxInt64, math.MaxInt64},
+		},
+		{
+			name: "capped",
+			b:    newFibonacci(1*time.Second, 1*time.Minute),
+			exp:  state{1 * time.Minute, 1 * time.Minute},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for i := 0; i < 1000; i++ {
+				tc.b.Next()
+
+				s := *(*state)(tc.b.state)
+				if s[0] < 0 || s[1] < 0 || s[0] > tc.b.max || s[1] > tc.b.max {
+					t.Fatalf("call %d: state %v is outside [0, %v]", i, s, tc.b.max)
+				}
+			}
+			if got, want := *(*state)(tc.b.state), tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
@@ -116,6 +116,116 @@ func TestFibonacciBackoff(t *testing.T) {
 	}
 }
 
+func TestFibonacciBackoff_saturates(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewFibonacci(100_000 * time.Hour)
+	for i := 0; i < 1000; i++ {
+		val, stop := b.Next()
+		if stop {
+			t.Fatal("expected not to stop")
+		}
+		if i >= 7 && val != math.MaxInt64 {
+			t.Fatalf("expected call %d to return %v, got %v", i, time.Duration(math.MaxInt64), val)
+		}
+	}
+}
+
+func TestFibonacciCappedBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		base time.Duration
+		max  time.Duration
+		exp  []time.Duration
+	}{
+		{
+			name: "plateau",
+			base: 1 * time.Second,
+			max:  10 * time.Second,
+			exp: []time.Duration{
+				1 * time.Second,
+				2 * time.Second,
+				3 * time.Second,
+				5 * time.Second,
+				8 * time.Second,
+				10 * time.Second,
+				10 * time.Second,
+				10 * time.Second,
+			},
+		},
+		{
+			name: "on_term",
+			base: 1 * time.Second,
+			max:  8 * time.Second,
+			exp: []time.Duration{
+				1 * time.Second,
+				2 * time.Second,
+				3 * time.Second,
+				5 * time.Second,
+				8 * time.Second,
+				8 * time.Second,
+			},
+		},
+		{
+			name: "max_is_base",
+			base: 1 * time.Second,
+			max:  1 * time.Second,
+			exp: []time.Duration{
+				1 * time.Second,
+				1 * time.Second,
+				1 * time.Second,
+			},
+		},
+		{
+			name: "max_duration",
+			base: 100_000 * time.Hour,
+			max:  math.MaxInt64,
+			exp: []time.Duration{
+				100_000 * time.Hour,
+				200_000 * time.Hour,
+				300_000 * time.Hour,
+				500_000 * time.Hour,
+				800_000 * time.Hour,
+				1_300_000 * time.Hour,
+				2_100_000 * time.Hour,
+				math.MaxInt64,
+				math.MaxInt64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewFibonacciCapped(tc.base, tc.max)
+
+			results := make([]time.Duration, 0, len(tc.exp))
+			for range tc.exp {
+				val, stop := b.Next()
+				if stop {
+					t.Fatal("expected not to stop")
+				}
+				results = append(results, val)
+			}
+			if !reflect.DeepEqual(results, tc.exp) {
+				t.Errorf("expected \n\n%v\n\n to be \n\n%v\n\n", results, tc.exp)
+			}
+
+			// The plateau is stable.
+			for i := 0; i < 1000; i++ {
+				if val, _ := b.Next(); val != tc.max {
+					t.Fatalf("expected call %d after the plateau to return %v, got %v", i, tc.max, val)
+				}
+			}
+		})
+	}
+}
+
 func ExampleNewFibonacci() {
 	b := retry.NewFibonacci(1 * time.Second)
 
@@ -130,3 +240,19 @@ func ExampleNewFibonacci() {
 	// 5s
 	// 8s
 }
+
+func ExampleNewFibonacciCapped() {
+	b := retry.NewFibonacciCapped(1*time.Second, 6*time.Second)
+
+	for i := 0; i < 6; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 1s
+	// 2s
+	// 3s
+	// 5s
+	// 6s
+	// 6s
+}
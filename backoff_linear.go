@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+type linearBackoff struct {
+	base    time.Duration
+	attempt uint64
+}
+
+// Linear is a wrapper around Retry that uses a linear backoff. See NewLinear.
+func Linear(ctx context.Context, base time.Duration, f RetryFunc) error {
+	return Do(ctx, NewLinear(base), f)
+}
+
+// NewLinear creates a new linear backoff using the starting value of base and
+// adding base on each failure (1, 2, 3, 4, 5, 6, 7...).
+//
+// Once it overflows, the function constantly returns the maximum time.Duration
+// for a 64-bit integer. The returned backoff has a Reset method, which starts
+// the sequence over from base.
+//
+// It panics if the given base is less than or equal to zero.
+//
+// It is safe for concurrent use.
+func NewLinear(base time.Duration) Backoff {
+	return must(NewLinearE(base))
+}
+
+// NewLinearE is like [NewLinear], but returns an error instead of panicking if
+// base is less than or equal to zero.
+func NewLinearE(base time.Duration) (Backoff, error) {
+	if err := validatePositive("base", base); err != nil {
+		return nil, err
+	}
+
+	return &linearBackoff{
+		base: base,
+	}, nil
+}
+
+// Next implements Backoff. It is safe for concurrent use.
+func (b *linearBackoff) Next() (time.Duration, bool) {
+	n := atomic.AddUint64(&b.attempt, 1)
+	if n > uint64(math.MaxInt64/b.base) {
+		atomic.AddUint64(&b.attempt, ^uint64(0))
+		return math.MaxInt64, false
+	}
+
+	return b.base * time.Duration(n), false
+}
+
+// Reset starts the sequence over from base. It is safe for concurrent use.
+func (b *linearBackoff) Reset() {
+	atomic.StoreUint64(&b.attempt, 0)
+}
+
+// Base returns the base delay.
+func (b *linearBackoff) Base() time.Duration {
+	return b.base
+}
@@ -0,0 +1,124 @@
+package retry_test
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestLinearBackoff(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		base  time.Duration
+		tries int
+		exp   []time.Duration
+	}{
+		{
+			name:  "single",
+			base:  1 * time.Nanosecond,
+			tries: 1,
+			exp: []time.Duration{
+				1 * time.Nanosecond,
+			},
+		},
+		{
+			name:  "max",
+			base:  10 * time.Millisecond,
+			tries: 5,
+			exp: []time.Duration{
+				10 * time.Millisecond,
+				20 * time.Millisecond,
+				30 * time.Millisecond,
+				40 * time.Millisecond,
+				50 * time.Millisecond,
+			},
+		},
+		{
+			name:  "many",
+			base:  1 * time.Nanosecond,
+			tries: 10,
+			exp: []time.Duration{
+				1 * time.Nanosecond,
+				2 * time.Nanosecond,
+				3 * time.Nanosecond,
+				4 * time.Nanosecond,
+				5 * time.Nanosecond,
+				6 * time.Nanosecond,
+				7 * time.Nanosecond,
+				8 * time.Nanosecond,
+				9 * time.Nanosecond,
+				10 * time.Nanosecond,
+			},
+		},
+		{
+			name:  "overflow",
+			base:  time.Duration(math.MaxInt64 / 3),
+			tries: 6,
+			exp: []time.Duration{
+				math.MaxInt64 / 3,
+				math.MaxInt64 / 3 * 2,
+				math.MaxInt64 / 3 * 3,
+				math.MaxInt64,
+				math.MaxInt64,
+				math.MaxInt64,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.NewLinear(tc.base)
+
+			resultsCh := make(chan time.Duration, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				go func() {
+					r, _ := b.Next()
+					resultsCh <- r
+				}()
+			}
+
+			results := make([]time.Duration, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				select {
+				case val := <-resultsCh:
+					results[i] = val
+				case <-time.After(5 * time.Second):
+					t.Fatal("timeout")
+				}
+			}
+			sort.Slice(results, func(i, j int) bool {
+				return results[i] < results[j]
+			})
+
+			if !reflect.DeepEqual(results, tc.exp) {
+				t.Errorf("expected \n\n%v\n\n to be \n\n%v\n\n", results, tc.exp)
+			}
+		})
+	}
+}
+
+func ExampleNewLinear() {
+	b := retry.NewLinear(1 * time.Second)
+
+	for i := 0; i < 5; i++ {
+		val, _ := b.Next()
+		fmt.Printf("%v\n", val)
+	}
+	// Output:
+	// 1s
+	// 2s
+	// 3s
+	// 4s
+	// 5s
+}
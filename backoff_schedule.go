@@ -0,0 +1,92 @@
+package retry
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// NewSchedule creates a new backoff that returns each of durations in order on
+// successive calls to Next, and then stops. It suits hand-tuned schedules such
+// as 100ms, 500ms, 2s, 10s, 30s. The returned backoff has a Reset method, which
+// starts the schedule over.
+//
+// It panics if durations is empty or any duration is less than or equal to
+// zero. It is safe for concurrent use.
+func NewSchedule(durations ...time.Duration) Backoff {
+	return must(NewScheduleE(durations...))
+}
+
+// NewScheduleE is like [NewSchedule], but returns an error instead of
+// panicking if durations is invalid.
+func NewScheduleE(durations ...time.Duration) (Backoff, error) {
+	return newSchedule(durations, false)
+}
+
+// NewScheduleRepeatLast is like [NewSchedule], but keeps returning the last
+// duration once the schedule is exhausted instead of stopping.
+func NewScheduleRepeatLast(durations ...time.Duration) Backoff {
+	return must(NewScheduleRepeatLastE(durations...))
+}
+
+// NewScheduleRepeatLastE is like [NewScheduleRepeatLast], but returns an error
+// instead of panicking if durations is invalid.
+func NewScheduleRepeatLastE(durations ...time.Duration) (Backoff, error) {
+	return newSchedule(durations, true)
+}
+
+func newSchedule(durations []time.Duration, repeatLast bool) (Backoff, error) {
+	if len(durations) == 0 {
+		return nil, &ValidationError{Field: "durations", Reason: "must not be empty"}
+	}
+	for i, d := range durations {
+		if err := validatePositive(fmt.Sprintf("durations[%d]", i), d); err != nil {
+			return nil, err
+		}
+	}
+
+	copied := make([]time.Duration, len(durations))
+	copy(copied, durations)
+	return &scheduleBackoff{
+		durations:  copied,
+		repeatLast: repeatLast,
+	}, nil
+}
+
+type scheduleBackoff struct {
+	durations  []time.Duration
+	repeatLast bool
+
+	// next is the index of the next duration, which stops at the length of
+	// durations.
+	next atomic.Uint64
+}
+
+// Next implements Backoff.
+func (b *scheduleBackoff) Next() (time.Duration, bool) {
+	n := uint64(len(b.durations))
+	for {
+		i := b.next.Load()
+		if i >= n {
+			if b.repeatLast {
+				return b.durations[n-1], false
+			}
+			return 0, true
+		}
+		if b.next.CompareAndSwap(i, i+1) {
+			return b.durations[i], false
+		}
+	}
+}
+
+// Reset starts the schedule over.
+func (b *scheduleBackoff) Reset() {
+	b.next.Store(0)
+}
+
+// Durations returns a copy of the configured durations.
+func (b *scheduleBackoff) Durations() []time.Duration {
+	copied := make([]time.Duration, len(b.durations))
+	copy(copied, b.durations)
+	return copied
+}
@@ -0,0 +1,151 @@
+package retry_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestScheduleBackoff(t *testing.T) {
+	t.Parallel()
+
+	schedule := []time.Duration{
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		2 * time.Second,
+		10 * time.Second,
+		30 * time.Second,
+	}
+
+	cases := []struct {
+		name  string
+		b     func() retry.Backoff
+		tries int
+		exp   []time.Duration
+		stops int
+	}{
+		{
+			name:  "schedule",
+			b:     func() retry.Backoff { return retry.NewSchedule(schedule...) },
+			tries: 8,
+			exp:   schedule,
+			stops: 3,
+		},
+		{
+			name:  "repeat_last",
+			b:     func() retry.Backoff { return retry.NewScheduleRepeatLast(schedule...) },
+			tries: 8,
+			exp:   append(append([]time.Duration{}, schedule...), 30*time.Second, 30*time.Second, 30*time.Second),
+		},
+		{
+			name:  "single",
+			b:     func() retry.Backoff { return retry.NewSchedule(1 * time.Second) },
+			tries: 3,
+			exp:   []time.Duration{1 * time.Second},
+			stops: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.b()
+
+			type result struct {
+				val  time.Duration
+				stop bool
+			}
+			resultsCh := make(chan result, tc.tries)
+			for i := 0; i < tc.tries; i++ {
+				go func() {
+					val, stop := b.Next()
+					resultsCh <- result{val, stop}
+				}()
+			}
+
+			var results []time.Duration
+			var stops int
+			for i := 0; i < tc.tries; i++ {
+				select {
+				case r := <-resultsCh:
+					if r.stop {
+						stops++
+						continue
+					}
+					results = append(results, r.val)
+				case <-time.After(5 * time.Second):
+					t.Fatal("timeout")
+				}
+			}
+			sort.Slice(results, func(i, j int) bool {
+				return results[i] < results[j]
+			})
+
+			// Every duration is handed out exactly once.
+			if !reflect.DeepEqual(results, tc.exp) {
+				t.Errorf("expected \n\n%v\n\n to be \n\n%v\n\n", results, tc.exp)
+			}
+			if got, want := stops, tc.stops; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestScheduleBackoff_reset(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewSchedule(1*time.Second, 2*time.Second)
+
+	var got []time.Duration
+	for round := 0; round < 2; round++ {
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			got = append(got, val)
+		}
+		b.(interface{ Reset() }).Reset()
+	}
+	if got, want := fmt.Sprint(got), "[1s 2s 1s 2s]"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestScheduleBackoff_copies(t *testing.T) {
+	t.Parallel()
+
+	durations := []time.Duration{1 * time.Second, 2 * time.Second}
+	b := retry.NewSchedule(durations...)
+	durations[0] = 1 * time.Hour
+
+	if val, _ := b.Next(); val != 1*time.Second {
+		t.Errorf("expected %v to be %v", val, 1*time.Second)
+	}
+}
+
+func ExampleNewSchedule() {
+	b := retry.NewSchedule(100*time.Millisecond, 500*time.Millisecond, 2*time.Second)
+
+	for {
+		val, stop := b.Next()
+		if stop {
+			fmt.Println("stop")
+			break
+		}
+		fmt.Println(val)
+	}
+	// Output:
+	// 100ms
+	// 500ms
+	// 2s
+	// stop
+}
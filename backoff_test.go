@@ -2,6 +2,13 @@ package retry_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -52,6 +59,62 @@ func TestWithJitter(t *testing.T) {
 	}
 }
 
+func TestWithJitter_shortDelay(t *testing.T) {
+	t.Parallel()
+
+	const base = 100 * time.Millisecond
+	const samples = 100_000
+
+	b := retry.WithJitter(5*base, retry.NewConstant(base), retry.WithRandSeed(1))
+
+	var zeros int
+	var sum time.Duration
+	for i := 0; i < samples; i++ {
+		val, _ := b.Next()
+		if val < 0 || val >= 2*base {
+			t.Fatalf("expected %v to be in [0, %v)", val, 2*base)
+		}
+		if val == 0 {
+			zeros++
+		}
+		sum += val
+	}
+
+	if max := samples / 100; zeros >= max {
+		t.Errorf("expected %d zero delays to be fewer than %d", zeros, max)
+	}
+	if mean := sum / samples; mean < base*98/100 || mean > base*102/100 {
+		t.Errorf("expected mean %v to be within 2%% of %v", mean, base)
+	}
+}
+
+func TestWithJitter_zero(t *testing.T) {
+	t.Parallel()
+
+	exp := []time.Duration{0, 1 * time.Nanosecond, 1 * time.Second, math.MaxInt64}
+	var i int
+	b := retry.WithJitter(0, retry.BackoffFunc(func() (time.Duration, bool) {
+		if i >= len(exp) {
+			return 0, true
+		}
+		i++
+		return exp[i-1], false
+	}))
+
+	for _, want := range exp {
+		got, stop := b.Next()
+		if stop {
+			t.Fatal("should not stop")
+		}
+		if got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}
+	if _, stop := b.Next(); !stop {
+		t.Error("expected to stop")
+	}
+}
+
 func ExampleWithJitter() {
 	ctx := context.Background()
 
@@ -84,6 +147,149 @@ func TestWithJitterPercent(t *testing.T) {
 	}
 }
 
+func TestWithJitterPercent_zero(t *testing.T) {
+	t.Parallel()
+
+	exp := []time.Duration{0, 1 * time.Nanosecond, 1 * time.Second, math.MaxInt64}
+	var i int
+	b := retry.WithJitterPercent(0, retry.BackoffFunc(func() (time.Duration, bool) {
+		if i >= len(exp) {
+			return 0, true
+		}
+		i++
+		return exp[i-1], false
+	}))
+
+	for _, want := range exp {
+		got, stop := b.Next()
+		if stop {
+			t.Fatal("should not stop")
+		}
+		if got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}
+	if _, stop := b.Next(); !stop {
+		t.Error("expected to stop")
+	}
+}
+
+func TestWithJitterPercent_full(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithJitterPercent(100, retry.NewConstant(1*time.Second), retry.WithRandSeed(1))
+
+	var sum time.Duration
+	const samples = 100_000
+	for i := 0; i < samples; i++ {
+		val, stop := b.Next()
+		if stop {
+			t.Fatal("should not stop")
+		}
+		if min, max := time.Duration(0), 2*time.Second; val < min || val > max {
+			t.Fatalf("expected %v to be between %v and %v", val, min, max)
+		}
+		sum += val
+	}
+	if mean := sum / samples; mean < 980*time.Millisecond || mean > 1020*time.Millisecond {
+		t.Errorf("expected mean %v to be within 2%% of %v", mean, 1*time.Second)
+	}
+}
+
+func TestWithFullJitter(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		build    func(next retry.Backoff, opts ...retry.BackoffOption) retry.Backoff
+		min, max time.Duration
+		mean     time.Duration
+	}{
+		{
+			name:  "full",
+			build: retry.WithFullJitter,
+			min:   0,
+			max:   1 * time.Second,
+			mean:  500 * time.Millisecond,
+		},
+		{
+			name:  "equal",
+			build: retry.WithEqualJitter,
+			min:   500 * time.Millisecond,
+			max:   1 * time.Second,
+			mean:  750 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.build(retry.NewConstant(1*time.Second), retry.WithRandSeed(1))
+
+			const samples = 100_000
+			var sum time.Duration
+			for i := 0; i < samples; i++ {
+				val, stop := b.Next()
+				if stop {
+					t.Fatal("should not stop")
+				}
+				if val < tc.min || val > tc.max {
+					t.Fatalf("expected %v to be between %v and %v", val, tc.min, tc.max)
+				}
+				sum += val
+			}
+			if mean, tolerance := sum/samples, 10*time.Millisecond; mean < tc.mean-tolerance || mean > tc.mean+tolerance {
+				t.Errorf("expected mean %v to be within %v of %v", mean, tolerance, tc.mean)
+			}
+		})
+
+		t.Run(tc.name+"_edges", func(t *testing.T) {
+			t.Parallel()
+
+			exp := []time.Duration{0, 1, math.MaxInt64}
+			var i int
+			b := tc.build(retry.BackoffFunc(func() (time.Duration, bool) {
+				if i >= len(exp) {
+					return 0, true
+				}
+				i++
+				return exp[i-1], false
+			}))
+
+			for _, d := range exp {
+				val, stop := b.Next()
+				if stop {
+					t.Fatal("should not stop")
+				}
+				if val < 0 || val > d {
+					t.Errorf("expected %v to be between 0 and %v", val, d)
+				}
+			}
+			if _, stop := b.Next(); !stop {
+				t.Error("expected to stop")
+			}
+		})
+	}
+}
+
+func ExampleWithFullJitter() {
+	ctx := context.Background()
+
+	b := retry.NewExponential(100 * time.Millisecond)
+	b = retry.WithCappedDuration(10*time.Second, b)
+	b = retry.WithFullJitter(b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
 func ExampleWithJitterPercent() {
 	ctx := context.Background()
 
@@ -98,6 +304,63 @@ func ExampleWithJitterPercent() {
 	}
 }
 
+func TestWithRandSource(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		build func(opt retry.BackoffOption) retry.Backoff
+	}{
+		{
+			name: "jitter",
+			build: func(opt retry.BackoffOption) retry.Backoff {
+				return retry.WithJitter(500*time.Millisecond, retry.NewExponential(time.Second), opt)
+			},
+		},
+		{
+			name: "jitter_percent",
+			build: func(opt retry.BackoffOption) retry.Backoff {
+				return retry.WithJitterPercent(20, retry.NewExponential(time.Second), opt)
+			},
+		},
+		{
+			name: "composed",
+			build: func(opt retry.BackoffOption) retry.Backoff {
+				b := retry.WithJitter(500*time.Millisecond, retry.NewFibonacci(time.Second), opt)
+				return retry.WithJitterPercent(20, retry.WithCappedDuration(10*time.Second, b), opt)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			sequence := func(b retry.Backoff) string {
+				var vals []time.Duration
+				for i := 0; i < 20; i++ {
+					val, _ := b.Next()
+					vals = append(vals, val)
+				}
+				return fmt.Sprint(vals)
+			}
+
+			first := sequence(tc.build(retry.WithRandSource(rand.NewSource(7))))
+			second := sequence(tc.build(retry.WithRandSource(rand.NewSource(7))))
+			if got, want := second, first; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			other := sequence(tc.build(retry.WithRandSource(rand.NewSource(8))))
+			if other == first {
+				t.Errorf("expected %v to differ from %v", other, first)
+			}
+		})
+	}
+}
+
 func TestWithMaxRetries(t *testing.T) {
 	t.Parallel()
 
@@ -170,12 +433,140 @@ func ExampleWithCappedDuration() {
 	}
 }
 
+func TestWithMinDuration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("floor", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMinDuration(3*time.Second, retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)))
+
+		val, stop := b.Next()
+		if stop {
+			t.Errorf("should not stop")
+		}
+		if got, want := val, 3*time.Second; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		if _, stop := b.Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		t.Parallel()
+
+		// Jitter of 1s around 1s would reach down to 0.
+		jittered := func() retry.Backoff {
+			return retry.WithJitter(1*time.Second, retry.NewConstant(1*time.Second), retry.WithRandSeed(1))
+		}
+		raw := jittered()
+		b := retry.WithMinDuration(800*time.Millisecond, jittered())
+
+		var raised int
+		for i := 0; i < 1000; i++ {
+			want, _ := raw.Next()
+			val, _ := b.Next()
+			if val < 800*time.Millisecond {
+				t.Fatalf("expected %v to be at least %v", val, 800*time.Millisecond)
+			}
+			if want < 800*time.Millisecond {
+				raised++
+				want = 800 * time.Millisecond
+			}
+			if got := val; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}
+		if raised == 0 {
+			t.Errorf("expected some delays to be raised")
+		}
+	})
+
+	t.Run("ordering", func(t *testing.T) {
+		t.Parallel()
+
+		// The outermost wrapper wins when the minimum exceeds the cap.
+		floored := retry.WithMinDuration(2*time.Second, retry.WithCappedDuration(1*time.Second, retry.NewExponential(1*time.Second)))
+		capped := retry.WithCappedDuration(1*time.Second, retry.WithMinDuration(2*time.Second, retry.NewExponential(1*time.Second)))
+		for i := 0; i < 5; i++ {
+			if got, _ := floored.Next(); got != 2*time.Second {
+				t.Errorf("expected %v to be %v", got, 2*time.Second)
+			}
+			if got, _ := capped.Next(); got != 1*time.Second {
+				t.Errorf("expected %v to be %v", got, 1*time.Second)
+			}
+		}
+
+		// A range within the cap and above the minimum is clamped to both.
+		b := retry.WithCappedDuration(4*time.Second, retry.WithMinDuration(2*time.Second, retry.NewExponential(1*time.Second)))
+		var got []time.Duration
+		for i := 0; i < 5; i++ {
+			val, _ := b.Next()
+			got = append(got, val)
+		}
+		if got, want := fmt.Sprint(got), "[2s 2s 4s 4s 4s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMinDuration(2*time.Second, retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+			return time.Duration(retry) * time.Second, retry > 3
+		}))
+
+		var got []time.Duration
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			got = append(got, val)
+		}
+
+		r, ok := b.(interface{ Reset() })
+		if !ok {
+			t.Fatal("expected backoff to have a Reset method")
+		}
+		r.Reset()
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			got = append(got, val)
+		}
+		if got, want := fmt.Sprint(got), "[2s 2s 3s 2s 2s 3s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithMinDuration() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Second)
+	b = retry.WithJitter(1*time.Second, b)
+	b = retry.WithMinDuration(500*time.Millisecond, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
 func TestWithMaxDuration(t *testing.T) {
 	t.Parallel()
 
+	clock := newFakeClock()
 	b := retry.WithMaxDuration(250*time.Millisecond, retry.BackoffFunc(func() (time.Duration, bool) {
 		return 1 * time.Second, false
-	}))
+	}), retry.WithNowFunc(clock.Now))
 
 	// Take once, within timeout.
 	val, stop := b.Next()
@@ -187,7 +578,7 @@ func TestWithMaxDuration(t *testing.T) {
 		t.Errorf("expected %v to be less than %v", val, 250*time.Millisecond)
 	}
 
-	time.Sleep(200 * time.Millisecond)
+	clock.Advance(200 * time.Millisecond)
 
 	// Take again, remainder contines
 	val, stop = b.Next()
@@ -199,7 +590,7 @@ func TestWithMaxDuration(t *testing.T) {
 		t.Errorf("expected %v to be less than %v", val, 50*time.Millisecond)
 	}
 
-	time.Sleep(50 * time.Millisecond)
+	clock.Advance(50 * time.Millisecond)
 
 	// Now we stop
 	val, stop = b.Next()
@@ -211,6 +602,389 @@ func TestWithMaxDuration(t *testing.T) {
 	}
 }
 
+func TestWithMaxDuration_lazyStart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first_next", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(5*time.Second, retry.NewConstant(1*time.Second),
+			retry.WithNowFunc(clock.Now), retry.WithLazyStart())
+
+		// Time passing before the first call does not consume the budget.
+		clock.Advance(1 * time.Hour)
+		for i := 0; i < 5; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("%d: should not stop", i)
+			}
+			if got, want := val, 1*time.Second; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+			clock.Advance(val)
+		}
+		if _, stop := b.Next(); !stop {
+			t.Errorf("should stop")
+		}
+		if got, want := b.(retry.StopReasoner).StopReason(), retry.ReasonMaxDuration; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("eager", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(5*time.Second, retry.NewConstant(1*time.Second), retry.WithNowFunc(clock.Now))
+
+		clock.Advance(1 * time.Hour)
+		if _, stop := b.Next(); !stop {
+			t.Errorf("should stop")
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		// Every reading of the clock is 1ms later than the previous one.
+		var calls atomic.Int64
+		now := func() time.Time {
+			return time.Unix(0, 0).Add(time.Duration(calls.Add(1)) * time.Millisecond)
+		}
+		b := retry.WithMaxDuration(1*time.Hour, retry.NewConstant(2*time.Hour),
+			retry.WithNowFunc(now), retry.WithLazyStart())
+
+		const workers = 50
+		vals := make([]time.Duration, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				val, stop := b.Next()
+				if stop {
+					t.Errorf("should not stop")
+				}
+				vals[i] = val
+			}(i)
+		}
+		wg.Wait()
+
+		// With a single start time, every delay is the remaining budget measured
+		// from one of the readings in this window.
+		window := time.Duration(calls.Load()) * time.Millisecond
+		for i, val := range vals {
+			if val > 1*time.Hour || val < 1*time.Hour-window {
+				t.Errorf("%d: expected %v to be within %v of %v", i, val, window, 1*time.Hour)
+			}
+		}
+	})
+
+	for _, lazy := range []bool{false, true} {
+		lazy := lazy
+
+		t.Run(fmt.Sprintf("reset_lazy_%t", lazy), func(t *testing.T) {
+			t.Parallel()
+
+			clock := newFakeClock()
+			opts := []retry.BackoffOption{retry.WithNowFunc(clock.Now)}
+			if lazy {
+				opts = append(opts, retry.WithLazyStart())
+			}
+			b := retry.WithMaxDuration(5*time.Second, retry.NewConstant(10*time.Second), opts...)
+
+			// The final delay is truncated, and then the backoff stops.
+			if val, _ := b.Next(); val != 5*time.Second {
+				t.Errorf("expected %v to be %v", val, 5*time.Second)
+			}
+			clock.Advance(5 * time.Second)
+			if _, stop := b.Next(); !stop {
+				t.Fatalf("should stop")
+			}
+
+			clock.Advance(1 * time.Minute)
+			b.(interface{ Reset() }).Reset()
+			if lazy {
+				clock.Advance(1 * time.Minute)
+			}
+
+			// Reset restores the full budget and clears the stop.
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("should not stop")
+			}
+			if got, want := val, 5*time.Second; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := b.(retry.StopReasoner).StopReason(), retry.ReasonNone; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+// lateClock is a fake clock whose sleeps end late by the next duration in
+// late, simulating timer starvation.
+type lateClock struct {
+	*fakeClock
+	late []time.Duration
+}
+
+func (c *lateClock) Sleep(ctx context.Context, d time.Duration) error {
+	if len(c.late) > 0 {
+		d += c.late[0]
+		c.late = c.late[1:]
+	}
+	return c.fakeClock.Sleep(ctx, d)
+}
+
+func TestWithMaxDuration_lateWakeUp(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	cases := []struct {
+		name   string
+		late   []time.Duration
+		opts   []retry.DoOption
+		calls  int
+		reason retry.StopReason
+	}{
+		{
+			name:   "budget_blown",
+			late:   []time.Duration{0, 30 * time.Second},
+			calls:  2,
+			reason: retry.ReasonMaxDuration,
+		},
+		{
+			name:   "late_within_budget",
+			late:   []time.Duration{2 * time.Second},
+			calls:  4,
+			reason: retry.ReasonBudgetTruncatedFinalSleep,
+		},
+		{
+			name:   "recheck_disabled",
+			late:   []time.Duration{0, 30 * time.Second},
+			opts:   []retry.DoOption{retry.WithoutBudgetRecheck()},
+			calls:  3,
+			reason: retry.ReasonMaxDuration,
+		},
+		{
+			name:   "truncated_final_sleep",
+			calls:  5,
+			reason: retry.ReasonBudgetTruncatedFinalSleep,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &lateClock{fakeClock: newFakeClock(), late: tc.late}
+			b := retry.WithMaxDuration(10*time.Second, retry.NewConstant(3*time.Second), retry.WithNowFunc(clock.Now))
+
+			var calls int
+			var reason retry.StopReason
+			err := retry.Do(context.Background(), b, func(_ context.Context) error {
+				calls++
+				return retry.RetryableError(errFail)
+			}, append(tc.opts, retry.WithClock(clock), retry.WithStopHook(func(r retry.StopReason, _ error) {
+				reason = r
+			}))...)
+			if got, want := err, errFail; !errors.Is(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := reason, tc.reason; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithMaxDuration_bounds(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		timeout time.Duration
+		next    retry.Backoff
+		step    time.Duration
+		calls   int
+		stopped bool
+	}{
+		{
+			name:    "max_int64",
+			timeout: math.MaxInt64,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    1 * time.Hour,
+			calls:   100,
+		},
+		{
+			name:    "max_int64_with_jitter",
+			timeout: math.MaxInt64,
+			next:    retry.WithJitter(1*time.Second, retry.NewConstant(1*time.Millisecond)),
+			step:    1 * time.Hour,
+			calls:   100,
+		},
+		{
+			name:    "max_int64_clock_backward",
+			timeout: math.MaxInt64,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    -1 * time.Hour,
+			calls:   100,
+		},
+		{
+			name:    "near_max_int64_clock_backward",
+			timeout: math.MaxInt64 - 1,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    math.MinInt64,
+			calls:   100,
+		},
+		{
+			name:    "unbounded",
+			timeout: retry.UnboundedDuration,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    math.MaxInt64,
+			calls:   100,
+		},
+		{
+			name:    "clock_backward",
+			timeout: 10 * time.Second,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    -1 * time.Hour,
+			calls:   100,
+		},
+		{
+			name:    "zero",
+			timeout: 0,
+			next:    retry.NewConstant(1 * time.Second),
+			calls:   0,
+			stopped: true,
+		},
+		{
+			name:    "negative",
+			timeout: -1 * time.Second,
+			next:    retry.NewConstant(1 * time.Second),
+			step:    math.MaxInt64,
+			calls:   0,
+			stopped: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := newFakeClock()
+			b := retry.WithMaxDuration(tc.timeout, tc.next, retry.WithNowFunc(clock.Now))
+
+			var calls int
+			for ; calls < 100; calls++ {
+				val, stop := b.Next()
+				if stop {
+					break
+				}
+				if val < 0 {
+					t.Fatalf("call %d: expected %v to not be negative", calls, val)
+				}
+				clock.Advance(tc.step)
+			}
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if _, stop := b.Next(); stop != tc.stopped {
+				t.Errorf("expected stop %t to be %t", stop, tc.stopped)
+			}
+		})
+	}
+}
+
+func TestWithMaxDuration_stopReason(t *testing.T) {
+	t.Parallel()
+
+	t.Run("truncated_final_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(250*time.Millisecond, retry.NewConstant(100*time.Millisecond),
+			retry.WithNowFunc(clock.Now))
+		sr := b.(retry.StopReasoner)
+
+		for i, want := range []time.Duration{
+			100 * time.Millisecond,
+			100 * time.Millisecond,
+			50 * time.Millisecond, // truncated to the remaining budget
+		} {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("%d: should not stop", i)
+			}
+			if got := val; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+			if got, want := sr.StopReason(), retry.ReasonNone; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+			clock.Advance(val)
+		}
+
+		if _, stop := b.Next(); !stop {
+			t.Fatal("should stop")
+		}
+		if got, want := sr.StopReason(), retry.ReasonBudgetTruncatedFinalSleep; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exact_budget", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(200*time.Millisecond, retry.NewConstant(100*time.Millisecond),
+			retry.WithNowFunc(clock.Now))
+		sr := b.(retry.StopReasoner)
+
+		for i := 0; i < 2; i++ {
+			val, stop := b.Next()
+			if stop {
+				t.Fatalf("%d: should not stop", i)
+			}
+			clock.Advance(val)
+		}
+
+		if _, stop := b.Next(); !stop {
+			t.Fatal("should stop")
+		}
+		if got, want := sr.StopReason(), retry.ReasonMaxDuration; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("next_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(1*time.Second, retry.WithMaxRetries(0, retry.NewConstant(100*time.Millisecond)),
+			retry.WithNowFunc(clock.Now))
+		sr := b.(retry.StopReasoner)
+
+		if _, stop := b.Next(); !stop {
+			t.Fatal("should stop")
+		}
+		if got, want := sr.StopReason(), retry.ReasonStopped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
 func ExampleWithMaxDuration() {
 	ctx := context.Background()
 
@@ -224,3 +998,347 @@ func ExampleWithMaxDuration() {
 		// handle error
 	}
 }
+
+// fakeClock is a manually-advanced clock for use with [retry.WithNowFunc].
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(0, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.Advance(d)
+	return nil
+}
+
+func TestWithErrorBudgets(t *testing.T) {
+	t.Parallel()
+
+	errRefused := errors.New("connection refused")
+	errTimeout := errors.New("timeout")
+	errOther := errors.New("other")
+
+	classify := func(err error) string {
+		switch {
+		case errors.Is(err, errRefused):
+			return "refused"
+		case errors.Is(err, errTimeout):
+			return "timeout"
+		default:
+			return ""
+		}
+	}
+
+	newBackoff := func() retry.ErrorBackoff {
+		b := retry.WithErrorBudgets(map[string]uint64{
+			"refused": 3,
+			"timeout": 1,
+			"":        2,
+		}, classify, retry.NewConstant(1*time.Second))
+		return b.(retry.ErrorBackoff)
+	}
+
+	t.Run("per_class", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff()
+
+		// Interleave the classes; each has its own counter. Once one class is
+		// exhausted, every class stops.
+		for i, tc := range []struct {
+			err  error
+			stop bool
+		}{
+			{errRefused, false},
+			{errTimeout, false},
+			{errRefused, false},
+			{errOther, false},
+			{errRefused, false},
+			{errOther, false},
+			{errTimeout, true},
+			{errRefused, true},
+			{errOther, true},
+		} {
+			_, stop := b.NextError(tc.err)
+			if got, want := stop, tc.stop; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("next_does_not_consume", func(t *testing.T) {
+		t.Parallel()
+
+		b := newBackoff()
+		for i := 0; i < 10; i++ {
+			if _, stop := b.Next(); stop {
+				t.Fatal("should not stop")
+			}
+		}
+		if _, stop := b.NextError(errTimeout); stop {
+			t.Error("should not stop")
+		}
+	})
+
+	t.Run("do", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithErrorBudgets(map[string]uint64{
+			"refused": 8,
+			"timeout": 2,
+		}, classify, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			if i%2 == 0 {
+				return retry.RetryableError(errTimeout)
+			}
+			return retry.RetryableError(errRefused)
+		})
+		if !errors.Is(err, errTimeout) {
+			t.Errorf("expected %v to be %v", err, errTimeout)
+		}
+
+		// refused, timeout, refused, timeout, refused, timeout (exhausted)
+		if got, want := i, 6; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithErrorBudgets() {
+	ctx := context.Background()
+
+	classify := func(err error) string {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return "refused"
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "timeout"
+		}
+		return ""
+	}
+
+	b := retry.NewExponential(1 * time.Second)
+	b = retry.WithErrorBudgets(map[string]uint64{
+		"refused": 8,
+		"timeout": 2,
+		"":        3,
+	}, classify, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestWithQuantizedDelay(t *testing.T) {
+	t.Parallel()
+
+	const q = 15 * time.Second
+
+	cases := []struct {
+		name string
+		mode retry.RoundMode
+		in   time.Duration
+		exp  time.Duration
+	}{
+		{"up_zero", retry.RoundUp, 0, 0},
+		{"up_exact", retry.RoundUp, 30 * time.Second, 30 * time.Second},
+		{"up_just_under", retry.RoundUp, 30*time.Second - 1, 30 * time.Second},
+		{"up_just_over", retry.RoundUp, 30*time.Second + 1, 45 * time.Second},
+		{"up_small", retry.RoundUp, 1 * time.Second, 15 * time.Second},
+		{"up_overflow", retry.RoundUp, math.MaxInt64, math.MaxInt64 - math.MaxInt64%q},
+
+		{"down_zero", retry.RoundDown, 0, 0},
+		{"down_exact", retry.RoundDown, 30 * time.Second, 30 * time.Second},
+		{"down_just_under", retry.RoundDown, 30*time.Second - 1, 15 * time.Second},
+		{"down_just_over", retry.RoundDown, 30*time.Second + 1, 30 * time.Second},
+		{"down_small", retry.RoundDown, 1 * time.Second, 15 * time.Second},
+
+		{"nearest_zero", retry.RoundNearest, 0, 0},
+		{"nearest_exact", retry.RoundNearest, 30 * time.Second, 30 * time.Second},
+		{"nearest_just_under", retry.RoundNearest, 30*time.Second - 1, 30 * time.Second},
+		{"nearest_just_over", retry.RoundNearest, 30*time.Second + 1, 30 * time.Second},
+		{"nearest_half", retry.RoundNearest, 37500 * time.Millisecond, 45 * time.Second},
+		{"nearest_below_half", retry.RoundNearest, 37500*time.Millisecond - 1, 30 * time.Second},
+		{"nearest_small", retry.RoundNearest, 1 * time.Second, 15 * time.Second},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.WithQuantizedDelay(q, tc.mode, retry.BackoffFunc(func() (time.Duration, bool) {
+				return tc.in, false
+			}))
+
+			val, stop := b.Next()
+			if stop {
+				t.Errorf("should not stop")
+			}
+			if got, want := val, tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithQuantizedDelay(q, retry.RoundUp, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, true
+		}))
+
+		val, stop := b.Next()
+		if !stop {
+			t.Errorf("should stop")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+	})
+}
+
+func ExampleWithQuantizedDelay() {
+	ctx := context.Background()
+
+	b := retry.NewFibonacci(1 * time.Second)
+	b = retry.WithJitterPercent(10, b)
+	b = retry.WithQuantizedDelay(15*time.Second, retry.RoundUp, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
+
+func TestBackoff_Reset(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		exp  []time.Duration
+	}{
+		{
+			name: "constant",
+			b:    retry.NewConstant(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 1 * time.Second},
+		},
+		{
+			name: "linear",
+			b:    retry.NewLinear(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "exponential",
+			b:    retry.NewExponential(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			name: "exponential_factor",
+			b:    retry.NewExponentialWithFactor(2*time.Second, 1.5),
+			exp:  []time.Duration{2 * time.Second, 3 * time.Second, 4500 * time.Millisecond},
+		},
+		{
+			name: "fibonacci",
+			b:    retry.NewFibonacci(1 * time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second},
+		},
+		{
+			name: "fibonacci_capped",
+			b:    retry.NewFibonacciCapped(1*time.Second, 3*time.Second),
+			exp:  []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "middleware",
+			b: retry.WithQuantizedDelay(1*time.Second, retry.RoundUp,
+				retry.WithCappedDuration(3*time.Second,
+					retry.WithJitter(0, retry.WithJitterPercent(0, retry.NewExponential(1*time.Second))))),
+			exp: []time.Duration{1 * time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, ok := tc.b.(interface{ Reset() })
+			if !ok {
+				t.Fatalf("expected %T to have a Reset method", tc.b)
+			}
+
+			// The sequence is the same before and after a reset.
+			for i := 0; i < 2; i++ {
+				for _, want := range tc.exp {
+					val, _ := tc.b.Next()
+					if got := val; got != want {
+						t.Errorf("expected %v to be %v", got, want)
+					}
+				}
+				r.Reset()
+			}
+		})
+	}
+
+	t.Run("decorrelated_jitter", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewDecorrelatedJitter(1*time.Second, 1*time.Hour)
+		for i := 0; i < 20; i++ {
+			b.Next()
+		}
+		b.(interface{ Reset() }).Reset()
+		if val, _ := b.Next(); val > 3*time.Second {
+			t.Errorf("expected %v to be at most %v", val, 3*time.Second)
+		}
+	})
+
+	t.Run("max_retries", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(1, retry.NewExponential(1*time.Second))
+		b.Next()
+		if _, stop := b.Next(); !stop {
+			t.Fatal("expected stop")
+		}
+
+		b.(interface{ Reset() }).Reset()
+		val, stop := b.Next()
+		if stop {
+			t.Fatal("expected the retries to be restored")
+		}
+		if got, want := val, 1*time.Second; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package retry
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	_ ObserverBackoff = (*budgetBackoff)(nil)
+	_ StopReasoner    = (*budgetBackoff)(nil)
+	_ callEnder       = (*budgetBackoff)(nil)
+)
+
+// budgetSlots is the number of slots the window of a Budget is divided into.
+// Requests and retries expire one slot at a time.
+const budgetSlots = 10
+
+// Budget limits retries to a fraction of recent request volume, shared by
+// every retry loop that uses it, like the retry budgets of gRPC and Finagle.
+// During an outage, retries then add a bounded amount of load, rather than
+// multiplying it by the number of attempts allowed per request.
+//
+// Over a sliding window, ten seconds by default, the budget allows ratio
+// retries per request, plus minRetriesPerSecond retries per second so that
+// services with little traffic can still retry. Requests are counted by the
+// backoffs returned by [Budget.Wrap], on the first attempt of each call to
+// [Do], or with [Budget.Deposit].
+//
+// It is safe for concurrent use.
+type Budget struct {
+	ratio        float64
+	minPerSecond float64
+	window       time.Duration
+	clock        Clock
+
+	lock  sync.Mutex
+	slots [budgetSlots]budgetSlot
+}
+
+// budgetSlot counts the requests and retries in one slot of the window.
+type budgetSlot struct {
+	epoch    int64
+	requests float64
+	retries  float64
+}
+
+// BudgetOption is an option that configures a [Budget].
+type BudgetOption func(b *Budget)
+
+// WithBudgetClock sets the clock used by the budget to expire requests and
+// retries. It is primarily useful for driving time in tests.
+func WithBudgetClock(c Clock) BudgetOption {
+	return func(b *Budget) {
+		if c != nil {
+			b.clock = c
+		}
+	}
+}
+
+// WithBudgetWindow sets the duration over which a [Budget] counts requests and
+// retries. The default is ten seconds.
+func WithBudgetWindow(d time.Duration) BudgetOption {
+	return func(b *Budget) {
+		b.window = d
+	}
+}
+
+// NewBudget creates a new retry budget that allows ratio retries per request,
+// such as 0.1 for retries to add at most 10% to the load, plus
+// minRetriesPerSecond retries per second.
+//
+// It panics if ratio or minRetriesPerSecond is negative, NaN, or infinite, or
+// the window is not positive; see [NewBudgetE].
+func NewBudget(ratio, minRetriesPerSecond float64, opts ...BudgetOption) *Budget {
+	b, err := NewBudgetE(ratio, minRetriesPerSecond, opts...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
+
+// NewBudgetE is like [NewBudget], but returns an error instead of panicking if
+// the arguments are invalid.
+func NewBudgetE(ratio, minRetriesPerSecond float64, opts ...BudgetOption) (*Budget, error) {
+	// The negated comparisons also reject NaN.
+	if !(ratio >= 0) || math.IsInf(ratio, 1) {
+		return nil, &ValidationError{Field: "ratio", Reason: "must be a finite number greater than or equal to 0"}
+	}
+	if !(minRetriesPerSecond >= 0) || math.IsInf(minRetriesPerSecond, 1) {
+		return nil, &ValidationError{Field: "minRetriesPerSecond", Reason: "must be a finite number greater than or equal to 0"}
+	}
+
+	b := &Budget{
+		ratio:        ratio,
+		minPerSecond: minRetriesPerSecond,
+		window:       10 * time.Second,
+		clock:        realClock{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if err := validatePositive("window", b.window); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Deposit records a request, which adds ratio retries to the budget. Requests
+// made with a backoff from [Budget.Wrap] are recorded automatically.
+func (b *Budget) Deposit() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.slot(b.clock.Now()).requests++
+}
+
+// withdraw takes a retry from the budget and returns true if one is available.
+func (b *Budget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.clock.Now()
+	curr := b.slot(now)
+
+	var requests, retries float64
+	for _, s := range b.slots {
+		if s.epoch > curr.epoch-budgetSlots {
+			requests += s.requests
+			retries += s.retries
+		}
+	}
+
+	balance := b.minPerSecond*b.window.Seconds() + b.ratio*requests - retries
+	if balance < 1 {
+		return false
+	}
+	curr.retries++
+	return true
+}
+
+// slot returns the slot of the window for now, clearing it if it held an
+// earlier slot. The caller must hold the lock.
+func (b *Budget) slot(now time.Time) *budgetSlot {
+	width := max(b.window/budgetSlots, 1)
+	epoch := now.UnixNano() / int64(width)
+
+	s := &b.slots[(epoch%budgetSlots+budgetSlots)%budgetSlots]
+	if s.epoch != epoch {
+		*s = budgetSlot{epoch: epoch}
+	}
+	return s
+}
+
+// Wrap returns a backoff that takes a retry from the budget on every call to
+// Next, and stops with the reason [ReasonRetryBudget] when none is available,
+// without waiting. Once stopped, it keeps stopping until the call to [Do]
+// returns or it is reset, even if the budget refills. The returned backoff
+// records a request for every call to Do that uses it, so it should be used by
+// one call at a time, while the budget is shared.
+//
+// It panics if next is nil.
+func (b *Budget) Wrap(next Backoff) Backoff {
+	if err := validateNext(next); err != nil {
+		panic(err.Error())
+	}
+
+	return &budgetBackoff{
+		budget: b,
+		next:   next,
+	}
+}
+
+type budgetBackoff struct {
+	budget *Budget
+	next   Backoff
+
+	// counted is true once the request of the current call was recorded.
+	counted atomic.Bool
+	reason  atomic.Int32
+}
+
+// Next implements Backoff.
+func (b *budgetBackoff) Next() (time.Duration, bool) {
+	// Keep stopping for the rest of the call.
+	if StopReason(b.reason.Load()) != ReasonNone {
+		return 0, true
+	}
+
+	// The retry loop calls Next before reporting the first attempt, so the
+	// request is recorded here to count toward its own retries.
+	if b.counted.CompareAndSwap(false, true) {
+		b.budget.Deposit()
+	}
+
+	val, stop := b.next.Next()
+	if stop {
+		b.reason.Store(int32(stopReasonOf(b.next)))
+		return 0, true
+	}
+
+	if !b.budget.withdraw() {
+		b.reason.Store(int32(ReasonRetryBudget))
+		return 0, true
+	}
+	return val, false
+}
+
+// Observe implements ObserverBackoff. The first attempt of a call is recorded
+// as a request, unless Next already recorded it.
+func (b *budgetBackoff) Observe(o Outcome) {
+	if o.Attempt == 1 && b.counted.CompareAndSwap(false, true) {
+		b.budget.Deposit()
+	}
+}
+
+// endCall implements callEnder. The next attempt starts a new call, which is
+// recorded as a request and may retry again.
+func (b *budgetBackoff) endCall() {
+	b.counted.Store(false)
+	b.reason.Store(int32(ReasonNone))
+}
+
+// StopReason implements StopReasoner.
+func (b *budgetBackoff) StopReason() StopReason {
+	return StopReason(b.reason.Load())
+}
+
+// Reset clears the stop reason and resets next if it has a Reset method.
+func (b *budgetBackoff) Reset() {
+	b.counted.Store(false)
+	b.reason.Store(int32(ReasonNone))
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// Unwrap implements Wrapper.
+func (b *budgetBackoff) Unwrap() Backoff {
+	return b.next
+}
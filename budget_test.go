@@ -0,0 +1,267 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	// retries calls Next on b until it stops and returns the number of retries
+	// it allowed.
+	retries := func(t *testing.T, b retry.Backoff) int {
+		t.Helper()
+
+		var n int
+		for {
+			if _, stop := b.Next(); stop {
+				break
+			}
+			n++
+			if n > 1000 {
+				t.Fatal("should stop")
+			}
+		}
+		if got, want := b.(retry.StopReasoner).StopReason(), retry.ReasonRetryBudget; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		return n
+	}
+
+	t.Run("ratio", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		budget := retry.NewBudget(0.5, 0, retry.WithBudgetClock(clock))
+		for i := 0; i < 20; i++ {
+			budget.Deposit()
+		}
+
+		b := budget.Wrap(retry.NewConstant(1 * time.Second))
+		if got, want := retries(t, b), 10; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("min_retries_per_second", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		budget := retry.NewBudget(0, 2, retry.WithBudgetClock(clock), retry.WithBudgetWindow(5*time.Second))
+
+		b := budget.Wrap(retry.NewConstant(1 * time.Second))
+		if got, want := retries(t, b), 10; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("window", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		budget := retry.NewBudget(0.5, 0, retry.WithBudgetClock(clock))
+		b := budget.Wrap(retry.NewConstant(1 * time.Second))
+
+		for i := 0; i < 4; i++ {
+			budget.Deposit()
+		}
+		clock.Advance(5 * time.Second)
+		for i := 0; i < 4; i++ {
+			budget.Deposit()
+		}
+		if got, want := retries(t, b), 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The backoff keeps stopping until it is reset, which starts a new call
+		// and so records another request.
+		reset := b.(interface{ Reset() }).Reset
+
+		// The first requests expire, and their share of the retries is not
+		// returned until the retries expire too.
+		clock.Advance(5 * time.Second)
+		reset()
+		if got, want := retries(t, b), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		for i := 0; i < 2; i++ {
+			budget.Deposit()
+		}
+		if got, want := retries(t, b), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// Once the retries expire, the remaining requests allow retries again.
+		clock.Advance(5 * time.Second)
+		reset()
+		if got, want := retries(t, b), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("sticky", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		budget := retry.NewBudget(0.5, 0, retry.WithBudgetClock(clock))
+		b := budget.Wrap(retry.NewConstant(1 * time.Second))
+		if got, want := retries(t, b), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The budget refills, but the backoff keeps stopping.
+		for i := 0; i < 20; i++ {
+			budget.Deposit()
+		}
+		if _, stop := b.Next(); !stop {
+			t.Error("should stop")
+		}
+		if got, want := b.(retry.StopReasoner).StopReason(), retry.ReasonRetryBudget; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("next_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		budget := retry.NewBudget(0, 100, retry.WithBudgetClock(newFakeClock()))
+		b := budget.Wrap(retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)))
+
+		if _, stop := b.Next(); stop {
+			t.Fatal("should not stop")
+		}
+		if _, stop := b.Next(); !stop {
+			t.Fatal("should stop")
+		}
+		if got, want := b.(retry.StopReasoner).StopReason(), retry.ReasonStopped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestBudget_do(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	t.Run("sequential", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		budget := retry.NewBudget(0.5, 0, retry.WithBudgetClock(clock))
+
+		var attempts int
+		var limited int
+		for i := 0; i < 20; i++ {
+			err := retry.Do(context.Background(), budget.Wrap(retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))), func(_ context.Context) error {
+				attempts++
+				return retry.RetryableError(errFail)
+			}, retry.WithStopHook(func(reason retry.StopReason, _ error) {
+				if reason == retry.ReasonRetryBudget {
+					limited++
+				}
+			}))
+			if !errors.Is(err, errFail) {
+				t.Fatalf("expected %v to be %v", err, errFail)
+			}
+		}
+
+		// Without the budget, there would be 80 attempts.
+		if got, want := attempts, 30; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := limited, 20; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("zero_delay", func(t *testing.T) {
+		t.Parallel()
+
+		budget := retry.NewBudget(1, 0, retry.WithBudgetClock(newFakeClock()))
+		zero := retry.BackoffFunc(func() (time.Duration, bool) {
+			return 0, false
+		})
+
+		// Retrying without a delay still belongs to the same request, which
+		// allows a single retry.
+		var attempts int
+		_ = retry.Do(context.Background(), budget.Wrap(retry.WithMaxRetries(10, zero)), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(errFail)
+		})
+		if got, want := attempts, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		const calls = 200
+		budget := retry.NewBudget(0.25, 0, retry.WithBudgetClock(newFakeClock()))
+
+		var attempts atomic.Int64
+		var wg sync.WaitGroup
+		for i := 0; i < calls; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_ = retry.Do(context.Background(), budget.Wrap(retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))), func(_ context.Context) error {
+					attempts.Add(1)
+					return retry.RetryableError(errFail)
+				})
+			}()
+		}
+		wg.Wait()
+
+		if got, max := attempts.Load(), int64(calls+calls/4); got > max {
+			t.Errorf("expected %v to be at most %v", got, max)
+		}
+	})
+}
+
+func TestNewBudgetE(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		ratio float64
+		min   float64
+		opts  []retry.BudgetOption
+		field string
+	}{
+		{"negative_ratio", -1, 1, nil, "ratio"},
+		{"nan_ratio", math.NaN(), 1, nil, "ratio"},
+		{"infinite_ratio", math.Inf(1), 1, nil, "ratio"},
+		{"negative_min", 0.1, -1, nil, "minRetriesPerSecond"},
+		{"nan_min", 0.1, math.NaN(), nil, "minRetriesPerSecond"},
+		{"zero_window", 0.1, 1, []retry.BudgetOption{retry.WithBudgetWindow(0)}, "window"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := retry.NewBudgetE(tc.ratio, tc.min, tc.opts...)
+			var verr *retry.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			if got, want := verr.Field, tc.field; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package retry
+
+import (
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCallSites is the number of call sites tracked by [WithCallerAttribution].
+// Calls from further call sites are not counted.
+const maxCallSites = 1024
+
+// retryPackagePrefix prefixes the names of functions in this package, which
+// are skipped when looking for the caller.
+const retryPackagePrefix = "github.com/sethvargo/go-retry."
+
+// CallSiteStat is the number of attempts and exhaustions attributed to a call
+// site by [WithCallerAttribution].
+type CallSiteStat struct {
+	// File and Line locate the call into this package.
+	File string
+	Line int
+
+	// Function is the fully-qualified name of the calling function.
+	Function string
+
+	// Attempts is the number of attempts made by calls from this site.
+	Attempts uint64
+
+	// Exhaustions is the number of calls from this site that stopped retrying
+	// a retryable error, such as because the backoff stopped. These are the
+	// calls reported to [WithStopHook].
+	Exhaustions uint64
+}
+
+// WithCallerAttribution attributes the attempts and exhaustions of the call to
+// the code that called into this package, such as the caller of [Do], and
+// counts them in package-level statistics read with [CallSiteStats]. This
+// answers which code paths retry the most.
+//
+// Finding the caller walks a few stack frames on every call, so attribution is
+// off unless this option is given. Calls made from goroutines started by this
+// package, such as by [FirstSuccess], have no caller outside of it and are not
+// counted. At most 1024 call sites are tracked.
+func WithCallerAttribution() DoOption {
+	return func(c *doConfig) {
+		c.attribute = true
+	}
+}
+
+// CallSiteStats returns the statistics of every call site counted by
+// [WithCallerAttribution], ordered by the number of attempts, most first.
+func CallSiteStats() []CallSiteStat {
+	callSites.lock.Lock()
+	stats := make([]CallSiteStat, 0, len(callSites.sites))
+	for _, s := range callSites.sites {
+		stats = append(stats, CallSiteStat{
+			File:        s.file,
+			Line:        s.line,
+			Function:    s.function,
+			Attempts:    s.attempts.Load(),
+			Exhaustions: s.exhaustions.Load(),
+		})
+	}
+	callSites.lock.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Attempts != stats[j].Attempts {
+			return stats[i].Attempts > stats[j].Attempts
+		}
+		if stats[i].File != stats[j].File {
+			return stats[i].File < stats[j].File
+		}
+		return stats[i].Line < stats[j].Line
+	})
+	return stats
+}
+
+type callSiteKey struct {
+	file string
+	line int
+}
+
+type callSite struct {
+	file     string
+	line     int
+	function string
+
+	attempts    atomic.Uint64
+	exhaustions atomic.Uint64
+}
+
+var callSites struct {
+	lock  sync.Mutex
+	sites map[callSiteKey]*callSite
+}
+
+// lookupCallSite returns the call site of the first caller outside of this
+// package, after skipping the first skip frames, starting with lookupCallSite
+// itself. It returns nil if there is no such caller or too many call sites are
+// already tracked.
+func lookupCallSite(skip int) *callSite {
+	var pcs [16]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" &&
+			!strings.HasPrefix(frame.Function, retryPackagePrefix) &&
+			!strings.HasPrefix(frame.Function, "runtime.") {
+			return trackCallSite(frame)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+func trackCallSite(frame runtime.Frame) *callSite {
+	key := callSiteKey{file: frame.File, line: frame.Line}
+
+	callSites.lock.Lock()
+	defer callSites.lock.Unlock()
+
+	if s, ok := callSites.sites[key]; ok {
+		return s
+	}
+	if len(callSites.sites) >= maxCallSites {
+		return nil
+	}
+	if callSites.sites == nil {
+		callSites.sites = make(map[callSiteKey]*callSite)
+	}
+
+	s := &callSite{
+		file:     frame.File,
+		line:     frame.Line,
+		function: frame.Function,
+	}
+	callSites.sites[key] = s
+	return s
+}
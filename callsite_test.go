@@ -0,0 +1,85 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func attributedExhausts(ctx context.Context) error {
+	b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+	return retry.Do(ctx, b, func(_ context.Context) error {
+		return retry.RetryableError(errors.New("oops"))
+	}, retry.WithCallerAttribution())
+}
+
+func attributedSucceeds(ctx context.Context) error {
+	var calls int
+	b := retry.NewConstant(1 * time.Nanosecond)
+	return retry.DoWithPredicate(ctx, b, func(error) bool { return true }, func(_ context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("oops")
+		}
+		return nil
+	}, retry.WithCallerAttribution())
+}
+
+func TestWithCallerAttribution(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := attributedExhausts(ctx); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := attributedSucceeds(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Calls without the option are not attributed.
+	if err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := make(map[string]retry.CallSiteStat)
+	for _, s := range retry.CallSiteStats() {
+		if !strings.HasSuffix(s.File, "callsite_test.go") {
+			continue
+		}
+		name := s.Function[strings.LastIndex(s.Function, ".")+1:]
+		if _, ok := stats[name]; ok {
+			t.Errorf("expected a single call site in %v", name)
+		}
+		stats[name] = s
+	}
+	if got, want := len(stats), 2; got != want {
+		t.Fatalf("expected %v to be %v: %v", got, want, stats)
+	}
+
+	exhausts := stats["attributedExhausts"]
+	if got, want := exhausts.Attempts, uint64(6); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := exhausts.Exhaustions, uint64(2); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if exhausts.Line == 0 {
+		t.Errorf("expected a line number")
+	}
+
+	succeeds := stats["attributedSucceeds"]
+	if got, want := succeeds.Attempts, uint64(4); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := succeeds.Exhaustions, uint64(0); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
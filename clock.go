@@ -0,0 +1,40 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time used by [Do] and [DoValue] to measure attempts
+// and to sleep between them. It exists so tests can control time; most callers
+// never need to provide one.
+type Clock interface {
+	// Now returns the current time. Durations are computed by subtracting two
+	// readings, so implementations should include a monotonic reading like
+	// [time.Now] does.
+	Now() time.Time
+
+	// Sleep pauses for d, returning early with a non-nil error if ctx is done
+	// first.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// realClock is the default clock, backed by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	return sleep(ctx, d)
+}
+
+// WithClock sets the clock used to measure attempts and sleep between them. To
+// keep time-based backoffs consistent with the measurements, pass the same
+// clock's Now method to them with [WithNowFunc].
+func WithClock(c Clock) DoOption {
+	return func(cfg *doConfig) {
+		cfg.clock = c
+	}
+}
@@ -0,0 +1,118 @@
+package retry
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// CoalescedTimers causes [Do] and [DoValue] to sleep on timers shared with every
+// other retry loop using the same resolution, instead of creating a timer for
+// each sleep. Each wake-up time is rounded up to the next multiple of
+// resolution, so all loops waking within the same window share a single
+// runtime timer.
+//
+// This trades precision for far fewer timers when very many loops are sleeping
+// at once: a sleep is never shorter than requested, but may be up to resolution
+// longer. Cancellation is not delayed. A resolution less than or equal to zero
+// disables coalescing.
+//
+// CoalescedTimers replaces any clock set with [WithClock].
+func CoalescedTimers(resolution time.Duration) DoOption {
+	if resolution <= 0 {
+		return func(c *doConfig) {
+			c.clock = realClock{}
+		}
+	}
+
+	w := wheelFor(resolution)
+	return func(c *doConfig) {
+		c.clock = w
+	}
+}
+
+// wheels holds the shared *timerWheel for each resolution.
+var wheels sync.Map
+
+// wheelFor returns the shared timer wheel for resolution.
+func wheelFor(resolution time.Duration) *timerWheel {
+	if w, ok := wheels.Load(resolution); ok {
+		return w.(*timerWheel)
+	}
+	w, _ := wheels.LoadOrStore(resolution, &timerWheel{
+		resolution: resolution,
+		base:       time.Now(),
+		buckets:    make(map[int64]chan struct{}),
+	})
+	return w.(*timerWheel)
+}
+
+// timerWheel is a [Clock] that groups sleeps into buckets of the resolution,
+// with one runtime timer per bucket. Buckets are removed when they fire.
+type timerWheel struct {
+	resolution time.Duration
+
+	// base is the origin of the bucket numbers. Wake-up times are measured
+	// from it with its monotonic clock reading, so changes to the wall clock
+	// do not move them.
+	base time.Time
+
+	lock    sync.Mutex
+	buckets map[int64]chan struct{}
+
+	// timers is the number of runtime timers created, for benchmarks.
+	timers int64
+}
+
+// Now implements Clock.
+func (w *timerWheel) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements Clock.
+func (w *timerWheel) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	res := int64(w.resolution)
+	now := int64(time.Since(w.base))
+
+	// Bucket numbers are computed from the wake-up time relative to base; fall
+	// back to a dedicated timer if that would overflow.
+	if int64(d) > math.MaxInt64-now-res {
+		return sleep(ctx, d)
+	}
+	tick := (now + int64(d) + res - 1) / res
+
+	select {
+	case <-w.bucket(tick):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// bucket returns the channel closed when the bucket numbered tick fires,
+// creating it and its timer if needed.
+func (w *timerWheel) bucket(tick int64) <-chan struct{} {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if ch, ok := w.buckets[tick]; ok {
+		return ch
+	}
+
+	ch := make(chan struct{})
+	w.buckets[tick] = ch
+	w.timers++
+
+	time.AfterFunc(time.Duration(tick)*w.resolution-time.Since(w.base), func() {
+		w.lock.Lock()
+		delete(w.buckets, tick)
+		w.lock.Unlock()
+		close(ch)
+	})
+	return ch
+}
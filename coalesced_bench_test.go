@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkSleepingLoops runs 100k concurrent retry loops that each sleep once,
+// comparing a timer per sleep with coalesced timers. It reports the mean
+// lateness of wake-ups and, for coalesced timers, the number of runtime timers
+// created.
+func BenchmarkSleepingLoops(b *testing.B) {
+	const loops = 100_000
+	const maxDelay = 500 * time.Millisecond
+
+	errRetry := RetryableError(errors.New("retry"))
+
+	run := func(b *testing.B, opts []DoOption, timers func() int64) {
+		b.ReportAllocs()
+
+		var late atomic.Int64
+		var before int64
+		if timers != nil {
+			before = timers()
+		}
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			for j := 0; j < loops; j++ {
+				delay := time.Duration(j) * maxDelay / loops
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					var last time.Time
+					_ = Do(context.Background(), WithMaxRetries(1, NewConstant(delay+1)), func(_ context.Context) error {
+						now := time.Now()
+						if last.IsZero() {
+							last = now
+							return errRetry
+						}
+						late.Add(int64(now.Sub(last) - delay))
+						return nil
+					}, opts...)
+				}()
+			}
+			wg.Wait()
+		}
+
+		if timers != nil {
+			b.ReportMetric(float64(timers()-before)/float64(b.N), "timers/op")
+		}
+		b.ReportMetric(float64(late.Load())/float64(b.N*loops), "late-ns/loop")
+	}
+
+	b.Run("timer_per_sleep", func(b *testing.B) {
+		run(b, nil, nil)
+	})
+
+	b.Run("coalesced_100ms", func(b *testing.B) {
+		w := wheelFor(100 * time.Millisecond)
+		run(b, []DoOption{CoalescedTimers(100 * time.Millisecond)}, func() int64 {
+			w.lock.Lock()
+			defer w.lock.Unlock()
+			return w.timers
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestCoalescedTimers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("never_early", func(t *testing.T) {
+		t.Parallel()
+
+		const delay = 30 * time.Millisecond
+		const resolution = 20 * time.Millisecond
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var last time.Time
+				if err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(delay)), func(_ context.Context) error {
+					now := time.Now()
+					if !last.IsZero() {
+						if slept := now.Sub(last); slept < delay {
+							t.Errorf("expected sleep %v to be at least %v", slept, delay)
+						}
+						return nil
+					}
+					last = now
+					return retry.RetryableError(fmt.Errorf("oops"))
+				}, retry.CoalescedTimers(resolution)); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.CoalescedTimers(1*time.Minute))
+
+		if got, want := err, context.DeadlineExceeded; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected cancellation to be prompt, took %v", elapsed)
+		}
+	})
+}
+
+func ExampleCoalescedTimers() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(5, retry.NewExponential(1*time.Second))
+
+	// Wake-ups are rounded up to the next 100ms, so loops waking within the
+	// same window share one timer.
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}, retry.CoalescedTimers(100*time.Millisecond)); err != nil {
+		// handle error
+	}
+}
@@ -0,0 +1,138 @@
+package retry
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DefaultCompactLength is the maximum message length, in bytes, kept by
+// [CompactError].
+const DefaultCompactLength = 4 << 10
+
+// CompactedError replaces an error that was removed from a chain by an error
+// compactor, such as [CompactError]. It keeps the original error's type name and
+// message, truncated if needed, and wraps the compacted forms of the errors the
+// original wrapped, so [errors.Is] and [errors.As] still find the errors that
+// were kept.
+type CompactedError struct {
+	// Type is the type of the original error, as printed by the %T verb.
+	Type string
+
+	msg  string
+	errs []error
+}
+
+// Error returns the original error's message, truncated if needed.
+func (e *CompactedError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the compacted forms of the errors the original error wrapped.
+func (e *CompactedError) Unwrap() []error {
+	return e.errs
+}
+
+// CompactError is an error compactor for [WithErrorCompaction] that truncates
+// messages to [DefaultCompactLength] bytes. See [NewErrorCompactor].
+func CompactError(err error) error {
+	c, _ := compactError(err, DefaultCompactLength)
+	return c
+}
+
+// NewErrorCompactor returns an error compactor for [WithErrorCompaction] that
+// bounds the memory retained by an error chain.
+//
+// Errors in the chain that implement Body() []byte, such as errors holding an
+// HTTP response body, and errors whose message is longer than maxLen bytes are
+// replaced with a [CompactedError]. So is every error that wraps a replaced
+// error, since it retains it, except errors returned by [RetryableError], which
+// are rebuilt around the compacted error. Errors that wrap nothing that was
+// replaced are kept as is. It panics if maxLen is negative.
+func NewErrorCompactor(maxLen int) func(err error) error {
+	return must(NewErrorCompactorE(maxLen))
+}
+
+// NewErrorCompactorE is like [NewErrorCompactor], but returns an error instead
+// of panicking if maxLen is negative.
+func NewErrorCompactorE(maxLen int) (func(err error) error, error) {
+	if maxLen < 0 {
+		return nil, &ValidationError{Field: "maxLen", Reason: "must not be negative"}
+	}
+	return func(err error) error {
+		c, _ := compactError(err, maxLen)
+		return c
+	}, nil
+}
+
+// bodyError is implemented by errors that retain a payload, such as the body of
+// an HTTP response.
+type bodyError interface {
+	Body() []byte
+}
+
+// compactError returns err with every error that retains a large message or
+// body, and every error that wraps one, replaced, and whether anything was
+// replaced. Errors are never compared with each other, since an error in the
+// chain may have an incomparable type.
+func compactError(err error, maxLen int) (error, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	if rerr, ok := err.(*retryableError); ok {
+		inner, changed := compactError(rerr.err, maxLen)
+		if !changed {
+			return rerr, false
+		}
+		return &retryableError{err: inner, delay: rerr.delay, hasDelay: rerr.hasDelay}, true
+	}
+
+	var wrapped []error
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			wrapped = []error{e}
+		}
+	case interface{ Unwrap() []error }:
+		wrapped = x.Unwrap()
+	}
+
+	changed := false
+	compacted := make([]error, 0, len(wrapped))
+	for _, e := range wrapped {
+		c, ok := compactError(e, maxLen)
+		if ok {
+			changed = true
+		}
+		if c != nil {
+			compacted = append(compacted, c)
+		}
+	}
+
+	_, hasBody := err.(bodyError)
+	msg := err.Error()
+	if !changed && !hasBody && len(msg) <= maxLen {
+		return err, false
+	}
+
+	return &CompactedError{
+		Type: fmt.Sprintf("%T", err),
+		msg:  truncateMessage(msg, maxLen),
+		errs: compacted,
+	}, true
+}
+
+// truncateMessage truncates msg to at most maxLen bytes, on a rune boundary,
+// and records how many bytes were removed.
+func truncateMessage(msg string, maxLen int) string {
+	if len(msg) <= maxLen {
+		return msg
+	}
+
+	n := maxLen
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + "... (" + strconv.Itoa(len(msg)-n) + " bytes truncated)"
+}
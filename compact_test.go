@@ -0,0 +1,211 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// bigMessage is a synthetic 10MB error message.
+var bigMessage = strings.Repeat("x", 10<<20)
+
+type bodyError struct {
+	status int
+	body   []byte
+}
+
+func (e *bodyError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func (e *bodyError) Body() []byte {
+	return e.body
+}
+
+func TestCompactError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retry.CompactError(nil); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+
+	t.Run("small", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("read: %w", io.EOF)
+		if got := retry.CompactError(err); got != err {
+			t.Errorf("expected %v to be unchanged", got)
+		}
+	})
+
+	t.Run("large_message", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.RetryableError(fmt.Errorf("%s: %w", bigMessage, io.EOF))
+		got := retry.CompactError(err)
+
+		if n, max := len(got.Error()), retry.DefaultCompactLength+64; n > max {
+			t.Errorf("expected %d to be at most %d", n, max)
+		}
+		if !strings.HasSuffix(got.Error(), "bytes truncated)") {
+			t.Errorf("expected %q to record truncation", got.Error()[len(got.Error())-32:])
+		}
+		if !errors.Is(got, io.EOF) {
+			t.Errorf("expected %v to be %v", got, io.EOF)
+		}
+
+		var cerr *retry.CompactedError
+		if !errors.As(got, &cerr) {
+			t.Fatalf("expected %T to contain a compacted error", got)
+		}
+		if got, want := cerr.Type, "*fmt.wrapError"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+
+		// The retryable marker survives compaction.
+		if err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return got
+		}); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		t.Parallel()
+
+		err := fmt.Errorf("upstream: %w", &bodyError{status: 503, body: []byte(bigMessage)})
+		got := retry.CompactError(err)
+
+		if got, want := got.Error(), "upstream: status 503"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		var berr *bodyError
+		if errors.As(got, &berr) {
+			t.Errorf("expected body error to be dropped")
+		}
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		t.Parallel()
+
+		err := errors.Join(io.EOF, errors.New(bigMessage), io.ErrUnexpectedEOF)
+		got := retry.NewErrorCompactor(16)(err)
+
+		if n, max := len(got.Error()), 64; n > max {
+			t.Errorf("expected %d to be at most %d", n, max)
+		}
+		if !errors.Is(got, io.EOF) || !errors.Is(got, io.ErrUnexpectedEOF) {
+			t.Errorf("expected %v to contain %v and %v", got, io.EOF, io.ErrUnexpectedEOF)
+		}
+	})
+
+	t.Run("incomparable", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.RetryableError(fmt.Errorf("wrap: %w", sliceError{msgs: []string{"a", "b"}}))
+		if got, want := retry.CompactError(err).Error(), "retryable: wrap: [a b]"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+
+		got := retry.NewErrorCompactor(4)(fmt.Errorf("wrap: %w", sliceError{msgs: []string{"a", "b"}}))
+		var serr sliceError
+		if errors.As(got, &serr) {
+			t.Errorf("expected %v to be compacted", got)
+		}
+	})
+
+	t.Run("utf8", func(t *testing.T) {
+		t.Parallel()
+
+		got := retry.NewErrorCompactor(2)(errors.New("héllo")).Error()
+		if got, want := got, "h... (5 bytes truncated)"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+}
+
+func TestWithErrorCompaction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first_success", func(t *testing.T) {
+		t.Parallel()
+
+		fs := make([]retry.RetryFuncValue[int], 4)
+		for i := range fs {
+			fs[i] = func(_ context.Context) (int, error) {
+				return 0, retry.RetryableError(&bodyError{status: 500, body: []byte(bigMessage)})
+			}
+		}
+
+		_, _, err := retry.FirstSuccess(context.Background(), fs, func() retry.Backoff {
+			return retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+		}, retry.WithErrorCompaction(retry.CompactError))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+
+		var berr *bodyError
+		if errors.As(err, &berr) {
+			t.Errorf("expected body errors to be dropped")
+		}
+		if got, want := strings.Count(err.Error(), "status 500"), len(fs); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		cache := retry.NewNegativeCache(time.Minute, 10)
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		err := retry.DoCached(context.Background(), cache, "key", b, func(_ context.Context) error {
+			return errors.New(bigMessage)
+		}, retry.WithErrorCompaction(retry.CompactError))
+
+		// The caller receives the original error; the cache retains the
+		// compacted one.
+		if got, want := len(err.Error()), len(bigMessage); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		cached := cache.Get("key")
+		if n, max := len(cached.Error()), retry.DefaultCompactLength+64; n > max {
+			t.Errorf("expected %d to be at most %d", n, max)
+		}
+	})
+
+	t.Run("incomparable_history", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.Do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(fmt.Errorf("wrap: %w", sliceError{msgs: []string{"a"}}))
+		}, retry.WithErrorHistory(3), retry.WithErrorCompaction(retry.CompactError))
+		if got, want := len(retry.AttemptErrors(err)), 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithErrorCompaction() {
+	ctx := context.Background()
+
+	cache := retry.NewNegativeCache(5*time.Minute, 1000)
+	b := retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond))
+
+	if err := retry.DoCached(ctx, cache, "https://example.com", b, func(ctx context.Context) error {
+		// TODO: fetch, returning errors that may include the response body
+		return nil
+	}, retry.WithErrorCompaction(retry.CompactError)); err != nil {
+		// handle error
+	}
+}
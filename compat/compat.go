@@ -0,0 +1,106 @@
+// Package compat provides the signatures of earlier releases of
+// github.com/sethvargo/go-retry, implemented on the current package, so that
+// call sites can be migrated incrementally after upgrading.
+//
+// Every function is deprecated in favor of its replacement in the retry
+// package, and [Mappings] lists them all. Behavior that differs from the
+// replacement is documented on each function.
+package compat
+
+//go:generate go run gen_mappings.go
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// NewConstant creates a new constant backoff using the value t. It returns an
+// error instead of panicking if t is less than or equal to zero.
+//
+// Deprecated: Use [retry.NewConstantE].
+func NewConstant(t time.Duration) (retry.Backoff, error) {
+	return retry.NewConstantE(t)
+}
+
+// NewExponential creates a new exponential backoff using the starting value of
+// base. It returns an error instead of panicking if base is less than or equal
+// to zero.
+//
+// Deprecated: Use [retry.NewExponentialE].
+func NewExponential(base time.Duration) (retry.Backoff, error) {
+	return retry.NewExponentialE(base)
+}
+
+// NewFibonacci creates a new Fibonacci backoff using the starting value of
+// base. It returns an error instead of panicking if base is less than or equal
+// to zero.
+//
+// Deprecated: Use [retry.NewFibonacciE].
+func NewFibonacci(base time.Duration) (retry.Backoff, error) {
+	return retry.NewFibonacciE(base)
+}
+
+// Constant is a wrapper around [Do] that uses a constant backoff. It returns an
+// error instead of panicking if t is less than or equal to zero.
+//
+// Deprecated: Use [retry.Constant].
+func Constant(ctx context.Context, t time.Duration, f retry.RetryFunc) error {
+	b, err := NewConstant(t)
+	if err != nil {
+		return err
+	}
+	return Do(ctx, b, f)
+}
+
+// Exponential is a wrapper around [Do] that uses an exponential backoff. It
+// returns an error instead of panicking if base is less than or equal to zero.
+//
+// Deprecated: Use [retry.Exponential].
+func Exponential(ctx context.Context, base time.Duration, f retry.RetryFunc) error {
+	b, err := NewExponential(base)
+	if err != nil {
+		return err
+	}
+	return Do(ctx, b, f)
+}
+
+// Fibonacci is a wrapper around [Do] that uses a Fibonacci backoff. It returns
+// an error instead of panicking if base is less than or equal to zero.
+//
+// Deprecated: Use [retry.Fibonacci].
+func Fibonacci(ctx context.Context, base time.Duration, f retry.RetryFunc) error {
+	b, err := NewFibonacci(base)
+	if err != nil {
+		return err
+	}
+	return Do(ctx, b, f)
+}
+
+// Do wraps a function with a backoff to retry, like [retry.Do], with the error
+// semantics of earlier releases:
+//
+//   - When retrying stops, the error from the final attempt is returned as is,
+//     so it can be compared with ==. It does not match [retry.ErrExhausted].
+//   - When ctx is done, ctx.Err() is returned, without the cause the context
+//     was canceled with or the error from the last attempt.
+//
+// Deprecated: Use [retry.Do].
+func Do(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+	var stopped bool
+	err := retry.Do(ctx, b, f, retry.WithStopHook(func(retry.StopReason, error) {
+		stopped = true
+	}))
+	if stopped {
+		return errors.Unwrap(err)
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil && err != ctxErr && errors.Is(err, ctxErr) {
+		if cause := context.Cause(ctx); errors.Is(err, cause) {
+			return ctxErr
+		}
+	}
+	return err
+}
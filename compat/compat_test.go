@@ -0,0 +1,267 @@
+package compat_test
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/compat"
+)
+
+func TestConstructors(t *testing.T) {
+	t.Parallel()
+
+	constructors := map[string]func(time.Duration) (retry.Backoff, error){
+		"constant":    compat.NewConstant,
+		"exponential": compat.NewExponential,
+		"fibonacci":   compat.NewFibonacci,
+	}
+	current := map[string]func(time.Duration) retry.Backoff{
+		"constant":    retry.NewConstant,
+		"exponential": retry.NewExponential,
+		"fibonacci":   retry.NewFibonacci,
+	}
+
+	for name, fn := range constructors {
+		name, fn := name, fn
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Invalid arguments return an error instead of panicking.
+			for _, base := range []time.Duration{0, -1} {
+				b, err := fn(base)
+				if err == nil {
+					t.Errorf("%v: expected error", base)
+				}
+				if b != nil {
+					t.Errorf("%v: expected nil backoff, got %v", base, b)
+				}
+			}
+
+			b, err := fn(1 * time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := current[name](1 * time.Second)
+			for i := 0; i < 5; i++ {
+				gotVal, gotStop := b.Next()
+				wantVal, wantStop := want.Next()
+				if gotVal != wantVal || gotStop != wantStop {
+					t.Errorf("%d: expected (%v, %v) to be (%v, %v)", i, gotVal, gotStop, wantVal, wantStop)
+				}
+			}
+		})
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	t.Parallel()
+
+	helpers := map[string]func(context.Context, time.Duration, retry.RetryFunc) error{
+		"constant":    compat.Constant,
+		"exponential": compat.Exponential,
+		"fibonacci":   compat.Fibonacci,
+	}
+
+	for name, fn := range helpers {
+		name, fn := name, fn
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			err := fn(context.Background(), 0, func(_ context.Context) error {
+				calls++
+				return nil
+			})
+			var verr *retry.ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+			if got, want := calls, 0; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			err = fn(context.Background(), 1*time.Nanosecond, func(_ context.Context) error {
+				calls++
+				if calls < 3 {
+					return retry.RetryableError(io.EOF)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := calls, 3; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+		err := compat.Do(context.Background(), b, func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		if got, want := err, io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if errors.Is(err, retry.ErrExhausted) {
+			t.Errorf("expected %v not to match %v", err, retry.ErrExhausted)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		t.Parallel()
+
+		// A permanent error that itself matches ErrExhausted, such as from a
+		// nested call, is returned as is.
+		inner := retry.Do(context.Background(), retry.WithMaxRetries(0, retry.NewConstant(1)), func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		})
+		err := compat.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return inner
+		})
+		if got, want := err, inner; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+
+	t.Run("canceled_after_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := compat.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			cancel()
+			return retry.RetryableError(errors.New("oops"))
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+
+	t.Run("canceled_with_cause", func(t *testing.T) {
+		t.Parallel()
+
+		errShutdown := errors.New("shutdown")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errShutdown)
+
+		err := compat.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+
+		// The current package includes the cause.
+		err = retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		})
+		if !errors.Is(err, errShutdown) {
+			t.Errorf("expected %v to match %v", err, errShutdown)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		err := compat.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			if calls < 2 {
+				return retry.RetryableError(io.EOF)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// TestMappings verifies that mappings.go is up to date with the deprecation
+// notices in this package, and that every replacement exists.
+func TestMappings(t *testing.T) {
+	t.Parallel()
+
+	deprecatedRe := regexp.MustCompile(`(?m)^Deprecated: Use \[(retry\.\w+)\]\.$`)
+
+	shims := exportedFuncs(t, ".", func(name string) bool {
+		return !strings.HasSuffix(name, "_test.go") && name != "mappings.go" && name != "gen_mappings.go"
+	})
+	var want []compat.Mapping
+	for name, fn := range shims {
+		m := deprecatedRe.FindStringSubmatch(fn.Doc.Text())
+		if m == nil {
+			t.Errorf("%s has no deprecation notice naming its replacement", name)
+			continue
+		}
+		want = append(want, compat.Mapping{Old: "compat." + name, New: m[1]})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		return want[i].Old < want[j].Old
+	})
+
+	if got := compat.Mappings; !reflect.DeepEqual(got, want) {
+		t.Errorf("mappings.go is out of date, run go generate:\n\n%v\n\nwant\n\n%v", got, want)
+	}
+
+	current := exportedFuncs(t, "..", func(name string) bool {
+		return !strings.HasSuffix(name, "_test.go")
+	})
+	for _, m := range compat.Mappings {
+		if _, ok := current[strings.TrimPrefix(m.New, "retry.")]; !ok {
+			t.Errorf("%s: replacement %s does not exist", m.Old, m.New)
+		}
+	}
+}
+
+// exportedFuncs returns the exported functions declared in the files in dir
+// for which include returns true, by name.
+func exportedFuncs(t *testing.T, dir string, include func(name string) bool) map[string]*ast.FuncDecl {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi fs.FileInfo) bool {
+		return include(fi.Name())
+	}, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.IsExported() {
+					funcs[fn.Name.Name] = fn
+				}
+			}
+		}
+	}
+	if len(funcs) == 0 {
+		t.Fatalf("no functions in %s", dir)
+	}
+	return funcs
+}
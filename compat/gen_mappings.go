@@ -0,0 +1,71 @@
+//go:build ignore
+
+// This program generates mappings.go from the deprecation notices of the
+// functions in this package. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// deprecatedRe matches the replacement named by a deprecation notice.
+var deprecatedRe = regexp.MustCompile(`(?m)^Deprecated: Use \[(retry\.\w+)\]\.$`)
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && name != "mappings.go" && name != "gen_mappings.go"
+	}, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var lines []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv != nil || !fn.Name.IsExported() || fn.Doc == nil {
+					continue
+				}
+				m := deprecatedRe.FindStringSubmatch(fn.Doc.Text())
+				if m == nil {
+					log.Fatalf("%s has no deprecation notice naming its replacement", fn.Name.Name)
+				}
+				lines = append(lines, fmt.Sprintf("{Old: %q, New: %q},", "compat."+fn.Name.Name, m[1]))
+			}
+		}
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_mappings.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package compat\n\n")
+	buf.WriteString("// Mappings lists the replacement in the retry package of every function in\n")
+	buf.WriteString("// this package, sorted by the old name.\n")
+	buf.WriteString("var Mappings = []Mapping{\n")
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("mappings.go", src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
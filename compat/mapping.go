@@ -0,0 +1,13 @@
+package compat
+
+// Mapping names a function in this package and its replacement in the retry
+// package.
+type Mapping struct {
+	// Old is the qualified name of the function in this package, such as
+	// "compat.NewConstant".
+	Old string
+
+	// New is the qualified name of its replacement, such as
+	// "retry.NewConstantE".
+	New string
+}
@@ -0,0 +1,15 @@
+// Code generated by gen_mappings.go; DO NOT EDIT.
+
+package compat
+
+// Mappings lists the replacement in the retry package of every function in
+// this package, sorted by the old name.
+var Mappings = []Mapping{
+	{Old: "compat.Constant", New: "retry.Constant"},
+	{Old: "compat.Do", New: "retry.Do"},
+	{Old: "compat.Exponential", New: "retry.Exponential"},
+	{Old: "compat.Fibonacci", New: "retry.Fibonacci"},
+	{Old: "compat.NewConstant", New: "retry.NewConstantE"},
+	{Old: "compat.NewExponential", New: "retry.NewExponentialE"},
+	{Old: "compat.NewFibonacci", New: "retry.NewFibonacciE"},
+}
@@ -0,0 +1,237 @@
+package retry_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// testConcurrentSafety calls Next on the backoff returned by fn from many
+// goroutines at once. It is intended to be run with -race to detect
+// unsynchronized access to internal state.
+func testConcurrentSafety(tb testing.TB, fn func() retry.Backoff) {
+	tb.Helper()
+
+	const goroutines = 64
+	calls := 10_000
+	if testing.Short() {
+		calls = 1_000
+	}
+
+	b := fn()
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				val, stop := b.Next()
+				if !stop && val < 0 {
+					tb.Errorf("expected %v to be non-negative", val)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentSafety(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		fn   func() retry.Backoff
+	}{
+		{
+			name: "constant",
+			fn: func() retry.Backoff {
+				return retry.NewConstant(1 * time.Second)
+			},
+		},
+		{
+			name: "linear",
+			fn: func() retry.Backoff {
+				return retry.NewLinear(1 * time.Second)
+			},
+		},
+		{
+			name: "exponential",
+			fn: func() retry.Backoff {
+				return retry.NewExponential(1 * time.Second)
+			},
+		},
+		{
+			name: "exponential_factor",
+			fn: func() retry.Backoff {
+				return retry.NewExponentialWithFactor(1*time.Second, 1.5)
+			},
+		},
+		{
+			name: "decorrelated_jitter",
+			fn: func() retry.Backoff {
+				return retry.NewDecorrelatedJitter(1*time.Second, 1*time.Minute)
+			},
+		},
+		{
+			name: "fibonacci",
+			fn: func() retry.Backoff {
+				return retry.NewFibonacci(1 * time.Second)
+			},
+		},
+		{
+			name: "fibonacci_capped",
+			fn: func() retry.Backoff {
+				return retry.NewFibonacciCapped(1*time.Millisecond, 1*time.Second)
+			},
+		},
+		{
+			name: "schedule",
+			fn: func() retry.Backoff {
+				return retry.NewScheduleRepeatLast(1*time.Second, 2*time.Second, 3*time.Second)
+			},
+		},
+		{
+			name: "jitter",
+			fn: func() retry.Backoff {
+				return retry.WithJitter(250*time.Millisecond, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "jitter_percent",
+			fn: func() retry.Backoff {
+				return retry.WithJitterPercent(5, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "full_jitter",
+			fn: func() retry.Backoff {
+				return retry.WithFullJitter(retry.NewConstant(1 * time.Second))
+			},
+		},
+		{
+			name: "equal_jitter",
+			fn: func() retry.Backoff {
+				return retry.WithEqualJitter(retry.NewConstant(1 * time.Second))
+			},
+		},
+		{
+			name: "max_retries",
+			fn: func() retry.Backoff {
+				return retry.WithMaxRetries(100, retry.NewFibonacci(1*time.Second))
+			},
+		},
+		{
+			name: "capped_duration",
+			fn: func() retry.Backoff {
+				return retry.WithCappedDuration(5*time.Second, retry.NewExponential(1*time.Second))
+			},
+		},
+		{
+			name: "min_duration",
+			fn: func() retry.Backoff {
+				return retry.WithMinDuration(1*time.Second, retry.WithJitter(500*time.Millisecond, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "max_duration",
+			fn: func() retry.Backoff {
+				return retry.WithMaxDuration(5*time.Second, retry.NewExponential(1*time.Second),
+					retry.WithNowFunc(newFakeClock().Now))
+			},
+		},
+		{
+			name: "max_sleep",
+			fn: func() retry.Backoff {
+				return retry.WithMaxSleep(time.Hour, retry.NewConstant(1*time.Second), retry.WithTruncateFinalSleep())
+			},
+		},
+		{
+			name: "quantized_delay",
+			fn: func() retry.Backoff {
+				return retry.WithQuantizedDelay(1*time.Second, retry.RoundNearest, retry.NewFibonacci(1*time.Millisecond))
+			},
+		},
+		{
+			name: "error_budgets",
+			fn: func() retry.Backoff {
+				b := retry.WithErrorBudgets(map[string]uint64{"": 100}, func(error) string {
+					return ""
+				}, retry.NewConstant(1*time.Second))
+				eb := b.(retry.ErrorBackoff)
+				return retry.BackoffFunc(func() (time.Duration, bool) {
+					return eb.NextError(nil)
+				})
+			},
+		},
+		{
+			name: "adaptive_cutoff",
+			fn: func() retry.Backoff {
+				return retry.WithAdaptiveCutoff(retry.NewSuccessModel(0.1), 0.05, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "leader_only",
+			fn: func() retry.Backoff {
+				return retry.WithLeaderOnly(retry.NewLocalLocker(), retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "attempt",
+			fn: func() retry.Backoff {
+				return retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+					return time.Duration(retry) * time.Millisecond, false
+				})
+			},
+		},
+		{
+			name: "lease",
+			fn: func() retry.Backoff {
+				return retry.WithLease(func() time.Duration { return time.Hour }, 1*time.Second, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "startup_splay",
+			fn: func() retry.Backoff {
+				return retry.WithStartupSplay(1*time.Second, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "failure_threshold",
+			fn: func() retry.Backoff {
+				return retry.WithFailureThreshold(3, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "retry_budget",
+			fn: func() retry.Backoff {
+				return retry.NewBudget(0.1, 1e6).Wrap(retry.NewConstant(1 * time.Second))
+			},
+		},
+		{
+			name: "auto_reset",
+			fn: func() retry.Backoff {
+				return retry.WithAutoReset(1*time.Millisecond, retry.NewScheduleRepeatLast(1*time.Second, 2*time.Second))
+			},
+		},
+		{
+			name: "calendar",
+			fn: func() retry.Backoff {
+				return retry.NewCalendarBackoff([]retry.CalendarStep{{Days: 1}, {Days: 7}})
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			testConcurrentSafety(t, tc.fn)
+		})
+	}
+}
@@ -0,0 +1,659 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// entryPoint adapts a public retry function to the signature of Do.
+type entryPoint func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error
+
+var entryPoints = []struct {
+	name string
+	do   entryPoint
+}{
+	{
+		name: "Do",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.Do(ctx, b, f)
+		},
+	},
+	{
+		name: "DoValue",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, err := retry.DoValue(ctx, b, func(ctx context.Context) (int, error) {
+				return 1, f(ctx)
+			})
+			return err
+		},
+	},
+	{
+		name: "DoWithFactory",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoWithFactory(ctx, func() retry.Backoff { return b }, f)
+		},
+	},
+	{
+		name: "DoWithPredicate",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoWithPredicate(ctx, b, func(error) bool { return false }, f)
+		},
+	},
+	{
+		name: "DoWithTimeout",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoWithTimeout(ctx, b, time.Hour, f)
+		},
+	},
+	{
+		name: "DoWithReport",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, err := retry.DoWithReport(ctx, b, f)
+			return err
+		},
+	},
+	{
+		name: "DoWithChannel",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, wait := retry.DoWithChannel(ctx, b, f)
+			return wait()
+		},
+	},
+	{
+		name: "DoWithToken",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			tok := retry.NewToken()
+			if ctx.Err() != nil {
+				tok.Cancel()
+			}
+			defer context.AfterFunc(ctx, tok.Cancel)()
+			return retry.DoWithToken(tok, b, func() error {
+				return f(ctx)
+			})
+		},
+	},
+	{
+		name: "DoFile",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoFile(ctx, b, f)
+		},
+	},
+	{
+		name: "DoCached",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoCached(ctx, retry.NewNegativeCache(time.Minute, 1), "key", b, f)
+		},
+	},
+	{
+		name: "DoRedirect",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoRedirect(ctx, b, "a", func(ctx context.Context, _ string) (string, error) {
+				return "", f(ctx)
+			})
+		},
+	},
+	{
+		name: "Poll",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, err := retry.Poll(ctx, b, func(ctx context.Context) (int, error) {
+				return 1, f(ctx)
+			}, func(int) bool { return true })
+			return err
+		},
+	},
+	{
+		name: "FirstSuccess",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, _, err := retry.FirstSuccess(ctx, []retry.RetryFuncValue[int]{
+				func(ctx context.Context) (int, error) {
+					return 1, f(ctx)
+				},
+			}, func() retry.Backoff { return b })
+			return err
+		},
+	},
+	{
+		name: "DoValueWithPredicate",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, err := retry.DoValueWithPredicate(ctx, b, func(error) bool { return false }, func(ctx context.Context) (int, error) {
+				return 1, f(ctx)
+			})
+			return err
+		},
+	},
+	{
+		name: "RepeatWhile",
+		do: repeatEntryPoint(func(ctx context.Context, b retry.Backoff, f retry.RepeatFunc, while func(err error) bool) error {
+			return retry.RepeatWhile(ctx, b, f, while)
+		}),
+	},
+	{
+		name: "RepeatValue",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			// Any error ends RepeatValue, so the error from f is returned as the
+			// value and a nil error ends the loop instead.
+			last, err := retry.RepeatValue(ctx, b, func(ctx context.Context) (error, error) {
+				if err := f(ctx); err != nil {
+					return err, nil
+				}
+				return nil, errRepeatDone
+			})
+			switch {
+			case err == errRepeatDone:
+				return nil
+			case err == nil:
+				return last
+			default:
+				return err
+			}
+		},
+	},
+	{
+		name: "RepeatWithErrors",
+		do: repeatEntryPoint(func(ctx context.Context, b retry.Backoff, f retry.RepeatFunc, while func(err error) bool) error {
+			return retry.RepeatWithErrors(ctx, b, f, while)
+		}),
+	},
+	{
+		name: "DoWithHooks",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoWithHooks(ctx, b, f, retry.Hooks{})
+		},
+	},
+	{
+		name: "DoWithInitialDelay",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoWithInitialDelay(ctx, b, 1*time.Nanosecond, f)
+		},
+	},
+	{
+		name: "DoTiered",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			_, err := retry.DoTiered(ctx, []retry.Tier[int]{{
+				Backoff: b,
+				Func: func(ctx context.Context) (int, error) {
+					return 1, f(ctx)
+				},
+			}})
+			return err
+		},
+	},
+	{
+		name: "RepeatWithRecovery",
+		do: repeatEntryPoint(func(ctx context.Context, b retry.Backoff, f retry.RepeatFunc, while func(err error) bool) error {
+			return retry.RepeatWithRecovery(ctx, b, b, f, while)
+		}),
+	},
+	{
+		name: "DoPooled",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoPooled(ctx, retry.NewSleepPool(), b, f)
+		},
+	},
+	{
+		name: "DoAll",
+		do: func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+			return retry.DoAll(ctx, func() retry.Backoff { return b }, []int{1}, func(ctx context.Context, _ int) error {
+				return f(ctx)
+			})[0]
+		},
+	},
+}
+
+// errRepeatDone ends a repeat loop adapted by repeatEntryPoint.
+var errRepeatDone = errors.New("repeat done")
+
+// repeatEntryPoint adapts a repeat loop to the signature of Do. A repeat loop
+// continues after a nil error and returns nil when the backoff stops, so the
+// adapter ends the loop once f returns nil, and returns the last error from f
+// when the backoff stops.
+func repeatEntryPoint(repeat func(ctx context.Context, b retry.Backoff, f retry.RepeatFunc, while func(err error) bool) error) entryPoint {
+	return func(ctx context.Context, b retry.Backoff, f retry.RetryFunc) error {
+		var last error
+		err := repeat(ctx, b, func(ctx context.Context) error {
+			if last = f(ctx); last == nil {
+				return errRepeatDone
+			}
+			return last
+		}, func(err error) bool {
+			return err != errRepeatDone
+		})
+		switch {
+		case err == errRepeatDone:
+			return nil
+		case err == nil:
+			return last
+		default:
+			return err
+		}
+	}
+}
+
+// conformanceResult is the observable behavior of an entry point.
+type conformanceResult struct {
+	calls    int64
+	canceled bool
+	failed   bool
+	fast     bool
+}
+
+// TestConformance asserts that every public entry point shares the attempt,
+// sleep, and cancellation behavior of Do.
+func TestConformance(t *testing.T) {
+	t.Parallel()
+
+	errAttempt := errors.New("attempt failed")
+
+	cases := []struct {
+		name string
+		run  func(do entryPoint) conformanceResult
+		exp  conformanceResult
+	}{
+		{
+			name: "pre_canceled",
+			run: func(do entryPoint) conformanceResult {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				var calls atomic.Int64
+				err := do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+					calls.Add(1)
+					return nil
+				})
+				return conformanceResult{calls: calls.Load(), canceled: errors.Is(err, context.Canceled), fast: true}
+			},
+			exp: conformanceResult{calls: 0, canceled: true, fast: true},
+		},
+		{
+			name: "cancel_during_attempt",
+			run: func(do entryPoint) conformanceResult {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				var calls atomic.Int64
+				start := time.Now()
+				err := do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+					calls.Add(1)
+					cancel()
+					return retry.RetryableError(errAttempt)
+				})
+				return conformanceResult{calls: calls.Load(), canceled: errors.Is(err, context.Canceled), fast: time.Since(start) < 5*time.Second}
+			},
+			exp: conformanceResult{calls: 1, canceled: true, fast: true},
+		},
+		{
+			name: "cancel_during_sleep",
+			run: func(do entryPoint) conformanceResult {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				var calls atomic.Int64
+				start := time.Now()
+				err := do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+					calls.Add(1)
+					time.AfterFunc(10*time.Millisecond, cancel)
+					return retry.RetryableError(errAttempt)
+				})
+				return conformanceResult{calls: calls.Load(), canceled: errors.Is(err, context.Canceled), fast: time.Since(start) < 5*time.Second}
+			},
+			exp: conformanceResult{calls: 1, canceled: true, fast: true},
+		},
+		{
+			name: "backoff_stop",
+			run: func(do entryPoint) conformanceResult {
+				var calls atomic.Int64
+				start := time.Now()
+				err := do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Millisecond)), func(_ context.Context) error {
+					calls.Add(1)
+					return retry.RetryableError(errAttempt)
+				})
+				return conformanceResult{calls: calls.Load(), failed: errors.Is(err, errAttempt), fast: time.Since(start) < 5*time.Second}
+			},
+			exp: conformanceResult{calls: 3, failed: true, fast: true},
+		},
+		{
+			name: "zero_delay",
+			run: func(do entryPoint) conformanceResult {
+				zero := retry.BackoffFunc(func() (time.Duration, bool) {
+					return 0, false
+				})
+
+				var calls atomic.Int64
+				start := time.Now()
+				err := do(context.Background(), zero, func(_ context.Context) error {
+					if calls.Add(1) < 100 {
+						return retry.RetryableError(errAttempt)
+					}
+					return nil
+				})
+				return conformanceResult{calls: calls.Load(), failed: err != nil, fast: time.Since(start) < 5*time.Second}
+			},
+			exp: conformanceResult{calls: 100, failed: false, fast: true},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, ep := range entryPoints {
+				ep := ep
+
+				t.Run(ep.name, func(t *testing.T) {
+					t.Parallel()
+
+					if got, want := tc.run(ep.do), tc.exp; got != want {
+						t.Errorf("expected %+v to be %+v", got, want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// testStickyStop calls Next on b until it stops, and then asserts that every
+// later call also stops.
+func testStickyStop(tb testing.TB, b retry.Backoff, probe func()) {
+	tb.Helper()
+
+	const limit = 1_000
+	for i := 0; ; i++ {
+		if i == limit {
+			tb.Fatalf("expected backoff to stop within %d calls", limit)
+		}
+		if _, stop := b.Next(); stop {
+			break
+		}
+	}
+
+	if probe != nil {
+		probe()
+	}
+
+	for i := 0; i < 100; i++ {
+		if val, stop := b.Next(); !stop {
+			tb.Fatalf("call %d after stop: expected stop, got %v", i, val)
+		}
+	}
+}
+
+// TestStickyStop asserts that every backoff keeps stopping once it has
+// stopped.
+func TestStickyStop(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	// resuming stops on every other call, violating the contract, to show that
+	// stateful wrappers do not depend on the backoff they wrap being sticky.
+	resuming := func() retry.Backoff {
+		var calls atomic.Int64
+		return retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Second, calls.Add(1)%2 == 0
+		})
+	}
+
+	cases := []struct {
+		name string
+		b    func() retry.Backoff
+	}{
+		{
+			name: "max_retries",
+			b: func() retry.Backoff {
+				return retry.WithMaxRetries(3, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "max_retries_resuming",
+			b: func() retry.Backoff {
+				return retry.WithMaxRetries(10, resuming())
+			},
+		},
+		{
+			name: "max_duration_clock_backward",
+			b: func() retry.Backoff {
+				clock := newFakeClock()
+				b := retry.WithMaxDuration(5*time.Second, retry.BackoffFunc(func() (time.Duration, bool) {
+					clock.Advance(2 * time.Second)
+					return 1 * time.Second, false
+				}), retry.WithNowFunc(clock.Now))
+				return &probed{Backoff: b, probe: func() { clock.Advance(-time.Hour) }}
+			},
+		},
+		{
+			name: "max_duration_resuming",
+			b: func() retry.Backoff {
+				return retry.WithMaxDuration(time.Hour, resuming())
+			},
+		},
+		{
+			name: "jitter",
+			b: func() retry.Backoff {
+				return retry.WithJitter(1*time.Millisecond, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "jitter_percent",
+			b: func() retry.Backoff {
+				return retry.WithJitterPercent(5, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "capped_duration",
+			b: func() retry.Backoff {
+				return retry.WithCappedDuration(1*time.Second, retry.WithMaxRetries(2, retry.NewExponential(1*time.Second)))
+			},
+		},
+		{
+			name: "quantized_delay",
+			b: func() retry.Backoff {
+				return retry.WithQuantizedDelay(1*time.Second, retry.RoundUp, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "error_budgets",
+			b: func() retry.Backoff {
+				b := retry.WithErrorBudgets(map[string]uint64{"a": 1, "b": 5}, func(err error) string {
+					return err.Error()
+				}, retry.NewConstant(1*time.Second)).(retry.ErrorBackoff)
+				return retry.BackoffFunc(func() (time.Duration, bool) {
+					return b.NextError(errors.New("a"))
+				})
+			},
+		},
+		{
+			name: "error_budgets_other_class",
+			b: func() retry.Backoff {
+				b := retry.WithErrorBudgets(map[string]uint64{"a": 1, "b": 5}, func(err error) string {
+					return err.Error()
+				}, retry.NewConstant(1*time.Second)).(retry.ErrorBackoff)
+
+				// Once class a is exhausted, class b also stops.
+				var calls atomic.Int64
+				return retry.BackoffFunc(func() (time.Duration, bool) {
+					if calls.Add(1) <= 2 {
+						return b.NextError(errors.New("a"))
+					}
+					return b.NextError(errors.New("b"))
+				})
+			},
+		},
+		{
+			name: "local_refunds",
+			b: func() retry.Backoff {
+				b := retry.WithLocalRefunds(5, 1*time.Second, retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))).(retry.ErrorBackoff)
+
+				// Local failures after the stop would be refunded.
+				var calls atomic.Int64
+				return retry.BackoffFunc(func() (time.Duration, bool) {
+					if calls.Add(1) <= 2 {
+						return b.NextError(errOops)
+					}
+					return b.NextError(retry.LocalFailure(errOops))
+				})
+			},
+		},
+		{
+			name: "max_sleep",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(3*time.Second, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "max_sleep_truncated",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(3500*time.Millisecond, retry.NewConstant(1*time.Second), retry.WithTruncateFinalSleep())
+			},
+		},
+		{
+			name: "max_sleep_resuming",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(time.Hour, resuming())
+			},
+		},
+		{
+			name: "budget",
+			b: func() retry.Backoff {
+				budget := retry.NewBudget(1, 0, retry.WithBudgetClock(newFakeClock()))
+				b := budget.Wrap(retry.NewConstant(1 * time.Second))
+
+				// Requests recorded after the stop refill the budget.
+				return &probed{Backoff: b, probe: func() {
+					for i := 0; i < 10; i++ {
+						budget.Deposit()
+					}
+				}}
+			},
+		},
+		{
+			name: "budget_resuming",
+			b: func() retry.Backoff {
+				return retry.NewBudget(1, 1).Wrap(resuming())
+			},
+		},
+		{
+			name: "auto_reset",
+			b: func() retry.Backoff {
+				// Within idle, the stop of next is kept.
+				clock := newFakeClock()
+				b := retry.WithAutoReset(time.Minute, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)), retry.WithNowFunc(clock.Now))
+				return &probed{Backoff: b, probe: func() { clock.Advance(30 * time.Second) }}
+			},
+		},
+		{
+			name: "schedule",
+			b: func() retry.Backoff {
+				return retry.NewSchedule(1*time.Second, 2*time.Second, 5*time.Second)
+			},
+		},
+		{
+			name: "linear",
+			b: func() retry.Backoff {
+				return retry.WithMaxRetries(3, retry.NewLinear(1*time.Second))
+			},
+		},
+		{
+			name: "decorrelated_jitter",
+			b: func() retry.Backoff {
+				return retry.WithMaxRetries(3, retry.NewDecorrelatedJitter(1*time.Second, 10*time.Second))
+			},
+		},
+		{
+			name: "fibonacci_capped",
+			b: func() retry.Backoff {
+				return retry.WithMaxRetries(3, retry.NewFibonacciCapped(1*time.Second, 2*time.Second))
+			},
+		},
+		{
+			name: "calendar",
+			b: func() retry.Backoff {
+				clock := newFakeClock()
+				return retry.WithMaxRetries(3, retry.NewCalendarBackoff([]retry.CalendarStep{{Days: 1}}, retry.WithNowFunc(clock.Now)))
+			},
+		},
+		{
+			name: "adaptive_cutoff",
+			b: func() retry.Backoff {
+				// Only the second attempt has enough samples to stop.
+				m := retry.NewSuccessModel(0.1)
+				for i := 0; i < 60; i++ {
+					m.Observe(retry.Outcome{Attempt: 2, Err: errOops})
+				}
+				return retry.WithAdaptiveCutoff(m, 0.5, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "leader_only",
+			b: func() retry.Backoff {
+				lock := retry.NewLocalLocker()
+				if ok, _ := lock.TryLock(context.Background()); !ok {
+					panic("expected lock")
+				}
+				return retry.WithLeaderOnly(lock, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "leader_only_released",
+			b: func() retry.Backoff {
+				return retry.WithLeaderOnly(retry.NewLocalLocker(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "lease_renewed",
+			b: func() retry.Backoff {
+				return retry.WithLease(scriptedLease(5*time.Second, 0, time.Hour), 1*time.Second, retry.NewConstant(1*time.Second))
+			},
+		},
+		{
+			name: "startup_splay",
+			b: func() retry.Backoff {
+				return retry.WithStartupSplay(1*time.Second, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+		},
+		{
+			name: "attempt",
+			b: func() retry.Backoff {
+				return retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+					return 1 * time.Second, retry == 3
+				})
+			},
+		},
+		{
+			name: "failure_threshold_success",
+			b: func() retry.Backoff {
+				b := retry.WithFailureThreshold(3, resuming())
+				return &probed{Backoff: b, probe: func() {
+					b.(retry.ObserverBackoff).Observe(retry.Outcome{Attempt: 1})
+				}}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.b()
+			var probe func()
+			if p, ok := b.(*probed); ok {
+				probe = p.probe
+			}
+			testStickyStop(t, b, probe)
+		})
+	}
+}
+
+// probed is a backoff with a function to call once it has stopped.
+type probed struct {
+	retry.Backoff
+	probe func()
+}
@@ -0,0 +1,135 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PolicyAdjustments reports how [Constrained] changed a requested policy to
+// comply with the limits.
+type PolicyAdjustments struct {
+	// Requested is the policy as requested.
+	Requested Policy
+
+	// Applied is the policy used to build the backoff.
+	Applied Policy
+
+	// Fields are the names of the Policy fields that were clamped, in the order
+	// they are declared in Policy. It is empty if the request complied.
+	Fields []string
+}
+
+// ConstraintOption is an option that configures [Constrained].
+type ConstraintOption func(c *constraintConfig)
+
+type constraintConfig struct {
+	reject map[string]bool
+	report func(a PolicyAdjustments)
+}
+
+// WithRejectExceeding causes [Constrained] to reject, rather than clamp,
+// requested policies whose given fields exceed the limits. Fields are named
+// like the fields of [Policy]: "Jitter", "JitterPercent", "Cap",
+// "MaxAttempts", and "MaxDuration".
+func WithRejectExceeding(fields ...string) ConstraintOption {
+	return func(c *constraintConfig) {
+		for _, f := range fields {
+			c.reject[f] = true
+		}
+	}
+}
+
+// WithAdjustmentReport registers a function that is called with the
+// adjustments made to every requested policy that is not rejected, including
+// policies that complied.
+func WithAdjustmentReport(fn func(a PolicyAdjustments)) ConstraintOption {
+	return func(c *constraintConfig) {
+		c.report = fn
+	}
+}
+
+// Constrained returns a builder of backoffs from requested policies that
+// enforces the limits in max, for platform teams to hand to product teams as a
+// single enforcement point. The limits are:
+//
+//   - MaxAttempts, Cap, and MaxDuration are maxima. A requested value that is
+//     greater, or 0 and therefore unlimited, exceeds them.
+//   - Jitter and JitterPercent are minima. A requested value that is less
+//     exceeds them.
+//
+// Zero values in max impose no limit. The other fields of max are ignored.
+//
+// By default, a requested value that exceeds a limit is clamped to it; see
+// [WithRejectExceeding] to reject it instead, and [WithAdjustmentReport] to
+// learn what was clamped. Rejections are [*ValidationError] values, joined, as
+// are the errors of invalid requested policies.
+func Constrained(max Policy, opts ...ConstraintOption) func(requested Policy) (Backoff, error) {
+	cfg := &constraintConfig{reject: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(requested Policy) (Backoff, error) {
+		if err := requested.Validate(); err != nil {
+			return nil, err
+		}
+
+		applied := requested
+		var fields []string
+		var errs []error
+		enforce := func(field string, exceeds bool, clamp func(), reason string) {
+			if !exceeds {
+				return
+			}
+			if cfg.reject[field] {
+				errs = append(errs, &ValidationError{Field: field, Reason: reason})
+				return
+			}
+			clamp()
+			fields = append(fields, field)
+		}
+
+		enforce("Jitter", max.Jitter > 0 && requested.Jitter < max.Jitter,
+			func() { applied.Jitter = max.Jitter },
+			fmt.Sprintf("must be at least %s", max.Jitter))
+		enforce("JitterPercent", max.JitterPercent > 0 && requested.JitterPercent < max.JitterPercent,
+			func() { applied.JitterPercent = max.JitterPercent },
+			fmt.Sprintf("must be at least %d", max.JitterPercent))
+		enforce("Cap", exceedsMaxDuration(requested.Cap, max.Cap),
+			func() { applied.Cap = max.Cap },
+			fmt.Sprintf("must be set and at most %s", max.Cap))
+		enforce("MaxAttempts", max.MaxAttempts > 0 && (requested.MaxAttempts == 0 || requested.MaxAttempts > max.MaxAttempts),
+			func() { applied.MaxAttempts = max.MaxAttempts },
+			fmt.Sprintf("must be set and at most %d", max.MaxAttempts))
+		enforce("MaxDuration", exceedsMaxDuration(requested.MaxDuration, max.MaxDuration),
+			func() { applied.MaxDuration = max.MaxDuration },
+			fmt.Sprintf("must be set and at most %s", max.MaxDuration))
+
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+
+		// Clamping may still produce an invalid policy, such as a jitter minimum
+		// that overflows.
+		b, err := TryBuild(applied)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.report != nil {
+			cfg.report(PolicyAdjustments{
+				Requested: requested,
+				Applied:   applied,
+				Fields:    fields,
+			})
+		}
+		return b, nil
+	}
+}
+
+// exceedsMaxDuration reports whether the requested duration d exceeds the limit
+// max, where 0 means unlimited for both.
+func exceedsMaxDuration(d, max time.Duration) bool {
+	return max > 0 && (d == 0 || d > max)
+}
@@ -0,0 +1,177 @@
+package retry_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestConstrained(t *testing.T) {
+	t.Parallel()
+
+	limits := retry.Policy{
+		Jitter:      100 * time.Millisecond,
+		Cap:         30 * time.Second,
+		MaxAttempts: 5,
+		MaxDuration: 2 * time.Minute,
+	}
+
+	compliant := retry.Policy{
+		Algorithm:   retry.AlgorithmExponential,
+		Base:        1 * time.Second,
+		Jitter:      200 * time.Millisecond,
+		Cap:         10 * time.Second,
+		MaxAttempts: 3,
+		MaxDuration: 1 * time.Minute,
+	}
+
+	cases := []struct {
+		name      string
+		opts      []retry.ConstraintOption
+		requested retry.Policy
+		applied   retry.Policy
+		fields    []string
+		rejected  []string
+	}{
+		{
+			name:      "compliant",
+			requested: compliant,
+			applied:   compliant,
+		},
+		{
+			name: "clamped",
+			requested: retry.Policy{
+				Algorithm:   retry.AlgorithmExponential,
+				Base:        1 * time.Second,
+				Cap:         1 * time.Minute,
+				MaxAttempts: 10,
+			},
+			applied: retry.Policy{
+				Algorithm:   retry.AlgorithmExponential,
+				Base:        1 * time.Second,
+				Jitter:      100 * time.Millisecond,
+				Cap:         30 * time.Second,
+				MaxAttempts: 5,
+				MaxDuration: 2 * time.Minute,
+			},
+			fields: []string{"Jitter", "Cap", "MaxAttempts", "MaxDuration"},
+		},
+		{
+			name: "clamped_and_rejected",
+			opts: []retry.ConstraintOption{retry.WithRejectExceeding("MaxAttempts", "Cap")},
+			requested: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				Cap:         1 * time.Minute,
+				MaxAttempts: 10,
+			},
+			rejected: []string{"Cap", "MaxAttempts"},
+		},
+		{
+			name: "rejected_field_compliant",
+			opts: []retry.ConstraintOption{retry.WithRejectExceeding("MaxAttempts")},
+			requested: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				MaxAttempts: 2,
+			},
+			applied: retry.Policy{
+				Algorithm:   retry.AlgorithmConstant,
+				Base:        1 * time.Second,
+				Jitter:      100 * time.Millisecond,
+				Cap:         30 * time.Second,
+				MaxAttempts: 2,
+				MaxDuration: 2 * time.Minute,
+			},
+			fields: []string{"Jitter", "Cap", "MaxDuration"},
+		},
+		{
+			name: "invalid",
+			requested: retry.Policy{
+				Algorithm: retry.AlgorithmConstant,
+			},
+			rejected: []string{"Base"},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reports []retry.PolicyAdjustments
+			opts := append(tc.opts, retry.WithAdjustmentReport(func(a retry.PolicyAdjustments) {
+				reports = append(reports, a)
+			}))
+
+			b, err := retry.Constrained(limits, opts...)(tc.requested)
+			if len(tc.rejected) > 0 {
+				if b != nil {
+					t.Errorf("expected nil backoff, got %v", b)
+				}
+				for _, field := range tc.rejected {
+					if !hasValidationField(err, field) {
+						t.Errorf("expected %v to reject %v", err, field)
+					}
+				}
+				if got, want := len(reports), 0; got != want {
+					t.Errorf("expected %v to be %v", got, want)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b == nil {
+				t.Fatal("expected backoff")
+			}
+			if got, want := len(reports), 1; got != want {
+				t.Fatalf("expected %v to be %v", got, want)
+			}
+
+			report := reports[0]
+			if got, want := report.Requested, tc.requested; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := report.Applied, tc.applied; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := report.Fields, tc.fields; !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			// The backoff respects the applied maximum attempts.
+			var retries uint64
+			for {
+				if _, stop := b.Next(); stop {
+					break
+				}
+				retries++
+			}
+			if got, want := retries, tc.applied.MaxAttempts-1; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+// hasValidationField reports whether err contains a validation error for the
+// given field.
+func hasValidationField(err error, field string) bool {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, err := range errs {
+		var verr *retry.ValidationError
+		if errors.As(err, &verr) && verr.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+type dedupTokenKey struct{}
+
+// WithDedupSeed makes a deduplication token derived from seed available to
+// every attempt via [GetDedupToken]. Downstream consumers without idempotency
+// keys can use the token to discard duplicates of side effects, such as a
+// published message, that a retried attempt repeats.
+//
+// The token is a hash of seed, so it is identical across the attempts of a
+// call and across processes given the same seed, which allows replays after a
+// restart. The seed should identify the logical operation, such as an order
+// ID, and be distinct for every operation.
+func WithDedupSeed(seed string) DoOption {
+	token := dedupToken(seed)
+	return func(c *doConfig) {
+		c.beforeAttempt = append(c.beforeAttempt, func(ctx context.Context) (context.Context, error) {
+			return context.WithValue(ctx, dedupTokenKey{}, token), nil
+		})
+	}
+}
+
+// GetDedupToken returns the deduplication token of the current attempt from the
+// context passed to a [RetryFunc] or [RetryFuncValue]. It returns the empty
+// string if the call was not given [WithDedupSeed].
+func GetDedupToken(ctx context.Context) string {
+	token, _ := ctx.Value(dedupTokenKey{}).(string)
+	return token
+}
+
+// dedupToken returns the token for seed, which is the hex encoding of the first
+// 16 bytes of its SHA-256 hash. The hash is domain-separated, so the token does
+// not reveal the hash of the seed itself.
+func dedupToken(seed string) string {
+	sum := sha256.Sum256([]byte("go-retry dedup token\x00" + seed))
+	return hex.EncodeToString(sum[:16])
+}
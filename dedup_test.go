@@ -0,0 +1,102 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithDedupSeed(t *testing.T) {
+	t.Parallel()
+
+	tokens := func(seed string) []string {
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var out []string
+		if err := retry.Do(ctx, b, func(ctx context.Context) error {
+			out = append(out, retry.GetDedupToken(ctx))
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithDedupSeed(seed)); err == nil {
+			t.Fatal("expected err")
+		}
+		return out
+	}
+
+	t.Run("stable_across_attempts", func(t *testing.T) {
+		t.Parallel()
+
+		got := tokens("order-1234")
+		if got, want := len(got), 4; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i, token := range got {
+			if token == "" {
+				t.Errorf("attempt %d: expected a token", i+1)
+			}
+			if token != got[0] {
+				t.Errorf("attempt %d: expected %v to be %v", i+1, token, got[0])
+			}
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		// The token must not change between releases or processes, or replays
+		// after a restart would not be deduplicated.
+		if got, want := tokens("order-1234")[0], "e2e443b9fa64f52980a4f11ea2d7b14c"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := tokens("order-1234")[0], tokens("order-1234")[0]; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("distinct_seeds", func(t *testing.T) {
+		t.Parallel()
+
+		if a, b := tokens("order-1234")[0], tokens("order-1235")[0]; a == b {
+			t.Errorf("expected %v to differ from %v", a, b)
+		}
+	})
+
+	t.Run("no_seed", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retry.Do(context.Background(), retry.NewConstant(1), func(ctx context.Context) error {
+			if got, want := retry.GetDedupToken(ctx), ""; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func ExampleWithDedupSeed() {
+	ctx := context.Background()
+	orderID := "order-1234"
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond))
+	if err := retry.Do(ctx, b, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://queue.example.com/publish", nil)
+		if err != nil {
+			return err
+		}
+
+		// Consumers discard messages whose token they have already seen.
+		req.Header.Set("X-Dedup-Token", retry.GetDedupToken(ctx))
+		fmt.Println(req.Header.Get("X-Dedup-Token"))
+		return nil
+	}, retry.WithDedupSeed(orderID)); err != nil {
+		// handle error
+	}
+
+	// Output: e2e443b9fa64f52980a4f11ea2d7b14c
+}
@@ -0,0 +1,79 @@
+package retry
+
+import (
+	"time"
+)
+
+// deliveryState is the state of the current attempt of a retry loop, as far as
+// the callbacks of [Hooks] are concerned.
+//
+// An attempt starts pending and becomes running once it is made. A running
+// attempt that fails with a retryable error is either scheduled for retry with
+// OnRetry, which makes the next attempt pending, or ends the loop with a stop
+// or cancel hook. A pending attempt can also end the loop with a stop or
+// cancel hook. Nothing is delivered once the loop has ended.
+type deliveryState uint8
+
+const (
+	statePending deliveryState = iota
+	stateRunning
+	stateDone
+)
+
+// delivery enforces the delivery contract of [Hooks] for a single call to [Do]
+// or [DoValue]. The stop hooks are called by the Attempter when it finishes, so
+// a finished Attempter means the loop has ended.
+type delivery struct {
+	cfg *doConfig
+	a   *Attempter
+
+	state deliveryState
+
+	// attempt is the number of the pending or running attempt.
+	attempt uint64
+}
+
+func newDelivery(cfg *doConfig, a *Attempter) *delivery {
+	return &delivery{cfg: cfg, a: a, attempt: a.Attempts() + 1}
+}
+
+// start records that the pending attempt is being made.
+func (d *delivery) start() {
+	if d.state == statePending {
+		d.state = stateRunning
+	}
+}
+
+// retry schedules the next attempt after the running one failed, calling the
+// OnRetry hooks with delay and err. A nil err schedules the next attempt
+// without calling them, for an iteration that is not a retry.
+func (d *delivery) retry(delay time.Duration, err error) {
+	if d.state != stateRunning || d.a.done {
+		return
+	}
+	if err != nil {
+		for _, fn := range d.cfg.onRetry {
+			fn(d.attempt, delay, err)
+		}
+	}
+	d.state = statePending
+	d.attempt++
+}
+
+// cancel ends the loop because the context is done, calling the cancel hooks
+// with err, and returns err.
+func (d *delivery) cancel(err error) error {
+	if d.state == stateDone || d.a.done {
+		return err
+	}
+
+	phase := CancelBeforeAttempt
+	if d.state == stateRunning {
+		phase = CancelAfterAttempt
+	}
+	d.state = stateDone
+	for _, fn := range d.cfg.onCancel {
+		fn(d.attempt, phase, err)
+	}
+	return err
+}
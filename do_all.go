@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// DoAll retries f for each item in items, each under its own retry loop with a
+// backoff from newBackoff, and returns the final error of each loop, aligned by
+// index with items. The error of an item that succeeded is nil.
+//
+// The loops run concurrently, by default all at once; use [WithConcurrency] to
+// bound them. When ctx is done, loops in flight stop as they would with [Do],
+// and items not yet started are not attempted and report the context's error.
+// DoAll returns once every loop has finished.
+func DoAll[T any](ctx context.Context, newBackoff func() Backoff, items []T, f func(ctx context.Context, item T) error, opts ...DoOption) []error {
+	cfg := newDoConfig(opts)
+	errs := make([]error, len(items))
+
+	var sem chan struct{}
+	if n := cfg.concurrency; n > 0 {
+		sem = make(chan struct{}, n)
+	}
+
+	var wg sync.WaitGroup
+	for i, item := range items {
+		i, item := i, item
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = contextError(ctx)
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			errs[i] = Do(ctx, newBackoff(), func(ctx context.Context) error {
+				return f(ctx, item)
+			}, opts...)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+// WithConcurrency limits [DoAll] to running n retry loops at once. A value less
+// than or equal to zero means there is no limit, which is the default. Other
+// functions ignore it.
+func WithConcurrency(n int) DoOption {
+	return func(c *doConfig) {
+		c.concurrency = n
+	}
+}
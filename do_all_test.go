@@ -0,0 +1,178 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mixed", func(t *testing.T) {
+		t.Parallel()
+
+		errPermanent := errors.New("permanent")
+		errRetryable := errors.New("retryable")
+
+		// Each item has its own backoff, so every failing item gets 3 attempts
+		// regardless of how the others fare.
+		newBackoff := func() retry.Backoff {
+			return retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+		}
+
+		var lock sync.Mutex
+		attempts := make(map[string]int)
+
+		items := []string{"ok", "permanent", "retryable", "flaky"}
+		errs := retry.DoAll(context.Background(), newBackoff, items, func(_ context.Context, item string) error {
+			lock.Lock()
+			attempts[item]++
+			n := attempts[item]
+			lock.Unlock()
+
+			switch item {
+			case "permanent":
+				return errPermanent
+			case "retryable":
+				return retry.RetryableError(errRetryable)
+			case "flaky":
+				if n < 3 {
+					return retry.RetryableError(errRetryable)
+				}
+			}
+			return nil
+		})
+
+		if got, want := len(errs), len(items); got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if errs[0] != nil {
+			t.Errorf("expected %v to be nil", errs[0])
+		}
+		if got, want := errs[1], errPermanent; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if !errors.Is(errs[2], errRetryable) || !errors.Is(errs[2], retry.ErrExhausted) {
+			t.Errorf("expected %v to be %v", errs[2], errRetryable)
+		}
+		if errs[3] != nil {
+			t.Errorf("expected %v to be nil", errs[3])
+		}
+
+		for item, want := range map[string]int{"ok": 1, "permanent": 1, "retryable": 3, "flaky": 3} {
+			if got := attempts[item]; got != want {
+				t.Errorf("expected %q to have %v attempts, got %v", item, want, got)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		errs := retry.DoAll(context.Background(), nil, []int{}, func(_ context.Context, _ int) error {
+			return nil
+		})
+		if got, want := len(errs), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("concurrency_limit", func(t *testing.T) {
+		t.Parallel()
+
+		newBackoff := func() retry.Backoff {
+			return retry.NewConstant(1 * time.Millisecond)
+		}
+
+		const limit = 3
+		var inFlight, peak atomic.Int64
+		items := make([]int, 20)
+		errs := retry.DoAll(context.Background(), newBackoff, items, func(_ context.Context, _ int) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			return nil
+		}, retry.WithConcurrency(limit))
+
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("expected item %d to succeed, got %v", i, err)
+			}
+		}
+		if got := peak.Load(); got > limit {
+			t.Errorf("expected at most %d loops at once, got %d", limit, got)
+		}
+	})
+
+	t.Run("canceled_mid_flight", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		newBackoff := func() retry.Backoff {
+			return retry.NewConstant(1 * time.Hour)
+		}
+
+		// The first two items start and then sleep; the rest wait for a slot.
+		var started atomic.Int64
+		items := []int{0, 1, 2, 3, 4}
+		start := time.Now()
+		errs := retry.DoAll(ctx, newBackoff, items, func(_ context.Context, item int) error {
+			if started.Add(1) == 2 {
+				time.AfterFunc(5*time.Millisecond, cancel)
+			}
+			return retry.RetryableError(fmt.Errorf("item %d failed", item))
+		}, retry.WithConcurrency(2))
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected cancellation to be prompt, took %v", elapsed)
+		}
+		if got, want := started.Load(), int64(2); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		for i, err := range errs {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected item %d error %v to be %v", i, err, context.Canceled)
+			}
+		}
+	})
+}
+
+func ExampleDoAll() {
+	ctx := context.Background()
+
+	newBackoff := func() retry.Backoff {
+		return retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+	}
+
+	objects := []string{"a.txt", "b.txt", "c.txt"}
+	errs := retry.DoAll(ctx, newBackoff, objects, func(ctx context.Context, name string) error {
+		if name == "b.txt" {
+			return fmt.Errorf("%s: permission denied", name)
+		}
+		return nil
+	}, retry.WithConcurrency(2))
+
+	for i, err := range errs {
+		fmt.Printf("%s: %v\n", objects[i], err)
+	}
+
+	// Output:
+	// a.txt: <nil>
+	// b.txt: b.txt: permission denied
+	// c.txt: <nil>
+}
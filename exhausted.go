@@ -0,0 +1,168 @@
+package retry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExhaustedError is the error returned by [Do] and [DoValue] when they stop
+// retrying a retryable error and were given [WithExhaustionDetail]. Its message
+// summarizes the attempts and the backoff, such as
+//
+//	retry: exhausted 4 attempts over 7.2s [exp base=500ms cap=5s ±10%]: connection refused
+//
+// which makes logged failures easier to triage. The final error remains
+// reachable with [errors.Is] and [errors.As].
+type ExhaustedError struct {
+	// Attempts is the number of attempts made.
+	Attempts uint64
+
+	// Elapsed is the time from the start of the call until retrying stopped,
+	// measured with the clock set by [WithClock].
+	Elapsed time.Duration
+
+	// Reason is why retrying stopped.
+	Reason StopReason
+
+	// Backoff is the backoff given to the call.
+	Backoff Backoff
+
+	// Err is the final error.
+	Err error
+}
+
+// Error returns the error string. The backoff is described only when Error is
+// called, so calls that never log the error do not pay for the description.
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry: exhausted %d attempts over %v [%s]: %v",
+		e.Attempts, e.Elapsed.Round(time.Millisecond), Describe(e.Backoff), e.Err)
+}
+
+// Unwrap returns the final error.
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// WithExhaustionDetail causes [Do] and [DoValue] to return an
+// [*ExhaustedError] when they stop retrying a retryable error, such as when the
+// backoff stops. Success, permanent errors, and context cancellation return
+// their errors unchanged.
+func WithExhaustionDetail() DoOption {
+	return func(c *doConfig) {
+		c.exhaustionDetail = true
+	}
+}
+
+// exhaustion records how a single call stopped retrying, for
+// WithExhaustionDetail.
+type exhaustion struct {
+	start    time.Time
+	end      time.Time
+	attempts uint64
+	reason   StopReason
+}
+
+// wrap returns err as an ExhaustedError if the call stopped retrying, or err
+// unchanged otherwise.
+func (x *exhaustion) wrap(err error, b Backoff) error {
+	if err == nil || x.reason == ReasonNone {
+		return err
+	}
+	return &ExhaustedError{
+		Attempts: x.attempts,
+		Elapsed:  x.end.Sub(x.start),
+		Reason:   x.reason,
+		Backoff:  b,
+		Err:      err,
+	}
+}
+
+// Describe returns a compact description of the backoff chain b for logs, such
+// as "exp base=500ms cap=5s ±10%" for a 10% jitter applied to a capped
+// exponential backoff. The algorithm comes first, followed by each wrapper from
+// the innermost to the outermost. Backoffs from outside this package are
+// described as "custom", and wrappers without parameters only by name.
+func Describe(b Backoff) string {
+	var parts []string
+	Walk(b, func(node Backoff) bool {
+		parts = append(parts, describeNode(node))
+		return true
+	})
+
+	// Walk visits the outermost wrapper first.
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeNode returns the description of a single backoff in a chain.
+func describeNode(b Backoff) string {
+	switch b := b.(type) {
+	case *constantBackoff:
+		return fmt.Sprintf("const base=%v", b.Base())
+	case *linearBackoff:
+		return fmt.Sprintf("linear base=%v", b.Base())
+	case *exponentialBackoff:
+		return fmt.Sprintf("exp base=%v", b.Base())
+	case *factorBackoff:
+		return fmt.Sprintf("exp base=%v factor=%v", b.Base(), b.Factor())
+	case *fibonacciBackoff:
+		return fmt.Sprintf("fib base=%v", b.Base())
+	case *fibonacciCappedBackoff:
+		return fmt.Sprintf("fib base=%v max=%v", b.Base(), b.Cap())
+	case *decorrelatedJitterBackoff:
+		return fmt.Sprintf("decorrelated base=%v cap=%v", b.Base(), b.Cap())
+	case *scheduleBackoff:
+		if b.repeatLast {
+			return fmt.Sprintf("schedule=%v repeat_last", b.durations)
+		}
+		return fmt.Sprintf("schedule=%v", b.durations)
+	case *attemptBackoff:
+		return "attempt"
+	case *CalendarBackoff:
+		return "calendar"
+	case *jitterBackoff:
+		return fmt.Sprintf("±%v", b.Jitter())
+	case *jitterPercentBackoff:
+		return fmt.Sprintf("±%d%%", b.JitterPercent())
+	case *fullJitterBackoff:
+		if b.equal {
+			return "equal_jitter"
+		}
+		return "full_jitter"
+	case *maxRetriesBackoff:
+		return fmt.Sprintf("retries=%d", b.MaxRetries())
+	case *cappedDurationBackoff:
+		return fmt.Sprintf("cap=%v", b.Cap())
+	case *minDurationBackoff:
+		return fmt.Sprintf("min=%v", b.MinDuration())
+	case *maxDurationBackoff:
+		return fmt.Sprintf("max_duration=%v", b.MaxDuration())
+	case *maxSleepBackoff:
+		return fmt.Sprintf("max_sleep=%v", b.MaxSleep())
+	case *quantizedDelayBackoff:
+		return fmt.Sprintf("quantum=%v", b.Quantum())
+	case *errorBudgetsBackoff:
+		return "error_budgets"
+	case *adaptiveCutoffBackoff:
+		return "adaptive_cutoff"
+	case *leaderBackoff:
+		return "leader_only"
+	case *leaseBackoff:
+		return fmt.Sprintf("lease margin=%v", b.Margin())
+	case *startupSplayBackoff:
+		return fmt.Sprintf("splay=%v", b.Splay())
+	case *failureThresholdBackoff:
+		return fmt.Sprintf("threshold=%d", b.Threshold())
+	case *localRefundsBackoff:
+		return fmt.Sprintf("refunds=%d", b.MaxRefunds())
+	case *budgetBackoff:
+		return "retry_budget"
+	case *autoResetBackoff:
+		return fmt.Sprintf("auto_reset idle=%v", b.Idle())
+	default:
+		return "custom"
+	}
+}
@@ -0,0 +1,181 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	next := retry.NewConstant(1 * time.Second)
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		exp  string
+	}{
+		{
+			name: "constant",
+			b:    next,
+			exp:  "const base=1s",
+		},
+		{
+			name: "linear",
+			b:    retry.WithMaxRetries(3, retry.NewLinear(2*time.Second)),
+			exp:  "linear base=2s retries=3",
+		},
+		{
+			name: "exponential",
+			b: retry.WithJitterPercent(10, retry.WithCappedDuration(5*time.Second,
+				retry.NewExponential(500*time.Millisecond))),
+			exp: "exp base=500ms cap=5s ±10%",
+		},
+		{
+			name: "factor",
+			b:    retry.NewExponentialWithFactor(1*time.Second, 1.5),
+			exp:  "exp base=1s factor=1.5",
+		},
+		{
+			name: "fibonacci",
+			b: retry.WithMaxDuration(1*time.Minute, retry.WithMaxRetries(4,
+				retry.WithJitter(250*time.Millisecond, retry.NewFibonacci(1*time.Second)))),
+			exp: "fib base=1s ±250ms retries=4 max_duration=1m0s",
+		},
+		{
+			name: "fibonacci_capped",
+			b:    retry.NewFibonacciCapped(1*time.Second, 1*time.Minute),
+			exp:  "fib base=1s max=1m0s",
+		},
+		{
+			name: "full_jitter",
+			b:    retry.WithMaxRetries(5, retry.WithFullJitter(retry.NewExponential(1*time.Second))),
+			exp:  "exp base=1s full_jitter retries=5",
+		},
+		{
+			name: "equal_jitter",
+			b:    retry.WithEqualJitter(retry.NewConstant(1 * time.Second)),
+			exp:  "const base=1s equal_jitter",
+		},
+		{
+			name: "decorrelated",
+			b:    retry.WithMinDuration(100*time.Millisecond, retry.NewDecorrelatedJitter(1*time.Second, 30*time.Second)),
+			exp:  "decorrelated base=1s cap=30s min=100ms",
+		},
+		{
+			name: "schedule",
+			b:    retry.NewScheduleRepeatLast(1*time.Second, 5*time.Second),
+			exp:  "schedule=[1s 5s] repeat_last",
+		},
+		{
+			name: "auto_reset",
+			b:    retry.WithAutoReset(1*time.Minute, retry.NewScheduleRepeatLast(1*time.Second, 5*time.Second)),
+			exp:  "schedule=[1s 5s] repeat_last auto_reset idle=1m0s",
+		},
+		{
+			name: "custom",
+			b: retry.WithMaxSleep(10*time.Second, retry.BackoffFunc(func() (time.Duration, bool) {
+				return 1 * time.Second, false
+			})),
+			exp: "custom max_sleep=10s",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := retry.Describe(tc.b), tc.exp; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithExhaustionDetail(t *testing.T) {
+	t.Parallel()
+
+	errRefused := errors.New("connection refused")
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		b := retry.WithMaxRetries(3, retry.WithJitterPercent(10, retry.WithCappedDuration(5*time.Second,
+			retry.NewExponential(500*time.Millisecond)), retry.WithRandSeed(1)))
+
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			// Each attempt takes 100ms.
+			clock.Advance(100 * time.Millisecond)
+			return retry.RetryableError(errRefused)
+		}, retry.WithExhaustionDetail(), retry.WithClock(clock))
+
+		var eerr *retry.ExhaustedError
+		if !errors.As(err, &eerr) {
+			t.Fatalf("expected %v to be an ExhaustedError", err)
+		}
+		if !errors.Is(err, errRefused) {
+			t.Errorf("expected %v to be %v", err, errRefused)
+		}
+		if got, want := eerr.Attempts, uint64(4); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := eerr.Reason, retry.ReasonStopped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := err.Error(), "retry: exhausted 4 attempts over 4.07s [exp base=500ms cap=5s ±10% retries=3]: connection refused"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("with_history", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return retry.RetryableError(errRefused)
+		}, retry.WithExhaustionDetail(), retry.WithErrorHistory(5), retry.WithClock(newFakeClock()))
+
+		if got, want := err.Error(), "retry: exhausted 2 attempts over 1s [const base=1s retries=1]: connection refused"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := len(retry.AttemptErrors(err)), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("not_exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Second))
+		opts := []retry.DoOption{retry.WithExhaustionDetail(), retry.WithClock(newFakeClock())}
+
+		if err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return nil
+		}, opts...); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+
+		// Permanent errors are returned as is.
+		if got, want := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return errRefused
+		}, opts...), errRefused; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// So are context errors.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if got, want := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(errRefused)
+		}, opts...), context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
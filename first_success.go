@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoFunctions is returned by [FirstSuccess] and [DoTiered] when they are
+// given nothing to run.
+var ErrNoFunctions = errors.New("retry: no functions to run")
+
+// FirstSuccess runs each function in fs concurrently, each under its own retry
+// loop with a backoff from newBackoff. It returns the value and index of the
+// first function to succeed, and cancels the contexts of the others with the
+// cause [ErrAttemptSuperseded], including any that are sleeping between
+// attempts.
+//
+// If every function fails, FirstSuccess returns an index of -1 and an error
+// joining each function's error, in order. Errors are compacted with the
+// function set by [WithErrorCompaction] as they arrive. FirstSuccess returns as
+// soon as a function succeeds; it does not wait for the others to observe
+// cancellation.
+func FirstSuccess[T any](ctx context.Context, fs []RetryFuncValue[T], newBackoff func() Backoff, opts ...DoOption) (T, int, error) {
+	var zero T
+	if len(fs) == 0 {
+		return zero, -1, ErrNoFunctions
+	}
+
+	cfg := newDoConfig(opts)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(ErrAttemptSuperseded)
+
+	type result struct {
+		val T
+		idx int
+		err error
+	}
+
+	// Buffered so losers never block after the winner has returned.
+	resultCh := make(chan result, len(fs))
+	for i, f := range fs {
+		i, f := i, f
+		go func() {
+			val, err := DoValue(ctx, newBackoff(), f, opts...)
+			resultCh <- result{val: val, idx: i, err: err}
+		}()
+	}
+
+	errs := make([]error, len(fs))
+	for range fs {
+		r := <-resultCh
+		if r.err == nil {
+			return r.val, r.idx, nil
+		}
+		errs[r.idx] = cfg.compactError(r.err)
+	}
+	return zero, -1, errors.Join(errs...)
+}
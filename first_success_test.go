@@ -0,0 +1,231 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestFirstSuccess(t *testing.T) {
+	t.Parallel()
+
+	t.Run("staggered", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		// Replica 1 succeeds on its third attempt; the others never succeed and
+		// sleep for a long time between attempts.
+		var wg sync.WaitGroup
+		canceled := make([]bool, 3)
+		var lock sync.Mutex
+
+		newReplica := func(i int, succeedOn int) retry.RetryFuncValue[string] {
+			var attempt int
+			return func(ctx context.Context) (string, error) {
+				attempt++
+				if succeedOn > 0 && attempt >= succeedOn {
+					return fmt.Sprintf("replica-%d", i), nil
+				}
+				if attempt == 1 && succeedOn == 0 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						<-ctx.Done()
+						lock.Lock()
+						canceled[i] = errors.Is(context.Cause(ctx), retry.ErrAttemptSuperseded)
+						lock.Unlock()
+					}()
+				}
+				return "", retry.RetryableError(fmt.Errorf("replica %d unavailable", i))
+			}
+		}
+
+		fs := []retry.RetryFuncValue[string]{
+			newReplica(0, 0),
+			newReplica(1, 3),
+			newReplica(2, 0),
+		}
+
+		newBackoff := func() retry.Backoff {
+			return retry.NewConstant(1 * time.Millisecond)
+		}
+
+		val, idx, err := retry.FirstSuccess(ctx, fs, newBackoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, "replica-1"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := idx, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// Losers are canceled promptly.
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("losers were not canceled")
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		if !canceled[0] || !canceled[2] {
+			t.Errorf("expected losers to be canceled as superseded: %v", canceled)
+		}
+	})
+
+	t.Run("cancels_during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		// The loser fails and then sleeps for an hour. The winner only succeeds
+		// once the loser is about to sleep.
+		sleeping := make(chan struct{})
+		loserCtx := make(chan context.Context, 1)
+		fs := []retry.RetryFuncValue[int]{
+			func(ctx context.Context) (int, error) {
+				loserCtx <- ctx
+				return 0, retry.RetryableError(io.EOF)
+			},
+			func(ctx context.Context) (int, error) {
+				<-sleeping
+				return 42, nil
+			},
+		}
+
+		var once sync.Once
+		newBackoff := func() retry.Backoff {
+			b := retry.NewConstant(1 * time.Hour)
+			return retry.BackoffFunc(func() (time.Duration, bool) {
+				once.Do(func() { close(sleeping) })
+				return b.Next()
+			})
+		}
+
+		start := time.Now()
+		val, idx, err := retry.FirstSuccess(ctx, fs, newBackoff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val != 42 || idx != 1 {
+			t.Errorf("expected (42, 1), got (%v, %v)", val, idx)
+		}
+		if got, max := time.Since(start), 1*time.Second; got > max {
+			t.Errorf("expected %v to be less than %v", got, max)
+		}
+
+		select {
+		case <-(<-loserCtx).Done():
+		case <-time.After(5 * time.Second):
+			t.Fatal("loser was not canceled")
+		}
+	})
+
+	t.Run("all_fail", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+
+		errA := errors.New("a")
+		errB := errors.New("b")
+		fs := []retry.RetryFuncValue[int]{
+			func(ctx context.Context) (int, error) {
+				return 0, retry.RetryableError(errA)
+			},
+			func(ctx context.Context) (int, error) {
+				return 0, errB
+			},
+		}
+
+		newBackoff := func() retry.Backoff {
+			return retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+		}
+
+		val, idx, err := retry.FirstSuccess(ctx, fs, newBackoff)
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		if val != 0 {
+			t.Errorf("expected %v to be %v", val, 0)
+		}
+		if got, want := idx, -1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if !errors.Is(err, errA) || !errors.Is(err, errB) {
+			t.Errorf("expected %v to contain %v and %v", err, errA, errB)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		_, idx, err := retry.FirstSuccess[int](context.Background(), nil, func() retry.Backoff {
+			return retry.NewConstant(1 * time.Second)
+		})
+		if !errors.Is(err, retry.ErrNoFunctions) {
+			t.Errorf("expected %v to be %v", err, retry.ErrNoFunctions)
+		}
+		if got, want := err.Error(), "retry: no functions to run"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := idx, -1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("parent_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		fs := []retry.RetryFuncValue[int]{
+			func(ctx context.Context) (int, error) {
+				return 1, nil
+			},
+		}
+
+		_, _, err := retry.FirstSuccess(ctx, fs, func() retry.Backoff {
+			return retry.NewConstant(1 * time.Second)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+	})
+}
+
+func ExampleFirstSuccess() {
+	ctx := context.Background()
+
+	replicas := []string{"a", "b", "c"}
+	fs := make([]retry.RetryFuncValue[string], 0, len(replicas))
+	for _, replica := range replicas {
+		replica := replica
+		fs = append(fs, func(ctx context.Context) (string, error) {
+			// TODO: read from replica
+			return replica, nil
+		})
+	}
+
+	val, idx, err := retry.FirstSuccess(ctx, fs, func() retry.Backoff {
+		return retry.WithMaxRetries(3, retry.NewExponential(10*time.Millisecond))
+	})
+	if err != nil {
+		// handle error
+	}
+	_, _ = val, idx
+}
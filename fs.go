@@ -0,0 +1,34 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"syscall"
+)
+
+// IsRetryableFSError reports whether err is a filesystem error that is likely to
+// succeed if the operation is retried, such as an interrupted system call or a
+// stale network file handle. Errors such as running out of space or permission
+// denied are not retryable, and neither are I/O errors such as EIO, since data
+// that failed to reach the disk may be lost even if a retried fsync succeeds.
+//
+// The error is unwrapped to find a [syscall.Errno], so errors returned by the
+// os package (such as [os.PathError]) are classified by their underlying
+// errno. The set of retryable errno values depends on the operating system.
+func IsRetryableFSError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	_, ok := retryableFSErrnos[errno]
+	return ok
+}
+
+// DoFile wraps an idempotent filesystem operation with a backoff to retry.
+// Errors for which [IsRetryableFSError] returns true are retried without
+// needing to be wrapped with [RetryableError]. Errors explicitly wrapped with
+// RetryableError are also retried.
+func DoFile(ctx context.Context, b Backoff, f RetryFunc, opts ...DoOption) error {
+	opts = appendOptions(opts, withRetryPredicate(IsRetryableFSError))
+	return Do(ctx, b, f, opts...)
+}
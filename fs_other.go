@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package retry
+
+import (
+	"syscall"
+)
+
+// retryableFSErrnos is the set of errno values that IsRetryableFSError treats as
+// retryable. There are no known retryable values on this platform.
+var retryableFSErrnos = map[syscall.Errno]struct{}{}
@@ -0,0 +1,139 @@
+//go:build unix || windows
+
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestIsRetryableFSError(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range fsErrnoCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, err := range []error{
+				tc.errno,
+				&os.PathError{Op: "open", Path: "/tmp/foo", Err: tc.errno},
+				&os.LinkError{Op: "rename", Old: "/tmp/foo", New: "/tmp/bar", Err: tc.errno},
+				fmt.Errorf("failed to sync: %w", &os.PathError{Op: "sync", Path: "/tmp/foo", Err: tc.errno}),
+				os.NewSyscallError("fsync", tc.errno),
+			} {
+				if got, want := retry.IsRetryableFSError(err), tc.exp; got != want {
+					t.Errorf("%v: expected %v to be %v", err, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if retry.IsRetryableFSError(nil) {
+			t.Error("expected false")
+		}
+	})
+
+	t.Run("not_errno", func(t *testing.T) {
+		t.Parallel()
+
+		if retry.IsRetryableFSError(errors.New("oops")) {
+			t.Error("expected false")
+		}
+	})
+}
+
+func TestDoFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("retries_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		err := retry.DoFile(ctx, b, func(_ context.Context) error {
+			i++
+			return &os.PathError{Op: "open", Path: "/tmp/foo", Err: fsRetryableErrno}
+		})
+
+		var pathErr *os.PathError
+		if !errors.As(err, &pathErr) {
+			t.Fatalf("expected %v to be a path error", err)
+		}
+		if got, want := i, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops_permanent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		if err := retry.DoFile(ctx, b, func(_ context.Context) error {
+			i++
+			return &os.PathError{Op: "write", Path: "/tmp/foo", Err: fsPermanentErrno}
+		}); err == nil {
+			t.Fatal("expected err")
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("explicit_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		if err := retry.DoFile(ctx, b, func(_ context.Context) error {
+			i++
+			if i < 3 {
+				return retry.RetryableError(fmt.Errorf("oops"))
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleDoFile() {
+	ctx := context.Background()
+
+	b := retry.NewExponential(10 * time.Millisecond)
+	b = retry.WithMaxRetries(5, b)
+
+	if err := retry.DoFile(ctx, b, func(_ context.Context) error {
+		f, err := os.Create(os.DevNull)
+		if err != nil {
+			return err
+		}
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}); err != nil {
+		// handle error
+	}
+}
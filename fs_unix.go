@@ -0,0 +1,21 @@
+//go:build unix
+
+package retry
+
+import (
+	"syscall"
+)
+
+// retryableFSErrnos is the set of errno values that IsRetryableFSError treats as
+// retryable on unix systems.
+//
+// EIO is not retryable: after fsync fails with EIO, the kernel may have dropped
+// the dirty pages, so a retried fsync can succeed even though the data was
+// never written.
+var retryableFSErrnos = map[syscall.Errno]struct{}{
+	syscall.EINTR:     {},
+	syscall.EAGAIN:    {},
+	syscall.EBUSY:     {},
+	syscall.ESTALE:    {},
+	syscall.ETIMEDOUT: {},
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package retry_test
+
+import (
+	"syscall"
+)
+
+var fsErrnoCases = []struct {
+	name  string
+	errno syscall.Errno
+	exp   bool
+}{
+	{"eintr", syscall.EINTR, true},
+	{"eagain", syscall.EAGAIN, true},
+	{"ebusy", syscall.EBUSY, true},
+	{"estale", syscall.ESTALE, true},
+	{"etimedout", syscall.ETIMEDOUT, true},
+	{"eio", syscall.EIO, false},
+	{"enospc", syscall.ENOSPC, false},
+	{"eacces", syscall.EACCES, false},
+	{"eperm", syscall.EPERM, false},
+	{"enoent", syscall.ENOENT, false},
+	{"erofs", syscall.EROFS, false},
+}
+
+var fsRetryableErrno = syscall.ESTALE
+
+var fsPermanentErrno = syscall.ENOSPC
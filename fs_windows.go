@@ -0,0 +1,29 @@
+//go:build windows
+
+package retry
+
+import (
+	"syscall"
+)
+
+const (
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+	errorNetworkBusy      syscall.Errno = 54
+	errorUnexpNetErr      syscall.Errno = 59
+	errorSemTimeout       syscall.Errno = 121
+	errorBusy             syscall.Errno = 170
+)
+
+// retryableFSErrnos is the set of errno values that IsRetryableFSError treats as
+// retryable on windows.
+var retryableFSErrnos = map[syscall.Errno]struct{}{
+	errorSharingViolation:           {},
+	errorLockViolation:              {},
+	errorNetworkBusy:                {},
+	errorUnexpNetErr:                {},
+	errorSemTimeout:                 {},
+	errorBusy:                       {},
+	syscall.ERROR_NETNAME_DELETED:   {},
+	syscall.ERROR_OPERATION_ABORTED: {},
+}
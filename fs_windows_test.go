@@ -0,0 +1,29 @@
+//go:build windows
+
+package retry_test
+
+import (
+	"syscall"
+)
+
+var fsErrnoCases = []struct {
+	name  string
+	errno syscall.Errno
+	exp   bool
+}{
+	{"sharing_violation", syscall.Errno(32), true},
+	{"lock_violation", syscall.Errno(33), true},
+	{"network_busy", syscall.Errno(54), true},
+	{"unexp_net_err", syscall.Errno(59), true},
+	{"sem_timeout", syscall.Errno(121), true},
+	{"busy", syscall.Errno(170), true},
+	{"netname_deleted", syscall.ERROR_NETNAME_DELETED, true},
+	{"operation_aborted", syscall.ERROR_OPERATION_ABORTED, true},
+	{"disk_full", syscall.Errno(112), false},
+	{"access_denied", syscall.ERROR_ACCESS_DENIED, false},
+	{"file_not_found", syscall.ERROR_FILE_NOT_FOUND, false},
+}
+
+var fsRetryableErrno = syscall.Errno(32)
+
+var fsPermanentErrno = syscall.ERROR_ACCESS_DENIED
@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrGateClosed is returned by [Do] and [DoValue] when the global gate set with
+// [SetGlobalGate] is closed. If an attempt was made, the error wraps the error
+// from the most recent attempt as well.
+var ErrGateClosed = errors.New("retry: gate closed")
+
+// GateOption is an option that configures the global gate.
+type GateOption func(g *gate)
+
+// WithGateFirstAttempt causes loops that have not made an attempt yet to make
+// exactly one attempt when the gate is closed, instead of failing immediately.
+func WithGateFirstAttempt() GateOption {
+	return func(g *gate) {
+		g.firstAttempt = true
+	}
+}
+
+type gate struct {
+	allow        func() bool
+	firstAttempt bool
+}
+
+// globalGate is the gate consulted by Do, or nil if none is set.
+var globalGate atomic.Pointer[gate]
+
+// SetGlobalGate sets a function that every call to [Do] and [DoValue] consults
+// before each attempt and before each sleep. When it returns false, the loop
+// stops scheduling work and returns [ErrGateClosed], wrapping the error from
+// the most recent attempt, with the stop reason [ReasonGateClosed]. Attempts in
+// progress are not interrupted.
+//
+// It is intended for draining a process on shutdown without passing an option
+// at every call site. allow is called on every check, so it should be cheap,
+// such as loading an atomic flag. The gate may be replaced at any time; a nil
+// allow removes it.
+func SetGlobalGate(allow func() bool, opts ...GateOption) {
+	if allow == nil {
+		globalGate.Store(nil)
+		return
+	}
+
+	g := &gate{allow: allow}
+	for _, opt := range opts {
+		opt(g)
+	}
+	globalGate.Store(g)
+}
+
+// gateOpen reports whether the global gate allows the loop to continue. first
+// is true before the first attempt.
+func gateOpen(first bool) bool {
+	g := globalGate.Load()
+	if g == nil || (first && g.firstAttempt) {
+		return true
+	}
+	return g.allow()
+}
+
+// gateClosedError returns the error for a loop stopped by the global gate,
+// wrapping lastErr if an attempt was made.
+func gateClosedError(lastErr error) error {
+	if lastErr == nil {
+		return ErrGateClosed
+	}
+	return fmt.Errorf("%w: %w", ErrGateClosed, lastErr)
+}
@@ -0,0 +1,165 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// closingClock closes the gate when it sleeps.
+type closingClock struct {
+	*fakeClock
+	draining *atomic.Bool
+}
+
+func (c *closingClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.draining.Store(true)
+	return c.fakeClock.Sleep(ctx, d)
+}
+
+// TestSetGlobalGate is not parallel because the gate is global.
+func TestSetGlobalGate(t *testing.T) {
+	t.Cleanup(func() { retry.SetGlobalGate(nil) })
+
+	var draining atomic.Bool
+	allow := func() bool { return !draining.Load() }
+
+	b := func() retry.Backoff {
+		return retry.WithMaxRetries(5, retry.NewConstant(1*time.Second))
+	}
+
+	t.Run("closed_before_first_attempt", func(t *testing.T) {
+		draining.Store(true)
+		retry.SetGlobalGate(allow)
+
+		var attempts int
+		var reasons []retry.StopReason
+		err := retry.Do(context.Background(), b(), func(_ context.Context) error {
+			attempts++
+			return nil
+		}, retry.WithStopHook(func(reason retry.StopReason, _ error) {
+			reasons = append(reasons, reason)
+		}))
+		if !errors.Is(err, retry.ErrGateClosed) {
+			t.Errorf("expected %v to be %v", err, retry.ErrGateClosed)
+		}
+		if errors.Is(err, retry.ErrExhausted) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrExhausted)
+		}
+		if got, want := attempts, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if len(reasons) != 0 {
+			t.Errorf("expected %v to be empty", reasons)
+		}
+	})
+
+	t.Run("first_attempt", func(t *testing.T) {
+		draining.Store(true)
+		retry.SetGlobalGate(allow, retry.WithGateFirstAttempt())
+
+		var attempts int
+		err := retry.Do(context.Background(), b(), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(io.EOF)
+		}, retry.WithClock(newFakeClock()))
+		if !errors.Is(err, retry.ErrGateClosed) || !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v and %v", err, retry.ErrGateClosed, io.EOF)
+		}
+		if got, want := attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("closed_before_sleep", func(t *testing.T) {
+		draining.Store(false)
+		retry.SetGlobalGate(allow)
+
+		var attempts int
+		var reasons []retry.StopReason
+		err := retry.Do(context.Background(), b(), func(_ context.Context) error {
+			attempts++
+			if attempts == 2 {
+				draining.Store(true)
+			}
+			return retry.RetryableError(io.EOF)
+		}, retry.WithClock(newFakeClock()), retry.WithStopHook(func(reason retry.StopReason, _ error) {
+			reasons = append(reasons, reason)
+		}))
+		if !errors.Is(err, retry.ErrGateClosed) || !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v and %v", err, retry.ErrGateClosed, io.EOF)
+		}
+		if got, want := attempts, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if len(reasons) != 1 || reasons[0] != retry.ReasonGateClosed {
+			t.Errorf("expected %v to be [%v]", reasons, retry.ReasonGateClosed)
+		}
+	})
+
+	t.Run("closed_during_sleep", func(t *testing.T) {
+		draining.Store(false)
+		retry.SetGlobalGate(allow)
+
+		var attempts int
+		err := retry.Do(context.Background(), b(), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(io.EOF)
+		}, retry.WithClock(&closingClock{newFakeClock(), &draining}))
+		if !errors.Is(err, retry.ErrGateClosed) || !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v and %v", err, retry.ErrGateClosed, io.EOF)
+		}
+		if got, want := attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("negative_cache", func(t *testing.T) {
+		draining.Store(true)
+		retry.SetGlobalGate(allow)
+
+		cache := retry.NewNegativeCache(1*time.Minute, 10)
+		err := retry.DoCached(context.Background(), cache, "key", b(), func(_ context.Context) error {
+			return nil
+		})
+		if !errors.Is(err, retry.ErrGateClosed) {
+			t.Errorf("expected %v to be %v", err, retry.ErrGateClosed)
+		}
+		if got, want := cache.Len(), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		draining.Store(true)
+		retry.SetGlobalGate(nil)
+
+		if err := retry.Do(context.Background(), b(), func(_ context.Context) error {
+			return nil
+		}); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+}
+
+func ExampleSetGlobalGate() {
+	var draining atomic.Bool
+	retry.SetGlobalGate(func() bool { return !draining.Load() })
+
+	// On SIGTERM, stop retrying everywhere.
+	draining.Store(true)
+
+	err := retry.Do(context.Background(), retry.NewConstant(1*time.Second), func(_ context.Context) error {
+		return nil
+	})
+	retry.SetGlobalGate(nil)
+
+	if errors.Is(err, retry.ErrGateClosed) {
+		// handle draining
+	}
+}
@@ -0,0 +1,140 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// HealthStatus is the status of a [HealthProbe], suitable for marshaling into
+// the response of a health endpoint.
+type HealthStatus struct {
+	// Name is the name of the probe.
+	Name string `json:"name"`
+
+	// Healthy is false once the retry loop is persistently exhausting.
+	Healthy bool `json:"healthy"`
+
+	// Exhaustions is the number of exhaustions recorded within the window since
+	// the last success.
+	Exhaustions int `json:"exhaustions"`
+
+	// LastSuccess is the time of the last success, or the zero time if none was
+	// recorded.
+	LastSuccess time.Time `json:"lastSuccess"`
+}
+
+// HealthProbe turns the outcomes of a retry loop into a health check status. It
+// is degraded while threshold exhaustions have been recorded within the window
+// since the last success, which indicates the loop is persistently giving up.
+//
+// Feed it with [WithHealthProbe], or by calling [HealthProbe.RecordExhaustion]
+// from a [WithStopHook] and [HealthProbe.RecordSuccess] after successes.
+//
+// It is safe for concurrent use.
+type HealthProbe struct {
+	name      string
+	window    time.Duration
+	threshold int
+	now       func() time.Time
+
+	lock        sync.Mutex
+	exhaustions []time.Time
+	lastSuccess time.Time
+}
+
+// HealthProbeOption is an option that configures a [HealthProbe].
+type HealthProbeOption func(p *HealthProbe)
+
+// WithHealthNowFunc sets the function used by the probe to read the current
+// time. It defaults to [time.Now] and is primarily useful for driving time in
+// tests.
+func WithHealthNowFunc(now func() time.Time) HealthProbeOption {
+	return func(p *HealthProbe) {
+		if now != nil {
+			p.now = now
+		}
+	}
+}
+
+// NewHealthProbe creates a new health probe with the given name that is
+// degraded once threshold exhaustions are recorded within failureWindow. A
+// threshold less than 1 is treated as 1.
+func NewHealthProbe(name string, failureWindow time.Duration, threshold int, opts ...HealthProbeOption) *HealthProbe {
+	p := &HealthProbe{
+		name:      name,
+		window:    failureWindow,
+		threshold: max(threshold, 1),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// RecordExhaustion records that the retry loop gave up.
+func (p *HealthProbe) RecordExhaustion() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	p.prune(now)
+	p.exhaustions = append(p.exhaustions, now)
+}
+
+// RecordSuccess records that the retry loop succeeded, which clears the
+// exhaustions recorded before it.
+func (p *HealthProbe) RecordSuccess() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.lastSuccess = p.now()
+	p.exhaustions = p.exhaustions[:0]
+}
+
+// Healthy reports whether fewer than threshold exhaustions were recorded within
+// the window since the last success.
+func (p *HealthProbe) Healthy() bool {
+	return p.Status().Healthy
+}
+
+// Status returns the current status of the probe.
+func (p *HealthProbe) Status() HealthStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.prune(p.now())
+	return HealthStatus{
+		Name:        p.name,
+		Healthy:     len(p.exhaustions) < p.threshold,
+		Exhaustions: len(p.exhaustions),
+		LastSuccess: p.lastSuccess,
+	}
+}
+
+// prune removes exhaustions older than the window.
+func (p *HealthProbe) prune(now time.Time) {
+	i := 0
+	for i < len(p.exhaustions) && now.Sub(p.exhaustions[i]) >= p.window {
+		i++
+	}
+	if i > 0 {
+		p.exhaustions = append(p.exhaustions[:0], p.exhaustions[i:]...)
+	}
+}
+
+// WithHealthProbe feeds the outcomes of [Do] or [DoValue] to p: giving up, as
+// reported to [WithStopHook], records an exhaustion, and a successful attempt
+// records a success.
+func WithHealthProbe(p *HealthProbe) DoOption {
+	return func(c *doConfig) {
+		WithStopHook(func(StopReason, error) {
+			p.RecordExhaustion()
+		})(c)
+		WithOutcomeObserver(func(o Outcome) {
+			if o.Err == nil {
+				p.RecordSuccess()
+			}
+		})(c)
+	}
+}
@@ -0,0 +1,207 @@
+package retry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestHealthProbe(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	p := retry.NewHealthProbe("db", time.Minute, 3, retry.WithHealthNowFunc(clock.Now))
+
+	if !p.Healthy() {
+		t.Errorf("expected a new probe to be healthy")
+	}
+
+	// A burst below the threshold stays healthy; reaching it degrades.
+	p.RecordExhaustion()
+	p.RecordExhaustion()
+	if !p.Healthy() {
+		t.Errorf("expected probe to be healthy below the threshold")
+	}
+	p.RecordExhaustion()
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded at the threshold")
+	}
+	if got, want := p.Status().Exhaustions, 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	// Recovery once the burst leaves the window.
+	clock.Advance(time.Minute)
+	if !p.Healthy() {
+		t.Errorf("expected probe to recover after the window")
+	}
+	if got, want := p.Status().Exhaustions, 0; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	// Exhaustions spread over the window are pruned one at a time.
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			clock.Advance(20 * time.Second)
+		}
+		p.RecordExhaustion()
+	}
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded within the window")
+	}
+	clock.Advance(20 * time.Second)
+	if !p.Healthy() {
+		t.Errorf("expected probe to recover once the oldest exhaustion expires")
+	}
+
+	// Recovery on success.
+	p.RecordExhaustion()
+	p.RecordExhaustion()
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded")
+	}
+	p.RecordSuccess()
+	status := p.Status()
+	if !status.Healthy {
+		t.Errorf("expected probe to recover on success")
+	}
+	if got, want := status.LastSuccess, clock.Now(); !got.Equal(want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestHealthProbe_threshold(t *testing.T) {
+	t.Parallel()
+
+	// A threshold below 1 degrades on the first exhaustion.
+	p := retry.NewHealthProbe("db", time.Minute, 0)
+	if !p.Healthy() {
+		t.Errorf("expected a new probe to be healthy")
+	}
+	p.RecordExhaustion()
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded")
+	}
+}
+
+func TestHealthProbe_status(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+	p := retry.NewHealthProbe("db", time.Minute, 1, retry.WithHealthNowFunc(clock.Now))
+	p.RecordSuccess()
+	clock.Advance(time.Second)
+	p.RecordExhaustion()
+
+	b, err := json.Marshal(p.Status())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got retry.HealthStatus
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := retry.HealthStatus{
+		Name:        "db",
+		Healthy:     false,
+		Exhaustions: 1,
+		LastSuccess: clock.Now().Add(-time.Second),
+	}
+	if got.Name != want.Name || got.Healthy != want.Healthy ||
+		got.Exhaustions != want.Exhaustions || !got.LastSuccess.Equal(want.LastSuccess) {
+		t.Errorf("expected %+v to be %+v", got, want)
+	}
+}
+
+func TestHealthProbe_concurrent(t *testing.T) {
+	t.Parallel()
+
+	p := retry.NewHealthProbe("db", time.Hour, 1000)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				p.RecordExhaustion()
+				_ = p.Status()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := p.Status().Exhaustions, 1000; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded")
+	}
+}
+
+func TestWithHealthProbe(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := newFakeClock()
+	p := retry.NewHealthProbe("db", time.Minute, 2, retry.WithHealthNowFunc(clock.Now))
+	b := func() retry.Backoff {
+		return retry.WithMaxRetries(2, retry.NewConstant(time.Second))
+	}
+	fail := func(_ context.Context) error {
+		return retry.RetryableError(errors.New("oops"))
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := retry.Do(ctx, b(), fail, retry.WithHealthProbe(p), retry.WithClock(clock)); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if got, want := p.Status().Exhaustions, 2; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if p.Healthy() {
+		t.Errorf("expected probe to be degraded")
+	}
+
+	// A permanent error is not an exhaustion.
+	if err := retry.Do(ctx, b(), func(_ context.Context) error {
+		return errors.New("permanent")
+	}, retry.WithHealthProbe(p), retry.WithClock(clock)); err == nil {
+		t.Fatal("expected error")
+	}
+	if got, want := p.Status().Exhaustions, 2; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	if err := retry.Do(ctx, b(), func(_ context.Context) error {
+		return nil
+	}, retry.WithHealthProbe(p), retry.WithClock(clock)); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Healthy() {
+		t.Errorf("expected probe to recover on success")
+	}
+}
+
+func ExampleNewHealthProbe() {
+	probe := retry.NewHealthProbe("database", 5*time.Minute, 3)
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(2, retry.NewConstant(time.Millisecond))
+	_ = retry.Do(ctx, b, func(_ context.Context) error {
+		return nil
+	}, retry.WithHealthProbe(probe))
+
+	// Serve probe.Status() from a health endpoint.
+	status := probe.Status()
+	fmt.Println(status.Name, status.Healthy)
+	// Output: database true
+}
@@ -0,0 +1,85 @@
+package retry
+
+import "errors"
+
+// WithErrorHistory retains the errors of the most recent max attempts, so that
+// callers can inspect every failure with [AttemptErrors], not just the last.
+// Older errors are dropped, which bounds memory when retrying indefinitely.
+// Errors are compacted with the function set by [WithErrorCompaction], if any.
+//
+// When [Do] or [DoValue] fails after making an attempt, the returned error
+// carries the history. Its message and its [errors.Is] and [errors.As]
+// behavior are those of the final error, so existing checks are unaffected. A
+// value of max less than or equal to zero disables the history.
+func WithErrorHistory(max int) DoOption {
+	return func(c *doConfig) {
+		c.historyMax = max
+	}
+}
+
+// AttemptErrors returns the errors of the attempts that preceded err, oldest
+// first, if err was returned by a call that used [WithErrorHistory]. Errors
+// wrapped with [RetryableError] are unwrapped. If more attempts failed than the
+// history retains, only the most recent are returned. It returns nil if err
+// carries no history.
+func AttemptErrors(err error) []error {
+	var herr *historyError
+	if !errors.As(err, &herr) {
+		return nil
+	}
+
+	errs := make([]error, len(herr.errs))
+	copy(errs, herr.errs)
+	return errs
+}
+
+// errorHistory is a ring of the most recent attempt errors of a single call.
+type errorHistory struct {
+	max   int
+	errs  []error
+	start int
+}
+
+// add records err, dropping the oldest error if the history is full.
+func (h *errorHistory) add(err error) {
+	if rerr, ok := err.(*retryableError); ok {
+		err = rerr.err
+	}
+
+	if len(h.errs) < h.max {
+		h.errs = append(h.errs, err)
+		return
+	}
+	h.errs[h.start] = err
+	h.start = (h.start + 1) % h.max
+}
+
+// wrap returns err carrying the recorded errors, or err unchanged if it is nil
+// or no errors were recorded.
+func (h *errorHistory) wrap(err error) error {
+	if err == nil || len(h.errs) == 0 {
+		return err
+	}
+
+	errs := make([]error, 0, len(h.errs))
+	errs = append(errs, h.errs[h.start:]...)
+	errs = append(errs, h.errs[:h.start]...)
+	return &historyError{err: err, errs: errs}
+}
+
+// historyError is the final error of a call, carrying the errors of its
+// attempts.
+type historyError struct {
+	err  error
+	errs []error
+}
+
+// Error returns the message of the final error.
+func (e *historyError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the final error.
+func (e *historyError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,165 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithErrorHistory(t *testing.T) {
+	t.Parallel()
+
+	errTimeout := errors.New("timeout")
+	err503 := errors.New("503")
+
+	t.Run("collects", func(t *testing.T) {
+		t.Parallel()
+
+		errs := []error{errTimeout, err503, io.EOF}
+		var i int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			err := errs[i]
+			i++
+			return retry.RetryableError(err)
+		}, retry.WithErrorHistory(10))
+
+		if got, want := retry.AttemptErrors(err), errs; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The final error behaves as before.
+		if got, want := err.Error(), io.EOF.Error(); got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+		if errors.Is(err, errTimeout) {
+			t.Errorf("expected %v not to be %v", err, errTimeout)
+		}
+	})
+
+	t.Run("as_final", func(t *testing.T) {
+		t.Parallel()
+
+		opErr := &net.OpError{Op: "dial", Err: io.EOF}
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return opErr
+		}, retry.WithErrorHistory(10))
+
+		var got *net.OpError
+		if !errors.As(err, &got) || got != opErr {
+			t.Errorf("expected %v to be %v", got, opErr)
+		}
+		if got, want := len(retry.AttemptErrors(err)), 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		t.Parallel()
+
+		var i int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(99, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			i++
+			return retry.RetryableError(fmt.Errorf("attempt %d", i))
+		}, retry.WithErrorHistory(3))
+
+		var got []string
+		for _, err := range retry.AttemptErrors(err) {
+			got = append(got, err.Error())
+		}
+		if want := []string{"attempt 98", "attempt 99", "attempt 100"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var i int
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			i++
+			if i == 2 {
+				cancel()
+			}
+			return retry.RetryableError(errTimeout)
+		}, retry.WithErrorHistory(10))
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if got, want := retry.AttemptErrors(err), []error{errTimeout, errTimeout}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		var i int
+		if err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			i++
+			if i < 3 {
+				return retry.RetryableError(errTimeout)
+			}
+			return nil
+		}, retry.WithErrorHistory(10)); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+	})
+
+	t.Run("compacted", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(errors.New(strings.Repeat("x", 1<<20)))
+		}, retry.WithErrorHistory(10), retry.WithErrorCompaction(retry.NewErrorCompactor(16)))
+
+		for i, err := range retry.AttemptErrors(err) {
+			if n, max := len(err.Error()), 64; n > max {
+				t.Errorf("%d: expected %d to be at most %d", i, n, max)
+			}
+		}
+	})
+
+	t.Run("no_history", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(errTimeout)
+		})
+		if got := retry.AttemptErrors(err); got != nil {
+			t.Errorf("expected %v to be nil", got)
+		}
+	})
+}
+
+func ExampleAttemptErrors() {
+	ctx := context.Background()
+
+	var i int
+	err := retry.Do(ctx, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+		i++
+		return retry.RetryableError(fmt.Errorf("attempt %d failed", i))
+	}, retry.WithErrorHistory(10))
+
+	for _, err := range retry.AttemptErrors(err) {
+		fmt.Println(err)
+	}
+	// Output:
+	// attempt 1 failed
+	// attempt 2 failed
+	// attempt 3 failed
+}
@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Hooks are functions called by [Do] and [DoValue] as they retry, such as to
+// emit metrics. Hooks observe the retry loop but cannot change its control
+// flow. Nil hooks are skipped.
+//
+// Each attempt that fails with a retryable error is followed by exactly one
+// of OnRetry, a stop hook registered with [WithStopHook], or OnCancel, even
+// if the context is canceled concurrently. After OnRetry, the call may still
+// end before the next attempt starts, which is reported once, with a stop
+// hook or with OnCancel for the attempt that was not made. Hooks are called
+// from the goroutine running the loop, in the order of the attempts.
+type Hooks struct {
+	// OnRetry is called after each failed attempt that will be retried, before
+	// sleeping, with the attempt number starting at 1, the delay that will be
+	// slept, and the unwrapped error. It is not called after the final attempt,
+	// such as when the backoff stops.
+	OnRetry func(attempt uint64, delay time.Duration, err error)
+
+	// OnCancel is called like a hook registered with [WithCancelHook].
+	OnCancel func(attempt uint64, phase CancelPhase, err error)
+
+	// OnDegradedSuccess is called like a hook registered with
+	// [WithDegradedSuccessHook].
+	OnDegradedSuccess func(ctx context.Context, attempts uint64, elapsed time.Duration)
+}
+
+// WithHooks registers the given hooks. Hooks from multiple calls are all
+// called, in the order they were given.
+func WithHooks(h Hooks) DoOption {
+	return func(c *doConfig) {
+		if h.OnRetry != nil {
+			c.onRetry = append(c.onRetry, h.OnRetry)
+		}
+		if h.OnCancel != nil {
+			c.onCancel = append(c.onCancel, h.OnCancel)
+		}
+		if h.OnDegradedSuccess != nil {
+			c.onDegraded = append(c.onDegraded, h.OnDegradedSuccess)
+		}
+	}
+}
+
+// CancelPhase describes where in the retry loop [Do] or [DoValue] found the
+// context done.
+type CancelPhase int
+
+const (
+	// CancelAfterAttempt indicates the context was done when the attempt
+	// returned, before a retry was scheduled.
+	CancelAfterAttempt CancelPhase = iota + 1
+
+	// CancelBeforeAttempt indicates the context was done before the attempt
+	// started, such as while waiting after the previous attempt.
+	CancelBeforeAttempt
+)
+
+// String returns the name of the phase.
+func (p CancelPhase) String() string {
+	switch p {
+	case CancelAfterAttempt:
+		return "after_attempt"
+	case CancelBeforeAttempt:
+		return "before_attempt"
+	default:
+		return "unknown"
+	}
+}
+
+// WithCancelHook registers a function that is called once when [Do] or
+// [DoValue] returns because the context is done. It receives the number of the
+// attempt that was made or about to be made, starting at 1, where the
+// cancellation was found, and the error that will be returned. It is not
+// called after a stop hook registered with [WithStopHook], or when the context
+// is done only after retrying ended. Multiple hooks are called in the order
+// they were given.
+func WithCancelHook(fn func(attempt uint64, phase CancelPhase, err error)) DoOption {
+	return func(c *doConfig) {
+		c.onCancel = append(c.onCancel, fn)
+	}
+}
+
+// WithDegradedSuccessHook registers a function that is called when [Do] or
+// [DoValue] succeeds only after retrying, which can be an early sign of an
+// outage. It receives the caller's context, the number of attempts made, and
+// the time elapsed since the first attempt started. It is not called when the
+// first attempt succeeds or when retrying fails. Multiple hooks are called in
+// the order they were given.
+func WithDegradedSuccessHook(fn func(ctx context.Context, attempts uint64, elapsed time.Duration)) DoOption {
+	return func(c *doConfig) {
+		c.onDegraded = append(c.onDegraded, fn)
+	}
+}
+
+// DoWithHooks wraps a function with a backoff to retry, like [Do], calling the
+// given hooks as it retries.
+func DoWithHooks(ctx context.Context, b Backoff, f RetryFunc, h Hooks, opts ...DoOption) error {
+	return Do(ctx, b, f, appendOptions(opts, WithHooks(h))...)
+}
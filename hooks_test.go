@@ -0,0 +1,409 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithHooks(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	chain := func() retry.Backoff {
+		return retry.WithMaxRetries(3, retry.WithJitter(5*time.Millisecond,
+			retry.NewExponential(10*time.Millisecond), retry.WithRandSeed(1)))
+	}
+
+	// An identical chain produces the delays the hook must see.
+	var want []time.Duration
+	expected := chain()
+	for {
+		val, stop := expected.Next()
+		if stop {
+			break
+		}
+		want = append(want, val)
+	}
+
+	var attempts []uint64
+	var delays []time.Duration
+	var errs []error
+	err := retry.DoWithHooks(context.Background(), chain(), func(_ context.Context) error {
+		return retry.RetryableError(errOops)
+	}, retry.Hooks{
+		OnRetry: func(attempt uint64, delay time.Duration, err error) {
+			attempts = append(attempts, attempt)
+			delays = append(delays, delay)
+			errs = append(errs, err)
+		},
+	}, retry.WithClock(newFakeClock()))
+	if got, want := err, errOops; !errors.Is(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	// The final attempt, after which the backoff stops, is not reported.
+	if got, want := fmt.Sprint(attempts), "[1 2 3]"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if !reflect.DeepEqual(delays, want) {
+		t.Errorf("expected %v to be %v", delays, want)
+	}
+	for _, err := range errs {
+		if got, want := err, errOops; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}
+}
+
+func TestWithHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success_and_permanent", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		hooks := retry.WithHooks(retry.Hooks{
+			OnRetry: func(uint64, time.Duration, error) {
+				calls++
+			},
+		})
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+		if err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return nil
+		}, hooks); err != nil {
+			t.Fatal(err)
+		}
+		if err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return errors.New("permanent")
+		}, hooks); err == nil {
+			t.Fatal("expected error")
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			return retry.RetryableError(errors.New("oops"))
+		}, retry.WithHooks(retry.Hooks{}))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestWithDegradedSuccessHook(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	type call struct {
+		attempts uint64
+		elapsed  time.Duration
+		value    any
+	}
+
+	type key struct{}
+
+	cases := []struct {
+		name  string
+		b     retry.Backoff
+		fails int
+		err   error
+		calls []call
+	}{
+		{
+			name:  "first_try",
+			b:     retry.NewConstant(1 * time.Second),
+			fails: 0,
+		},
+		{
+			name:  "after_retries",
+			b:     retry.NewConstant(1 * time.Second),
+			fails: 2,
+			// Three attempts of 100ms and two sleeps of 1s.
+			calls: []call{{attempts: 3, elapsed: 2300 * time.Millisecond, value: "v"}},
+		},
+		{
+			name:  "exhausted",
+			b:     retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)),
+			fails: 5,
+			err:   errOops,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := newFakeClock()
+			ctx := context.WithValue(context.Background(), key{}, "v")
+
+			var calls []call
+			var attempts int
+			err := retry.Do(ctx, tc.b, func(_ context.Context) error {
+				attempts++
+				clock.Advance(100 * time.Millisecond)
+				if attempts <= tc.fails {
+					return retry.RetryableError(errOops)
+				}
+				return nil
+			}, retry.WithClock(clock), retry.WithDegradedSuccessHook(func(ctx context.Context, attempts uint64, elapsed time.Duration) {
+				calls = append(calls, call{attempts: attempts, elapsed: elapsed, value: ctx.Value(key{})})
+			}))
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %v to be %v", err, tc.err)
+			}
+			if !reflect.DeepEqual(calls, tc.calls) {
+				t.Errorf("expected %+v to be %+v", calls, tc.calls)
+			}
+		})
+	}
+
+	t.Run("permanent", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		var attempts int
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			attempts++
+			if attempts < 2 {
+				return retry.RetryableError(errOops)
+			}
+			return errOops
+		}, retry.WithDegradedSuccessHook(func(context.Context, uint64, time.Duration) {
+			calls++
+		}))
+		if got, want := err, errOops; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("hooks", func(t *testing.T) {
+		t.Parallel()
+
+		var got []uint64
+		var attempts int
+		if err := retry.DoWithHooks(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			attempts++
+			if attempts < 4 {
+				return retry.RetryableError(errOops)
+			}
+			return nil
+		}, retry.Hooks{
+			OnDegradedSuccess: func(_ context.Context, attempts uint64, _ time.Duration) {
+				got = append(got, attempts)
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fmt.Sprint(got), "[4]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithCancelHook(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	type cancelCall struct {
+		attempt uint64
+		phase   retry.CancelPhase
+	}
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+
+		// cancel is the attempt that cancels the context before returning, or 0
+		// for OnRetry to cancel it, or -1 to cancel before calling Do.
+		cancel int
+
+		calls   int
+		retries int
+		stops   int
+		want    []cancelCall
+	}{
+		{
+			name:   "after_attempt",
+			b:      retry.NewConstant(1 * time.Nanosecond),
+			cancel: 2,
+			calls:  2, retries: 1,
+			want: []cancelCall{{attempt: 2, phase: retry.CancelAfterAttempt}},
+		},
+		{
+			name:   "before_attempt",
+			b:      retry.NewConstant(1 * time.Hour),
+			cancel: 0,
+			calls:  1, retries: 1,
+			want: []cancelCall{{attempt: 2, phase: retry.CancelBeforeAttempt}},
+		},
+		{
+			name:   "before_first",
+			b:      retry.NewConstant(1 * time.Nanosecond),
+			cancel: -1,
+			want:   []cancelCall{{attempt: 1, phase: retry.CancelBeforeAttempt}},
+		},
+		{
+			name:   "after_stop",
+			b:      retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)),
+			cancel: 2,
+			calls:  2, retries: 1, stops: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel < 0 {
+				cancel()
+			}
+
+			var calls, retries, stops int
+			var got []cancelCall
+			err := retry.Do(ctx, tc.b, func(_ context.Context) error {
+				calls++
+				if calls == tc.cancel {
+					cancel()
+				}
+				return retry.RetryableError(errOops)
+			}, retry.WithHooks(retry.Hooks{
+				OnRetry: func(uint64, time.Duration, error) {
+					retries++
+					if tc.cancel == 0 {
+						cancel()
+					}
+				},
+				OnCancel: func(attempt uint64, phase retry.CancelPhase, err error) {
+					if !errors.Is(err, context.Canceled) {
+						t.Errorf("expected %v to be %v", err, context.Canceled)
+					}
+					got = append(got, cancelCall{attempt: attempt, phase: phase})
+				},
+			}), retry.WithStopHook(func(retry.StopReason, error) {
+				stops++
+			}))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := retries, tc.retries; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := stops, tc.stops; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %+v to be %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestHooks_exactlyOnce cancels the context at random times while retrying,
+// and checks that every failed attempt is followed by exactly one of OnRetry,
+// a stop hook, or OnCancel, in order. Run it with -race.
+func TestHooks_exactlyOnce(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	iterations := 10_000
+	if testing.Short() {
+		iterations = 1_000
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < iterations; i++ {
+		retries := uint64(r.Intn(5))
+		delay := time.Duration(1+r.Intn(50)) * time.Microsecond
+		cancelAfter := time.Duration(r.Intn(200)) * time.Microsecond
+		successAt := r.Intn(8)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		timer := time.AfterFunc(cancelAfter, cancel)
+
+		// events are recorded by the goroutine running the loop, and read once
+		// Do has returned.
+		var events []string
+		var calls, outcomes int
+		err := retry.Do(ctx, retry.WithMaxRetries(retries, retry.NewConstant(delay)), func(_ context.Context) error {
+			calls++
+			if calls == successAt {
+				return nil
+			}
+			return retry.RetryableError(errOops)
+		}, retry.WithOutcomeObserver(func(retry.Outcome) {
+			outcomes++
+		}), retry.WithHooks(retry.Hooks{
+			OnRetry: func(attempt uint64, _ time.Duration, _ error) {
+				events = append(events, fmt.Sprintf("retry %d", attempt))
+			},
+			OnCancel: func(attempt uint64, phase retry.CancelPhase, _ error) {
+				events = append(events, fmt.Sprintf("cancel %d %s", attempt, phase))
+			},
+		}), retry.WithStopHook(func(retry.StopReason, error) {
+			events = append(events, fmt.Sprintf("stop %d", calls))
+		}))
+		timer.Stop()
+		cancel()
+
+		if outcomes != calls {
+			t.Fatalf("iteration %d: expected %d outcomes, got %d", i, calls, outcomes)
+		}
+
+		// One OnRetry for every attempt but the last, unless the context was
+		// canceled while waiting after it, followed by one final event unless
+		// the call succeeded.
+		var want []string
+		for a := 1; a < calls; a++ {
+			want = append(want, fmt.Sprintf("retry %d", a))
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, retry.ErrExhausted):
+			want = append(want, fmt.Sprintf("stop %d", calls))
+		case errors.Is(err, context.Canceled):
+			if len(events) > 0 && events[len(events)-1] == fmt.Sprintf("cancel %d before_attempt", calls+1) {
+				want = append(want, fmt.Sprintf("retry %d", calls), fmt.Sprintf("cancel %d before_attempt", calls+1))
+			} else if calls == 0 {
+				want = append(want, "cancel 1 before_attempt")
+			} else {
+				want = append(want, fmt.Sprintf("cancel %d after_attempt", calls))
+			}
+		default:
+			t.Fatalf("iteration %d: unexpected error %v", i, err)
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Fatalf("iteration %d: expected %q to be %q", i, events, want)
+		}
+	}
+}
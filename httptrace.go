@@ -0,0 +1,140 @@
+package retry
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// HTTPTraceInfo holds the timings of the HTTP requests made during a single
+// attempt, collected with [net/http/httptrace]. If an attempt makes more than
+// one request, the timings of the first are reported. A phase that did not
+// happen, such as DNS resolution for an IP address or dialing for a reused
+// connection, has a zero duration.
+type HTTPTraceInfo struct {
+	// DNS is the time spent resolving the host name.
+	DNS time.Duration
+
+	// Connect is the time spent dialing the connection.
+	Connect time.Duration
+
+	// TLSHandshake is the time spent on the TLS handshake.
+	TLSHandshake time.Duration
+
+	// TimeToFirstByte is the time from requesting a connection until the first
+	// byte of the response headers was received.
+	TimeToFirstByte time.Duration
+
+	// Reused is true if the request used a connection from the pool.
+	Reused bool
+
+	// RemoteAddr is the address of the server, if a connection was obtained.
+	RemoteAddr string
+}
+
+// WithHTTPTrace installs an [httptrace.ClientTrace] on the context of each
+// attempt and calls collect with the attempt number, starting at 1, and the
+// timings once the attempt completes, before sleeping. This shows why a
+// particular attempt was slow. Any trace already on the caller's context is
+// still called.
+func WithHTTPTrace(collect func(attempt uint64, info HTTPTraceInfo)) DoOption {
+	return func(c *doConfig) {
+		c.attemptHooks = append(c.attemptHooks, func(ctx context.Context, attempt uint64) (context.Context, func()) {
+			t := new(httpTracer)
+			return httptrace.WithClientTrace(ctx, t.clientTrace()), func() {
+				collect(attempt, t.info())
+			}
+		})
+	}
+}
+
+// httpTracer records the timings of the first request traced with it. Trace
+// hooks may be called from multiple goroutines.
+type httpTracer struct {
+	lock sync.Mutex
+	data HTTPTraceInfo
+
+	getConn      time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	gotFirstByte bool
+}
+
+func (t *httpTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.getConn.IsZero() {
+				t.getConn = time.Now()
+			}
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.data.RemoteAddr == "" && info.Conn != nil {
+				t.data.Reused = info.Reused
+				t.data.RemoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.dnsStart.IsZero() {
+				t.dnsStart = time.Now()
+			}
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.data.DNS == 0 && !t.dnsStart.IsZero() {
+				t.data.DNS = time.Since(t.dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(string, string, error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.data.Connect == 0 && !t.connectStart.IsZero() {
+				t.data.Connect = time.Since(t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.tlsStart.IsZero() {
+				t.tlsStart = time.Now()
+			}
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.data.TLSHandshake == 0 && !t.tlsStart.IsZero() {
+				t.data.TLSHandshake = time.Since(t.tlsStart)
+			}
+		},
+		GotFirstResponseByte: func() {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if !t.gotFirstByte && !t.getConn.IsZero() {
+				t.gotFirstByte = true
+				t.data.TimeToFirstByte = time.Since(t.getConn)
+			}
+		},
+	}
+}
+
+func (t *httpTracer) info() HTTPTraceInfo {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.data
+}
@@ -0,0 +1,88 @@
+package retry_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithHTTPTrace(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+
+	// Disable keep-alives so every attempt dials a new connection.
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	var userConns int
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			userConns++
+		},
+	})
+
+	var lock sync.Mutex
+	attempts := make(map[uint64]retry.HTTPTraceInfo)
+	collect := func(attempt uint64, info retry.HTTPTraceInfo) {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts[attempt] = info
+	}
+
+	b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+	if err := retry.Do(ctx, b, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return retry.RetryableError(http.ErrHandlerTimeout)
+	}, retry.WithHTTPTrace(collect)); err == nil {
+		t.Fatal("expected err")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if got, want := len(attempts), 3; got != want {
+		t.Fatalf("expected %v to be %v", got, want)
+	}
+	for attempt := uint64(1); attempt <= 3; attempt++ {
+		info, ok := attempts[attempt]
+		if !ok {
+			t.Errorf("attempt %d: not collected", attempt)
+			continue
+		}
+		if info.Connect <= 0 {
+			t.Errorf("attempt %d: expected connect %v to be positive", attempt, info.Connect)
+		}
+		if info.TimeToFirstByte < time.Millisecond {
+			t.Errorf("attempt %d: expected time to first byte %v to be at least %v", attempt, info.TimeToFirstByte, time.Millisecond)
+		}
+		if info.Reused {
+			t.Errorf("attempt %d: expected connection to be new", attempt)
+		}
+		if got, want := info.RemoteAddr, srv.Listener.Addr().String(); got != want {
+			t.Errorf("attempt %d: expected %q to be %q", attempt, got, want)
+		}
+	}
+
+	// The caller's trace is still called.
+	if got, want := userConns, 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
@@ -0,0 +1,172 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotLeader is wrapped around the error returned by [Do] and [DoValue] when
+// retrying stopped because a backoff from [WithLeaderOnly] did not acquire its
+// lock.
+var ErrNotLeader = errors.New("retry: not leader")
+
+// TryLocker is a lock that is acquired without blocking. It is typically shared
+// by replicas of a process, backed by a lease in Redis or etcd or by a lock
+// file.
+type TryLocker interface {
+	// TryLock attempts to acquire the lock. If ok is true, the lock is held until
+	// release is called.
+	TryLock(ctx context.Context) (ok bool, release func())
+}
+
+// NewLocalLocker returns a [TryLocker] that is held by at most one caller in the
+// current process. It is intended for tests and single-binary deployments.
+func NewLocalLocker() TryLocker {
+	return new(localLocker)
+}
+
+type localLocker struct {
+	lock sync.Mutex
+}
+
+// TryLock implements TryLocker.
+func (l *localLocker) TryLock(ctx context.Context) (bool, func()) {
+	if ctx.Err() != nil || !l.lock.TryLock() {
+		return false, nil
+	}
+
+	var once sync.Once
+	return true, func() {
+		once.Do(l.lock.Unlock)
+	}
+}
+
+// WithLeaderOnly allows only the holder of lock to retry. Every caller makes
+// its first attempt, but before the first retry the backoff tries to acquire
+// the lock. If it is held elsewhere, the backoff stops with the reason
+// [ReasonNotLeader] and [Do] returns the last error wrapped with [ErrNotLeader],
+// so the caller can fall back to waiting for the leader. Otherwise, the lock is
+// held until next stops or the retry loop returns, and the delays of next are
+// used.
+//
+// The lock is acquired with a background context. Any middleware around it
+// must implement [Wrapper] so that [Do] can release the lock and report the
+// reason.
+//
+// Each returned backoff tracks its own leadership, so a new one must be built
+// for every retry loop, while the lock is shared. It panics if lock or next is
+// nil. It is safe for concurrent use if next is safe for concurrent use.
+func WithLeaderOnly(lock TryLocker, next Backoff) Backoff {
+	return must(WithLeaderOnlyE(lock, next))
+}
+
+// WithLeaderOnlyE is like [WithLeaderOnly], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithLeaderOnlyE(lock TryLocker, next Backoff) (Backoff, error) {
+	if lock == nil {
+		return nil, &ValidationError{Field: "lock", Reason: "must not be nil"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &leaderBackoff{
+		lock: lock,
+		next: next,
+	}, nil
+}
+
+type leaderState int
+
+const (
+	leaderUndecided leaderState = iota
+	leaderHeld
+	leaderDenied
+	leaderReleased
+)
+
+type leaderBackoff struct {
+	lock TryLocker
+	next Backoff
+
+	mu      sync.Mutex
+	state   leaderState
+	release func()
+}
+
+// Next implements Backoff.
+func (b *leaderBackoff) Next() (time.Duration, bool) {
+	b.mu.Lock()
+	if b.state == leaderUndecided {
+		ok, release := b.lock.TryLock(context.Background())
+		if ok {
+			b.state, b.release = leaderHeld, release
+		} else {
+			b.state = leaderDenied
+		}
+	}
+	state := b.state
+	b.mu.Unlock()
+
+	if state != leaderHeld {
+		return 0, true
+	}
+
+	next, stop := b.next.Next()
+	if stop {
+		b.releaseLock()
+	}
+	return next, stop
+}
+
+// StopReason implements StopReasoner.
+func (b *leaderBackoff) StopReason() StopReason {
+	b.mu.Lock()
+	state := b.state
+	b.mu.Unlock()
+
+	if state == leaderDenied {
+		return ReasonNotLeader
+	}
+	if sr, ok := b.next.(StopReasoner); ok {
+		return sr.StopReason()
+	}
+	return ReasonNone
+}
+
+// Unwrap implements Wrapper.
+func (b *leaderBackoff) Unwrap() Backoff {
+	return b.next
+}
+
+// releaseLock releases the lock if it is held.
+func (b *leaderBackoff) releaseLock() {
+	b.mu.Lock()
+	release := b.release
+	if b.state == leaderHeld {
+		b.state, b.release = leaderReleased, nil
+	}
+	b.mu.Unlock()
+
+	if release != nil {
+		release()
+	}
+}
+
+// endCall implements callEnder by releasing the lock.
+func (b *leaderBackoff) endCall() {
+	b.releaseLock()
+}
+
+// releaseLocks releases any lock held by a backoff from WithLeaderOnly in the
+// chain b.
+func releaseLocks(b Backoff) {
+	Walk(b, func(node Backoff) bool {
+		if l, ok := node.(*leaderBackoff); ok {
+			l.releaseLock()
+		}
+		return true
+	})
+}
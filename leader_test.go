@@ -0,0 +1,153 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithLeaderOnly(t *testing.T) {
+	t.Parallel()
+
+	t.Run("one_leader", func(t *testing.T) {
+		t.Parallel()
+
+		lock := retry.NewLocalLocker()
+
+		// Both replicas fail their first attempt before either retries, so they
+		// compete for the lock.
+		var ready sync.WaitGroup
+		ready.Add(2)
+
+		var retries [2]atomic.Int64
+		var errs [2]error
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				b := retry.WithLeaderOnly(lock, retry.WithMaxRetries(3, retry.NewConstant(1*time.Millisecond)))
+				var attempts int
+				errs[i] = retry.Do(context.Background(), b, func(_ context.Context) error {
+					attempts++
+					if attempts == 1 {
+						ready.Done()
+						ready.Wait()
+					} else {
+						retries[i].Add(1)
+					}
+					return retry.RetryableError(io.EOF)
+				})
+			}()
+		}
+		wg.Wait()
+
+		leaders := 0
+		for i := 0; i < 2; i++ {
+			switch {
+			case errors.Is(errs[i], retry.ErrNotLeader):
+				if got, want := retries[i].Load(), int64(0); got != want {
+					t.Errorf("replica %d: expected %v to be %v", i, got, want)
+				}
+			default:
+				leaders++
+				if got, want := retries[i].Load(), int64(3); got != want {
+					t.Errorf("replica %d: expected %v to be %v", i, got, want)
+				}
+			}
+			if !errors.Is(errs[i], io.EOF) {
+				t.Errorf("replica %d: expected %v to be %v", i, errs[i], io.EOF)
+			}
+		}
+		if got, want := leaders, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The leader released the lock when it stopped.
+		ok, release := lock.TryLock(context.Background())
+		if !ok {
+			t.Fatal("expected lock to be released")
+		}
+		release()
+	})
+
+	t.Run("stop_reason", func(t *testing.T) {
+		t.Parallel()
+
+		lock := retry.NewLocalLocker()
+		ok, release := lock.TryLock(context.Background())
+		if !ok {
+			t.Fatal("expected lock")
+		}
+		defer release()
+
+		var reason retry.StopReason
+		err := retry.Do(context.Background(), retry.WithLeaderOnly(lock, retry.NewConstant(1*time.Millisecond)), func(_ context.Context) error {
+			return retry.RetryableError(io.EOF)
+		}, retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+		if !errors.Is(err, retry.ErrNotLeader) {
+			t.Errorf("expected %v to be %v", err, retry.ErrNotLeader)
+		}
+		if got, want := reason, retry.ReasonNotLeader; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("released_on_success", func(t *testing.T) {
+		t.Parallel()
+
+		lock := retry.NewLocalLocker()
+
+		var attempts int
+		if err := retry.Do(context.Background(), retry.WithLeaderOnly(lock, retry.NewConstant(1*time.Millisecond)), func(_ context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return retry.RetryableError(io.EOF)
+			}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		ok, release := lock.TryLock(context.Background())
+		if !ok {
+			t.Fatal("expected lock to be released")
+		}
+		release()
+	})
+
+	t.Run("released_on_cancel", func(t *testing.T) {
+		t.Parallel()
+
+		lock := retry.NewLocalLocker()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var attempts int
+		err := retry.Do(ctx, retry.WithLeaderOnly(lock, retry.NewConstant(1*time.Millisecond)), func(_ context.Context) error {
+			attempts++
+			if attempts == 2 {
+				cancel()
+			}
+			return retry.RetryableError(io.EOF)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+
+		ok, release := lock.TryLock(context.Background())
+		if !ok {
+			t.Fatal("expected lock to be released")
+		}
+		release()
+	})
+}
@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLeaseExpiring is wrapped around the error returned by [Do] and [DoValue]
+// when retrying stopped because a backoff from [WithLease] had too little time
+// left on its lease.
+var ErrLeaseExpiring = errors.New("retry: lease expiring")
+
+var (
+	_ Backoff      = (*leaseBackoff)(nil)
+	_ StopReasoner = (*leaseBackoff)(nil)
+)
+
+// WithLease limits each delay to the time remaining on an external lease, such
+// as a database row lock, minus margin, which is reserved for the next attempt
+// and for releasing the lease. The remaining function is called on every Next,
+// so unlike [WithMaxDuration], the budget may grow when the lease is renewed.
+//
+// Delays longer than the time available are truncated to it. If no time is
+// available, because remaining returns margin or less, the backoff stops with
+// the reason [ReasonLeaseExpiring], and [Do] returns the last error wrapped
+// with [ErrLeaseExpiring].
+//
+// It panics if remaining or next is nil or margin is negative. It is safe for
+// concurrent use if remaining and next are safe for concurrent use.
+func WithLease(remaining func() time.Duration, margin time.Duration, next Backoff) Backoff {
+	return must(WithLeaseE(remaining, margin, next))
+}
+
+// WithLeaseE is like [WithLease], but returns an error instead of panicking if
+// the arguments are invalid.
+func WithLeaseE(remaining func() time.Duration, margin time.Duration, next Backoff) (Backoff, error) {
+	if remaining == nil {
+		return nil, &ValidationError{Field: "remaining", Reason: "must not be nil"}
+	}
+	if margin < 0 {
+		return nil, &ValidationError{Field: "margin", Reason: "must not be negative"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &leaseBackoff{
+		remaining: remaining,
+		margin:    margin,
+		next:      next,
+	}, nil
+}
+
+type leaseBackoff struct {
+	remaining func() time.Duration
+	margin    time.Duration
+	next      Backoff
+
+	reason atomic.Int32
+}
+
+// Next implements Backoff.
+func (b *leaseBackoff) Next() (time.Duration, bool) {
+	if StopReason(b.reason.Load()) != ReasonNone {
+		return 0, true
+	}
+
+	available := b.remaining() - b.margin
+	if available <= 0 {
+		return b.stop(ReasonLeaseExpiring)
+	}
+
+	val, stop := b.next.Next()
+	if stop {
+		return b.stop(stopReasonOf(b.next))
+	}
+
+	if val > available {
+		val = available
+	}
+	return val, false
+}
+
+// StopReason implements StopReasoner.
+func (b *leaseBackoff) StopReason() StopReason {
+	return StopReason(b.reason.Load())
+}
+
+func (b *leaseBackoff) stop(reason StopReason) (time.Duration, bool) {
+	b.reason.CompareAndSwap(int32(ReasonNone), int32(reason))
+	return 0, true
+}
+
+// Margin returns the configured margin.
+func (b *leaseBackoff) Margin() time.Duration {
+	return b.margin
+}
+
+// Unwrap implements Wrapper.
+func (b *leaseBackoff) Unwrap() Backoff {
+	return b.next
+}
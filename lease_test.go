@@ -0,0 +1,157 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// scriptedLease returns the remaining times in order, repeating the last.
+func scriptedLease(remaining ...time.Duration) func() time.Duration {
+	var lock sync.Mutex
+	return func() time.Duration {
+		lock.Lock()
+		defer lock.Unlock()
+
+		d := remaining[0]
+		if len(remaining) > 1 {
+			remaining = remaining[1:]
+		}
+		return d
+	}
+}
+
+func TestWithLease(t *testing.T) {
+	t.Parallel()
+
+	type step struct {
+		val  time.Duration
+		stop bool
+	}
+
+	cases := []struct {
+		name      string
+		remaining []time.Duration
+		margin    time.Duration
+		exp       []step
+		reason    retry.StopReason
+	}{
+		{
+			name:      "fits",
+			remaining: []time.Duration{10 * time.Second},
+			margin:    1 * time.Second,
+			exp: []step{
+				{1 * time.Second, false},
+				{2 * time.Second, false},
+				{4 * time.Second, false},
+			},
+		},
+		{
+			name:      "truncates",
+			remaining: []time.Duration{10 * time.Second, 6 * time.Second, 3 * time.Second},
+			margin:    1 * time.Second,
+			exp: []step{
+				{1 * time.Second, false},
+				{2 * time.Second, false},
+				{2 * time.Second, false},
+			},
+		},
+		{
+			name:      "renewal",
+			remaining: []time.Duration{5 * time.Second, 2 * time.Second, 30 * time.Second, 30 * time.Second},
+			margin:    1 * time.Second,
+			exp: []step{
+				{1 * time.Second, false},
+				{1 * time.Second, false},
+				{4 * time.Second, false},
+				{8 * time.Second, false},
+			},
+		},
+		{
+			name:      "shrinks_below_margin",
+			remaining: []time.Duration{30 * time.Second, 500 * time.Millisecond, 30 * time.Second},
+			margin:    1 * time.Second,
+			exp: []step{
+				{1 * time.Second, false},
+				{0, true},
+				{0, true},
+			},
+			reason: retry.ReasonLeaseExpiring,
+		},
+		{
+			name:      "equals_margin",
+			remaining: []time.Duration{1 * time.Second},
+			margin:    1 * time.Second,
+			exp: []step{
+				{0, true},
+			},
+			reason: retry.ReasonLeaseExpiring,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := retry.WithLease(scriptedLease(tc.remaining...), tc.margin, retry.NewExponential(1*time.Second))
+			for i, want := range tc.exp {
+				val, stop := b.Next()
+				if got := (step{val, stop}); got != want {
+					t.Errorf("%d: expected %v to be %v", i, got, want)
+				}
+			}
+
+			if got, want := b.(retry.StopReasoner).StopReason(), tc.reason; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWithLease_do(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		wrap func(b retry.Backoff) retry.Backoff
+	}{
+		{"outermost", func(b retry.Backoff) retry.Backoff { return b }},
+		{"wrapped", func(b retry.Backoff) retry.Backoff { return retry.WithJitter(1*time.Nanosecond, b) }},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			remaining := scriptedLease(10*time.Second, 10*time.Second, 100*time.Millisecond)
+			b := tc.wrap(retry.WithLease(remaining, 1*time.Second, retry.NewConstant(1*time.Nanosecond)))
+
+			var attempts int
+			var reason retry.StopReason
+			err := retry.Do(context.Background(), b, func(_ context.Context) error {
+				attempts++
+				return retry.RetryableError(io.EOF)
+			}, retry.WithStopHook(func(r retry.StopReason, _ error) {
+				reason = r
+			}))
+			if !errors.Is(err, retry.ErrLeaseExpiring) || !errors.Is(err, io.EOF) {
+				t.Errorf("expected %v to be %v and %v", err, retry.ErrLeaseExpiring, io.EOF)
+			}
+			if got, want := attempts, 3; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := reason, retry.ReasonLeaseExpiring; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by a [Limiter] when a token will never become
+// available, such as when its burst is zero.
+var ErrRateLimited = errors.New("retry: rate limited")
+
+// Limiter limits the rate of retries. Use it with [WithLimiter] to share a
+// retry budget across many retry loops, so that a failing dependency is not
+// overwhelmed by retries.
+//
+// It is satisfied by *rate.Limiter from golang.org/x/time/rate. Implementations
+// must be safe for concurrent use. The retrytest package provides a contract
+// test for implementations.
+type Limiter interface {
+	// Allow takes a token and returns true if one is available now. Otherwise
+	// it returns false without waiting.
+	Allow() bool
+
+	// Wait blocks until a token is available and takes it. It returns the
+	// context's error if ctx is done first, or [ErrRateLimited] if a token will
+	// never become available.
+	Wait(ctx context.Context) error
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// TokenBucket is an in-memory [Limiter]. It holds up to burst tokens and adds
+// rate tokens per second. It starts full.
+//
+// It is safe for concurrent use.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	clock Clock
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// TokenBucketOption is an option that configures a [TokenBucket].
+type TokenBucketOption func(b *TokenBucket)
+
+// WithTokenBucketClock sets the clock used by the bucket to refill tokens and
+// to wait for them. It is primarily useful for driving time in tests.
+func WithTokenBucketClock(c Clock) TokenBucketOption {
+	return func(b *TokenBucket) {
+		if c != nil {
+			b.clock = c
+		}
+	}
+}
+
+// NewTokenBucket creates a new token bucket that adds rate tokens per second
+// and holds at most burst tokens. A rate of 0 never refills, and a burst of 0
+// rejects every request.
+//
+// It panics if rate is negative, NaN, or infinite, or burst is negative; see
+// [NewTokenBucketE].
+func NewTokenBucket(rate float64, burst int, opts ...TokenBucketOption) *TokenBucket {
+	b, err := NewTokenBucketE(rate, burst, opts...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
+
+// NewTokenBucketE is like [NewTokenBucket], but returns an error instead of
+// panicking if the arguments are invalid.
+func NewTokenBucketE(rate float64, burst int, opts ...TokenBucketOption) (*TokenBucket, error) {
+	// The negated comparison also rejects NaN.
+	if !(rate >= 0) || math.IsInf(rate, 1) {
+		return nil, &ValidationError{Field: "rate", Reason: "must be a finite number greater than or equal to 0"}
+	}
+	if burst < 0 {
+		return nil, &ValidationError{Field: "burst", Reason: "must be greater than or equal to 0"}
+	}
+
+	b := &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		clock:  realClock{},
+		tokens: float64(burst),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.last = b.clock.Now()
+	return b, nil
+}
+
+// Allow implements [Limiter].
+func (b *TokenBucket) Allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
+
+// Wait implements [Limiter].
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b.lock.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.lock.Unlock()
+			return nil
+		}
+		if b.rate == 0 || b.burst < 1 {
+			b.lock.Unlock()
+			return ErrRateLimited
+		}
+		// A tiny rate overflows the delay, so clamp it rather than converting
+		// out of range.
+		d := time.Duration(math.MaxInt64)
+		if f := (1 - b.tokens) / b.rate * float64(time.Second); f < math.MaxInt64 {
+			d = time.Duration(f)
+		}
+		b.lock.Unlock()
+
+		// Another waiter may take the token first, so check again after waking.
+		if d <= 0 {
+			d = 1
+		}
+		if err := b.clock.Sleep(ctx, d); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+	}
+}
+
+// refill adds the tokens accrued since the last refill. The caller must hold
+// the lock.
+func (b *TokenBucket) refill() {
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed.Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// WithLimiter requires a token from l before every retry, in addition to the
+// backoff's delay, so the backoff itself is unchanged. The first attempt never
+// waits. If the context is canceled while waiting, the context's error is
+// returned. If l returns any other error, such as [ErrRateLimited] or the error
+// from a *rate.Limiter whose wait would exceed the context's deadline,
+// retrying stops with [ReasonRateLimited] and the error from the most recent
+// attempt is returned.
+func WithLimiter(l Limiter) DoOption {
+	return func(c *doConfig) {
+		c.limiter = l
+	}
+}
@@ -0,0 +1,262 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrytest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	retrytest.LimiterContract(t, func(rate float64, burst int, clock retry.Clock) retry.Limiter {
+		return retry.NewTokenBucket(rate, burst, retry.WithTokenBucketClock(clock))
+	})
+}
+
+func TestNewTokenBucketE(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"negative_rate", -1, 1},
+		{"nan_rate", math.NaN(), 1},
+		{"infinite_rate", math.Inf(1), 1},
+		{"negative_burst", 1, -1},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := retry.NewTokenBucketE(tc.rate, tc.burst); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// sleepRecordingClock is a Clock whose time never advances, and whose Sleep
+// records the duration and then fails.
+type sleepRecordingClock struct {
+	slept []time.Duration
+}
+
+func (c *sleepRecordingClock) Now() time.Time { return time.Unix(0, 0) }
+
+func (c *sleepRecordingClock) Sleep(_ context.Context, d time.Duration) error {
+	c.slept = append(c.slept, d)
+	return io.EOF
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	t.Parallel()
+
+	t.Run("tiny_rate", func(t *testing.T) {
+		t.Parallel()
+
+		clock := new(sleepRecordingClock)
+		l := retry.NewTokenBucket(1e-300, 1, retry.WithTokenBucketClock(clock))
+		l.Allow()
+
+		if err := l.Wait(context.Background()); !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+		if got, want := clock.slept, []time.Duration{math.MaxInt64}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithLimiter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rate_limited", func(t *testing.T) {
+		t.Parallel()
+
+		// The bucket allows two retries in total and never refills.
+		l := retry.NewTokenBucket(0, 2)
+
+		var reason retry.StopReason
+		var attempts int
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(fmt.Errorf("attempt %d", attempts))
+		}, retry.WithLimiter(l), retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+
+		if got, want := attempts, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(err), "attempt 3"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := reason, retry.ReasonRateLimited; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		clock := retrytest.NewFakeClock(time.Unix(0, 0))
+		l := retry.NewTokenBucket(1, 1, retry.WithTokenBucketClock(clock))
+		l.Allow()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithLimiter(l))
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+// recordingLimiter is a Limiter that grants every token and counts the calls
+// to Wait.
+type recordingLimiter struct {
+	waits int
+}
+
+func (l *recordingLimiter) Allow() bool { return true }
+
+func (l *recordingLimiter) Wait(ctx context.Context) error {
+	l.waits++
+	return ctx.Err()
+}
+
+// blockingLimiter is a Limiter that never grants a token, blocking until the
+// context is done.
+type blockingLimiter struct {
+	waiting chan struct{}
+}
+
+func (l *blockingLimiter) Allow() bool { return false }
+
+func (l *blockingLimiter) Wait(ctx context.Context) error {
+	close(l.waiting)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// errorLimiter is a Limiter whose Wait fails without the context being done,
+// like a *rate.Limiter whose wait would exceed the context's deadline.
+type errorLimiter struct {
+	err error
+}
+
+func (l *errorLimiter) Allow() bool                  { return false }
+func (l *errorLimiter) Wait(_ context.Context) error { return l.err }
+
+func TestWithLimiter_custom(t *testing.T) {
+	t.Parallel()
+
+	t.Run("before_each_retry", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &sleepRecorder{fakeClock: newFakeClock()}
+		l := &recordingLimiter{}
+
+		var attempts int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(3, retry.NewConstant(1*time.Second)), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithLimiter(l), retry.WithClock(clock))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if got, want := attempts, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := l.waits, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The backoff's delays are still slept.
+		if got, want := fmt.Sprint(clock.slept), "[1s 1s 1s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("blocks_until_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		l := &blockingLimiter{waiting: make(chan struct{})}
+		go func() {
+			<-l.waiting
+			cancel()
+		}()
+
+		var attempts int
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithLimiter(l))
+		if got, want := err, context.Canceled; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("wait_error", func(t *testing.T) {
+		t.Parallel()
+
+		l := &errorLimiter{err: errors.New("rate: Wait(n=1) would exceed context deadline")}
+
+		var reason retry.StopReason
+		var attempts int
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(io.EOF)
+		}, retry.WithLimiter(l), retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("expected %v to be %v", err, io.EOF)
+		}
+		if got, want := attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := reason, retry.ReasonRateLimited; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithLimiter() {
+	// Shared by every retry loop calling the same dependency: at most 10
+	// retries per second, with bursts of up to 100.
+	l := retry.NewTokenBucket(10, 100)
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}, retry.WithLimiter(l)); err != nil {
+		// handle error
+	}
+}
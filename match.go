@@ -0,0 +1,44 @@
+package retry
+
+import (
+	"errors"
+)
+
+// RetryOn returns a predicate for [DoWithPredicate] and [DoValueWithPredicate]
+// that reports whether an error matches any of targets with [errors.Is], such
+// as io.ErrUnexpectedEOF, anywhere in its chain.
+func RetryOn(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnTypes returns a predicate for [DoWithPredicate] and
+// [DoValueWithPredicate] that reports whether an error has an error of type T
+// in its chain, found with [errors.As]. If match functions are given, the
+// error found must also satisfy one of them, for example to retry only the
+// net.Error values that are timeouts:
+//
+//	retry.RetryOnTypes(func(err net.Error) bool { return err.Timeout() })
+func RetryOnTypes[T error](match ...func(err T) bool) func(err error) bool {
+	return func(err error) bool {
+		var target T
+		if !errors.As(err, &target) {
+			return false
+		}
+		if len(match) == 0 {
+			return true
+		}
+		for _, fn := range match {
+			if fn(target) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,182 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// timeoutError is a net.Error.
+type timeoutError struct {
+	timeout bool
+}
+
+func (e *timeoutError) Error() string   { return fmt.Sprintf("timeout=%t", e.timeout) }
+func (e *timeoutError) Timeout() bool   { return e.timeout }
+func (e *timeoutError) Temporary() bool { return false }
+
+// wrapDeep wraps err in several layers of fmt.Errorf.
+func wrapDeep(err error) error {
+	for i := 0; i < 5; i++ {
+		err = fmt.Errorf("layer %d: %w", i, err)
+	}
+	return err
+}
+
+func TestRetryOn(t *testing.T) {
+	t.Parallel()
+
+	errOther := errors.New("other")
+	pred := retry.RetryOn(io.ErrUnexpectedEOF, context.DeadlineExceeded)
+
+	cases := []struct {
+		name string
+		err  error
+		exp  bool
+	}{
+		{"direct", io.ErrUnexpectedEOF, true},
+		{"second_target", context.DeadlineExceeded, true},
+		{"deep", wrapDeep(io.ErrUnexpectedEOF), true},
+		{"joined", wrapDeep(errors.Join(errOther, wrapDeep(io.ErrUnexpectedEOF))), true},
+		{"other", wrapDeep(errOther), false},
+		{"eof", io.EOF, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := pred(tc.err), tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("no_targets", func(t *testing.T) {
+		t.Parallel()
+
+		if retry.RetryOn()(io.EOF) {
+			t.Error("expected no match")
+		}
+	})
+}
+
+func TestRetryOnTypes(t *testing.T) {
+	t.Parallel()
+
+	timeouts := retry.RetryOnTypes(func(err net.Error) bool { return err.Timeout() })
+	netErrors := retry.RetryOnTypes[net.Error]()
+
+	cases := []struct {
+		name     string
+		err      error
+		timeouts bool
+		any      bool
+	}{
+		{"timeout", &timeoutError{timeout: true}, true, true},
+		{"deep_timeout", wrapDeep(&timeoutError{timeout: true}), true, true},
+		{"deep_not_timeout", wrapDeep(&timeoutError{timeout: false}), false, true},
+		{"joined", errors.Join(io.EOF, wrapDeep(&timeoutError{timeout: true})), true, true},
+		{"other", wrapDeep(io.EOF), false, false},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := timeouts(tc.err), tc.timeouts; got != want {
+				t.Errorf("timeouts: expected %v to be %v", got, want)
+			}
+			if got, want := netErrors(tc.err), tc.any; got != want {
+				t.Errorf("any: expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDoWithPredicate_matchers(t *testing.T) {
+	t.Parallel()
+
+	errFatal := errors.New("fatal")
+
+	t.Run("retries_matches", func(t *testing.T) {
+		t.Parallel()
+
+		var evaluated int
+		pred := retry.RetryOn(io.ErrUnexpectedEOF)
+
+		var calls int
+		err := retry.DoWithPredicate(context.Background(), retry.NewConstant(1*time.Nanosecond), func(err error) bool {
+			evaluated++
+			return pred(err)
+		}, func(_ context.Context) error {
+			calls++
+			switch calls {
+			case 1, 2:
+				return wrapDeep(io.ErrUnexpectedEOF)
+			case 3:
+				return retry.RetryableError(errFatal)
+			case 4:
+				return wrapDeep(errFatal)
+			}
+			return nil
+		})
+		if got, want := err, errFatal; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The predicate is evaluated once for each unmarked error, and not for
+		// the error marked with RetryableError, which is retried regardless.
+		if got, want := evaluated, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.DoWithPredicate(context.Background(), retry.NewConstant(1*time.Nanosecond), func(err error) bool {
+			t.Errorf("unexpected call with %v", err)
+			return false
+		}, func(_ context.Context) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func ExampleRetryOnTypes() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+	// Retry network timeouts without marking them with RetryableError.
+	timeouts := retry.RetryOnTypes(func(err net.Error) bool { return err.Timeout() })
+
+	var attempts int
+	err := retry.DoWithPredicate(ctx, b, timeouts, func(_ context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("dial: %w", &timeoutError{timeout: true})
+		}
+		return nil
+	})
+	fmt.Println(attempts, err)
+
+	// Output:
+	// 3 <nil>
+}
@@ -0,0 +1,177 @@
+package retry
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// NegativeCache caches failures across calls to [DoCached], so repeated calls
+// for a key that recently failed permanently return the cached error instead of
+// calling the dependency again. Entries expire after a TTL, and the least
+// recently used entries are evicted once the cache is full.
+//
+// It is safe for concurrent use.
+type NegativeCache struct {
+	ttl          time.Duration
+	exhaustedTTL time.Duration
+	maxEntries   int
+	now          func() time.Time
+
+	lock    sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type negativeCacheEntry struct {
+	key     string
+	err     error
+	expires time.Time
+}
+
+// NegativeCacheOption is an option that configures a [NegativeCache].
+type NegativeCacheOption func(c *NegativeCache)
+
+// WithExhaustedTTL causes failures from exhausting the backoff on retryable
+// errors to also be cached, for the given TTL. By default, only permanent
+// failures are cached. The TTL is typically shorter than the TTL for permanent
+// failures.
+func WithExhaustedTTL(ttl time.Duration) NegativeCacheOption {
+	return func(c *NegativeCache) {
+		c.exhaustedTTL = ttl
+	}
+}
+
+// WithCacheNowFunc sets the function used by the cache to read the current
+// time. It defaults to [time.Now] and is primarily useful for driving time in
+// tests.
+func WithCacheNowFunc(now func() time.Time) NegativeCacheOption {
+	return func(c *NegativeCache) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// NewNegativeCache creates a new negative cache that caches permanent failures
+// for ttl and holds at most maxEntries keys. If maxEntries is less than or equal
+// to zero, the number of entries is not limited.
+func NewNegativeCache(ttl time.Duration, maxEntries int, opts ...NegativeCacheOption) *NegativeCache {
+	c := &NegativeCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		lru:        list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get returns the cached error for key, or nil if no unexpired failure is
+// cached.
+func (c *NegativeCache) Get(key string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry := elem.Value.(*negativeCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil
+	}
+
+	c.lru.MoveToFront(elem)
+	return entry.err
+}
+
+// Len returns the number of entries in the cache, including expired entries
+// that have not yet been removed.
+func (c *NegativeCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// set caches err for key for the duration ttl.
+func (c *NegativeCache) set(key string, err error, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	expires := c.now().Add(ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*negativeCacheEntry)
+		entry.err = err
+		entry.expires = expires
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&negativeCacheEntry{
+		key:     key,
+		err:     err,
+		expires: expires,
+	})
+
+	if c.maxEntries > 0 {
+		for c.lru.Len() > c.maxEntries {
+			c.remove(c.lru.Back())
+		}
+	}
+}
+
+func (c *NegativeCache) remove(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*negativeCacheEntry)
+	delete(c.entries, entry.key)
+}
+
+// DoCached wraps a function with a backoff to retry, like [Do], but first
+// consults the negative cache for key. If a failure for key is cached, it is
+// returned immediately without calling f. The cached error is the same error
+// value originally returned, so [errors.Is] and [errors.As] behave identically,
+// unless an error compactor is set with [WithErrorCompaction], in which case the
+// compacted error is cached.
+//
+// Permanent failures are cached. Failures from exhausting the backoff are only
+// cached if the cache was created with [WithExhaustedTTL]. Successes, context
+// cancellation, shutdown, and a closed global gate are never cached.
+func DoCached(ctx context.Context, cache *NegativeCache, key string, b Backoff, f RetryFunc, opts ...DoOption) error {
+	if err := cache.Get(key); err != nil {
+		return err
+	}
+
+	var exhausted, shutdown bool
+	var cfg *doConfig
+	opts = appendOptions(opts, WithStopHook(func(reason StopReason, _ error) {
+		if reason == ReasonShutdown || reason == ReasonGateClosed {
+			shutdown = true
+			return
+		}
+		exhausted = true
+	}), func(c *doConfig) {
+		// Keep the config built by Do to compact the cached error.
+		cfg = c
+	})
+
+	err := Do(ctx, b, f, opts...)
+	switch {
+	case err == nil, shutdown, ctx.Err() != nil, errors.Is(err, ErrGateClosed):
+	case exhausted:
+		cache.set(key, cfg.compactError(err), cache.exhaustedTTL)
+	default:
+		cache.set(key, cfg.compactError(err), cache.ttl)
+	}
+	return err
+}
@@ -0,0 +1,255 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+type permanentError struct {
+	code int
+}
+
+func (e *permanentError) Error() string {
+	return fmt.Sprintf("permanent error %d", e.code)
+}
+
+func TestDoCached(t *testing.T) {
+	t.Parallel()
+
+	newBackoff := func() retry.Backoff {
+		return retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+	}
+
+	t.Run("caches_permanent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clock := newFakeClock()
+		cache := retry.NewNegativeCache(1*time.Minute, 10, retry.WithCacheNowFunc(clock.Now))
+
+		errNotFound := &permanentError{code: 404}
+		wrapped := fmt.Errorf("lookup failed: %w", errNotFound)
+
+		var calls int
+		f := func(_ context.Context) error {
+			calls++
+			return wrapped
+		}
+
+		for i := 0; i < 3; i++ {
+			err := retry.DoCached(ctx, cache, "key", newBackoff(), f)
+			if got, want := err, wrapped; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+			if !errors.Is(err, errNotFound) {
+				t.Errorf("%d: expected %v to be %v", i, err, errNotFound)
+			}
+			var perr *permanentError
+			if !errors.As(err, &perr) || perr.code != 404 {
+				t.Errorf("%d: expected %v to be a permanent error", i, err)
+			}
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// Other keys are unaffected.
+		_ = retry.DoCached(ctx, cache, "other", newBackoff(), f)
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("ttl_expiry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clock := newFakeClock()
+		cache := retry.NewNegativeCache(1*time.Minute, 10, retry.WithCacheNowFunc(clock.Now))
+
+		var calls int
+		f := func(_ context.Context) error {
+			calls++
+			return fmt.Errorf("permanent")
+		}
+
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		clock.Advance(59 * time.Second)
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		clock.Advance(1 * time.Second)
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("lru_eviction", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		cache := retry.NewNegativeCache(1*time.Minute, 2)
+
+		f := func(_ context.Context) error {
+			return fmt.Errorf("permanent")
+		}
+
+		_ = retry.DoCached(ctx, cache, "a", newBackoff(), f)
+		_ = retry.DoCached(ctx, cache, "b", newBackoff(), f)
+
+		// Touch "a" so "b" is least recently used.
+		if err := cache.Get("a"); err == nil {
+			t.Fatal("expected a to be cached")
+		}
+
+		_ = retry.DoCached(ctx, cache, "c", newBackoff(), f)
+
+		if got, want := cache.Len(), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if err := cache.Get("a"); err == nil {
+			t.Error("expected a to be cached")
+		}
+		if err := cache.Get("b"); err != nil {
+			t.Error("expected b to be evicted")
+		}
+		if err := cache.Get("c"); err == nil {
+			t.Error("expected c to be cached")
+		}
+	})
+
+	t.Run("success_not_cached", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		cache := retry.NewNegativeCache(1*time.Minute, 10)
+
+		var calls int
+		for i := 0; i < 3; i++ {
+			if err := retry.DoCached(ctx, cache, "key", newBackoff(), func(_ context.Context) error {
+				calls++
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := cache.Len(), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhausted_not_cached_by_default", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		cache := retry.NewNegativeCache(1*time.Minute, 10)
+
+		var calls int
+		f := func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		if got, want := calls, 6; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhausted_ttl", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clock := newFakeClock()
+		cache := retry.NewNegativeCache(1*time.Minute, 10,
+			retry.WithExhaustedTTL(5*time.Second),
+			retry.WithCacheNowFunc(clock.Now))
+
+		var calls int
+		f := func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		clock.Advance(5 * time.Second)
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), f)
+		if got, want := calls, 6; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled_not_cached", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cache := retry.NewNegativeCache(1*time.Minute, 10)
+
+		_ = retry.DoCached(ctx, cache, "key", newBackoff(), func(_ context.Context) error {
+			cancel()
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+		if got, want := cache.Len(), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		cache := retry.NewNegativeCache(1*time.Minute, 8)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 64; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					key := fmt.Sprintf("key-%d", (i+j)%16)
+					_ = retry.DoCached(ctx, cache, key, newBackoff(), func(_ context.Context) error {
+						return fmt.Errorf("permanent")
+					})
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, max := cache.Len(), 8; got > max {
+			t.Errorf("expected %v to be at most %v", got, max)
+		}
+	})
+}
+
+func ExampleDoCached() {
+	ctx := context.Background()
+	cache := retry.NewNegativeCache(30*time.Second, 1024)
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(100*time.Millisecond))
+
+	if err := retry.DoCached(ctx, cache, "user/1234", b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
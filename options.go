@@ -0,0 +1,412 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DoOption is an option that configures the behavior of [Do] and [DoValue].
+type DoOption func(c *doConfig)
+
+type doConfig struct {
+	amplificationExtract func(ctx context.Context) (uint64, bool)
+	amplificationMax     uint64
+
+	keepLast bool
+
+	// beforeAttempt is called at the start of each attempt to derive the context
+	// passed to the retry function. If it returns an error, the attempt fails
+	// with that error without calling the retry function.
+	beforeAttempt []func(ctx context.Context) (context.Context, error)
+
+	// baggage is called with each attempt's context and attempt number after
+	// beforeAttempt, and returns the context passed to the retry function.
+	baggage []func(ctx context.Context, attempt uint64) context.Context
+
+	// retryIf reports whether an error that is not wrapped with RetryableError
+	// should be retried.
+	retryIf func(err error) bool
+
+	// onStop is called when retrying stops before success.
+	onStop []func(reason StopReason, err error)
+
+	shutdown *ShutdownCoordinator
+
+	// attemptTimeout is the maximum duration of each attempt, or 0 for no limit.
+	attemptTimeout time.Duration
+
+	// onOutcome is called after every attempt.
+	onOutcome []func(o Outcome)
+
+	// initialDelay is slept before the first attempt.
+	initialDelay time.Duration
+
+	// onRetry is called after every attempt that will be retried.
+	onRetry []func(attempt uint64, delay time.Duration, err error)
+
+	// onCancel is called once when the loop returns because the context is
+	// done.
+	onCancel []func(attempt uint64, phase CancelPhase, err error)
+
+	// onDegraded is called on success after more than one attempt.
+	onDegraded []func(ctx context.Context, attempts uint64, elapsed time.Duration)
+
+	clock Clock
+
+	limiter Limiter
+
+	// resources are acquired before each attempt and cleaned up after it.
+	resources []func(ctx context.Context) (cleanup func(), err error)
+
+	// attemptHooks derive each attempt's context after baggage, and return a
+	// function called once the attempt completes.
+	attemptHooks []func(ctx context.Context, attempt uint64) (context.Context, func())
+
+	// reclassify is called after reclassifyDelay with the error from a failed
+	// attempt, and returns the error used to decide retryability.
+	reclassify      func(ctx context.Context, err error) error
+	reclassifyDelay time.Duration
+
+	// compact is applied to errors before they are retained, or nil to retain
+	// them as is.
+	compact func(err error) error
+
+	// historyMax is the number of attempt errors retained by history, which is
+	// created for each call.
+	historyMax int
+	history    *errorHistory
+
+	// attribute enables caller attribution, and site is the call site found
+	// for each call.
+	attribute bool
+	site      *callSite
+
+	// exhaustionDetail enables ExhaustedError, and exhaustion records how each
+	// call stopped retrying.
+	exhaustionDetail bool
+	exhaustion       *exhaustion
+
+	// maxIterations is the number of attempts after which the loop is
+	// considered runaway, or 0 for no limit.
+	maxIterations uint64
+
+	// walkDepth is the number of wrapped errors walked before falling back to
+	// errors.As, or 0 for the default.
+	walkDepth int
+
+	// skipBudgetRecheck disables checking time budgets after a late wake-up.
+	skipBudgetRecheck bool
+
+	// deadlineAware stops retrying when the next delay would end after the
+	// context's deadline.
+	deadlineAware bool
+
+	// concurrency is the number of retry loops DoAll runs at once, or 0 for no
+	// limit.
+	concurrency int
+
+	// progress adds a progress sink to each attempt's context, and returns a
+	// function that closes it once the attempt completes and returns the number
+	// of values dropped.
+	progress func(ctx context.Context) (context.Context, func() uint64)
+}
+
+// defaultDoConfig is the configuration used when no options are given. It must
+// not be modified.
+var defaultDoConfig = &doConfig{clock: realClock{}}
+
+func newDoConfig(opts []DoOption) *doConfig {
+	if len(opts) == 0 {
+		return defaultDoConfig
+	}
+
+	c := &doConfig{clock: realClock{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// appendOptions returns opts followed by extra. It never writes into the
+// backing array of opts, which callers may share between concurrent calls.
+func appendOptions(opts []DoOption, extra ...DoOption) []DoOption {
+	return append(opts[:len(opts):len(opts)], extra...)
+}
+
+// maxAttempts returns the maximum number of local attempts, including the
+// first, permitted for the given context. A value of 0 means there is no limit
+// beyond what the backoff imposes.
+func (c *doConfig) maxAttempts(ctx context.Context) uint64 {
+	if c.amplificationExtract == nil {
+		return 0
+	}
+
+	clientAttempt, ok := c.amplificationExtract(ctx)
+	if !ok || clientAttempt < 1 {
+		return 0
+	}
+
+	if clientAttempt >= c.amplificationMax {
+		return 1
+	}
+	return c.amplificationMax - clientAttempt
+}
+
+// attemptContext derives the context for attempt number attempt, starting at 1,
+// from ctx, and acquires the attempt's resources. The returned release function
+// cleans up the resources and must be called once the attempt completes. If an
+// error is returned, any resources already acquired have been released.
+func (c *doConfig) attemptContext(ctx context.Context, attempt uint64) (context.Context, func(), error) {
+	ctx = context.WithValue(ctx, retryCountKey{}, attempt-1)
+
+	for _, fn := range c.beforeAttempt {
+		var err error
+		ctx, err = fn(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, fn := range c.baggage {
+		ctx = fn(ctx, attempt)
+	}
+
+	if len(c.attemptHooks) == 0 && len(c.resources) == 0 {
+		return ctx, func() {}, nil
+	}
+
+	cleanups := make([]func(), 0, len(c.attemptHooks)+len(c.resources))
+	release := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+	for _, fn := range c.attemptHooks {
+		var done func()
+		ctx, done = fn(ctx, attempt)
+		cleanups = append(cleanups, done)
+	}
+	for _, acquire := range c.resources {
+		cleanup, err := acquire(ctx)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+		if cleanup != nil {
+			cleanups = append(cleanups, cleanup)
+		}
+	}
+	return ctx, release, nil
+}
+
+// runAttempt calls f with the context for attempt number attempt, applying the
+// attempt timeout, and then calls release, even if f panics. The duration is
+// measured with a single clock reading before and after f, and is reported to
+// the outcome observers; budget wrappers read their own clock instead. An
+// attempt that timed out reports the timeout as its duration and its error is
+// made retryable.
+func (c *doConfig) runAttempt(ctx context.Context, attempt uint64, release func(), f func(ctx context.Context) error) (o Outcome) {
+	defer release()
+
+	o.Attempt = attempt
+	if c.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.attemptTimeout)
+		defer cancel()
+	}
+
+	start := c.clock.Now()
+	o.Err = f(ctx)
+	o.Duration = c.clock.Now().Sub(start)
+
+	if c.attemptTimeout > 0 && o.Err != nil &&
+		(o.Duration >= c.attemptTimeout || errors.Is(ctx.Err(), context.DeadlineExceeded)) {
+		o.TimedOut = true
+		o.Duration = c.attemptTimeout
+
+		if _, ok := asRetryable(o.Err); !ok {
+			o.Err = RetryableError(o.Err)
+		}
+	}
+	return o
+}
+
+// observe records the error of o in the history, if any, and calls the outcome
+// observers with o.
+func (c *doConfig) observe(o Outcome) {
+	if c.site != nil {
+		c.site.attempts.Add(1)
+	}
+	if c.history != nil && o.Err != nil {
+		c.history.add(c.compactError(o.Err))
+	}
+	for _, fn := range c.onOutcome {
+		fn(o)
+	}
+}
+
+// compactError applies the error compactor, if any, to err.
+func (c *doConfig) compactError(err error) error {
+	if c.compact == nil || err == nil {
+		return err
+	}
+	return c.compact(err)
+}
+
+// withRetryPredicate causes errors that are not wrapped with [RetryableError]
+// to be retried when fn returns true.
+func withRetryPredicate(fn func(err error) bool) DoOption {
+	return func(c *doConfig) {
+		c.retryIf = fn
+	}
+}
+
+// WithAmplificationGuard limits the number of local attempts when the current
+// request is itself being retried by an upstream caller. This prevents retries
+// from multiplying across layers of a call graph.
+//
+// The extract function returns the upstream attempt number from the context,
+// typically parsed from a request header (such as "x-retry-attempt") or gRPC
+// metadata. If ok is true and the upstream attempt is at least 1, the number of
+// local attempts is reduced so that the combined depth never exceeds
+// maxCombined. At least one local attempt is always made.
+func WithAmplificationGuard(extract func(ctx context.Context) (clientAttempt uint64, ok bool), maxCombined uint64) DoOption {
+	return func(c *doConfig) {
+		c.amplificationExtract = extract
+		c.amplificationMax = maxCombined
+	}
+}
+
+// ZeroOnError causes [DoValue] to return the zero value of its result type
+// alongside any error. This is the default behavior.
+func ZeroOnError() DoOption {
+	return func(c *doConfig) {
+		c.keepLast = false
+	}
+}
+
+// KeepLastOnError causes [DoValue] to return the value from the most recent
+// attempt alongside any error, instead of the zero value. If no attempt was
+// made, the zero value is returned.
+func KeepLastOnError() DoOption {
+	return func(c *doConfig) {
+		c.keepLast = true
+	}
+}
+
+// WithInitialDelay causes [Do] and [DoValue] to sleep for d before the first
+// attempt, as well as between attempts. If the context is done during the
+// sleep, the context's error is returned without making any attempt. A delay
+// less than or equal to zero is ignored.
+func WithInitialDelay(d time.Duration) DoOption {
+	return func(c *doConfig) {
+		c.initialDelay = d
+	}
+}
+
+// WithStopHook registers a function that is called when [Do] or [DoValue] stops
+// retrying a retryable error because the backoff stopped or the attempt limit
+// was reached. The function receives the reason and the error that will be
+// returned. It is not called on success, permanent errors, or context
+// cancellation. Multiple hooks are called in the order they were given.
+func WithStopHook(fn func(reason StopReason, err error)) DoOption {
+	return func(c *doConfig) {
+		c.onStop = append(c.onStop, fn)
+	}
+}
+
+// WithBaggage registers a function that derives each attempt's context from the
+// attempt number, starting at 1. It is intended for propagating retry metadata,
+// such as tracing baggage, to downstream services. The function is called after
+// the retry count is set, and the returned context is passed to the retry
+// function. It only affects the context of the attempt, never the caller's.
+// Multiple functions are called in the order they were given.
+func WithBaggage(set func(ctx context.Context, attempt uint64) context.Context) DoOption {
+	return func(c *doConfig) {
+		c.baggage = append(c.baggage, set)
+	}
+}
+
+// WithAttemptTimeout limits the duration of each attempt. The context passed to
+// the retry function is canceled after d. An attempt that exceeds d is retried
+// even if its error is not wrapped with [RetryableError], as long as the
+// caller's context is not done. A value of 0 means attempts are not limited.
+func WithAttemptTimeout(d time.Duration) DoOption {
+	return func(c *doConfig) {
+		c.attemptTimeout = d
+	}
+}
+
+// WithReclassification delays the decision of whether a failed attempt is
+// retryable. After an attempt fails, [Do] waits for delay and then calls
+// reclassify with the error; the returned error is classified with the usual
+// rules instead. This helps with clients that return an ambiguous error right
+// after a connection drops, which can be resolved into a clearer one by waiting
+// briefly and probing, for example by checking a health endpoint.
+//
+// To leave an error unchanged, reclassify returns it as is. A nil error is
+// treated as success. The delay does not count as a backoff step, and waiting
+// stops if the context is canceled.
+func WithReclassification(delay time.Duration, reclassify func(ctx context.Context, err error) error) DoOption {
+	return func(c *doConfig) {
+		c.reclassify = reclassify
+		c.reclassifyDelay = delay
+	}
+}
+
+// WithAttemptResources acquires a resource, such as a scratch directory, before
+// each attempt and cleans it up after the attempt completes, whether it
+// succeeded, failed, or panicked, and before sleeping. The acquire function
+// receives the attempt's context.
+//
+// If acquire returns an error, the retry function is not called and the error
+// is handled as if the attempt returned it: it is retried only if wrapped with
+// [RetryableError]. Such a failed acquisition counts as an attempt, including
+// for [GetRetryCount] and attempt limits. Multiple resources are acquired in
+// the order they were given and cleaned up in reverse order.
+func WithAttemptResources(acquire func(ctx context.Context) (cleanup func(), err error)) DoOption {
+	return func(c *doConfig) {
+		c.resources = append(c.resources, acquire)
+	}
+}
+
+// WithoutBudgetRecheck disables checking time budgets, such as the one set with
+// [WithMaxDuration], after waking up late from a sleep between attempts. By
+// default, [Do] and [DoValue] stop without making another attempt when a
+// sleep overran its delay by more than 50ms and the budget was used up in the
+// meantime.
+func WithoutBudgetRecheck() DoOption {
+	return func(c *doConfig) {
+		c.skipBudgetRecheck = true
+	}
+}
+
+// ErrDeadlineWouldExceed is wrapped around the error returned by [Do] and
+// [DoValue] when retrying stopped because of [WithDeadlineAware].
+var ErrDeadlineWouldExceed = errors.New("retry: next attempt would exceed the context deadline")
+
+// WithDeadlineAware causes [Do] and [DoValue] to stop retrying, instead of
+// sleeping, when the next delay would end after the context's deadline. The
+// sleep could not complete, so without it Do waits until the deadline and
+// returns the context's error. With it, Do returns right away with the error
+// from the last attempt, wrapped with [ErrDeadlineWouldExceed], and the reason
+// [ReasonDeadline]. The time left is measured with the clock set with
+// [WithClock]. A context without a deadline is not affected.
+func WithDeadlineAware() DoOption {
+	return func(c *doConfig) {
+		c.deadlineAware = true
+	}
+}
+
+// WithErrorCompaction sets a function applied to errors before they are
+// retained beyond the call that returned them: by [FirstSuccess], which keeps
+// the error of every function until all have failed, by [DoCached], which
+// caches failures, and by [WithErrorHistory]. It is useful when errors wrap
+// large payloads, such as response bodies. The error returned to the caller is
+// never compacted. By default, errors are retained as is; [CompactError]
+// bounds their size.
+func WithErrorCompaction(compact func(err error) error) DoOption {
+	return func(c *doConfig) {
+		c.compact = compact
+	}
+}
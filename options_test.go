@@ -0,0 +1,830 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+type upstreamAttemptKey struct{}
+
+func withUpstreamAttempt(ctx context.Context, attempt uint64) context.Context {
+	return context.WithValue(ctx, upstreamAttemptKey{}, attempt)
+}
+
+func upstreamAttempt(ctx context.Context) (uint64, bool) {
+	v, ok := ctx.Value(upstreamAttemptKey{}).(uint64)
+	return v, ok
+}
+
+func TestWithAmplificationGuard(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		upstream    uint64
+		hasUpstream bool
+		maxCombined uint64
+		exp         int
+	}{
+		{
+			name:        "no_upstream",
+			hasUpstream: false,
+			maxCombined: 3,
+			exp:         6, // 1 + 5 retries from the backoff
+		},
+		{
+			name:        "upstream_zero",
+			upstream:    0,
+			hasUpstream: true,
+			maxCombined: 3,
+			exp:         6,
+		},
+		{
+			name:        "upstream_reduces",
+			upstream:    1,
+			hasUpstream: true,
+			maxCombined: 3,
+			exp:         2,
+		},
+		{
+			name:        "upstream_exhausts",
+			upstream:    5,
+			hasUpstream: true,
+			maxCombined: 3,
+			exp:         1,
+		},
+		{
+			name:        "backoff_stricter",
+			upstream:    1,
+			hasUpstream: true,
+			maxCombined: 100,
+			exp:         6,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tc.hasUpstream {
+				ctx = withUpstreamAttempt(ctx, tc.upstream)
+			}
+
+			b := retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond))
+
+			var i int
+			if err := retry.Do(ctx, b, func(_ context.Context) error {
+				i++
+				return retry.RetryableError(fmt.Errorf("oops"))
+			}, retry.WithAmplificationGuard(upstreamAttempt, tc.maxCombined)); err == nil {
+				t.Fatal("expected err")
+			}
+
+			if got, want := i, tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		t.Parallel()
+
+		const maxCombined = 4
+
+		// Each layer calls and retries the next layer, propagating its local
+		// attempt number as the upstream attempt. Without the guard, three
+		// layers of 4 attempts would make 4*4*4 = 64 calls to the innermost
+		// function.
+		var calls int
+		var layer func(ctx context.Context, depth int) error
+		layer = func(ctx context.Context, depth int) error {
+			b := retry.WithMaxRetries(maxCombined-1, retry.NewConstant(1*time.Nanosecond))
+
+			var attempt uint64
+			if upstream, ok := upstreamAttempt(ctx); ok {
+				attempt = upstream
+			}
+
+			return retry.Do(ctx, b, func(ctx context.Context) error {
+				attempt++
+				if depth == 0 {
+					calls++
+					return retry.RetryableError(fmt.Errorf("oops"))
+				}
+				return retry.RetryableError(layer(withUpstreamAttempt(ctx, attempt), depth-1))
+			}, retry.WithAmplificationGuard(upstreamAttempt, maxCombined))
+		}
+
+		if err := layer(context.Background(), 2); err == nil {
+			t.Fatal("expected err")
+		}
+
+		// The outer layer makes 4 attempts. Its attempts 1 to 4 leave the middle
+		// layer 3, 2, 1, and 1 attempts, whose upstream attempts in turn leave
+		// the inner layer 2+1+1, 1+1, 1, and 1 attempts.
+		if got, want := calls, 8; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithAmplificationGuard_httpHeader() {
+	// Extract the upstream attempt number from the incoming request and store it
+	// on the context.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if v, err := strconv.ParseUint(r.Header.Get("x-retry-attempt"), 10, 64); err == nil {
+			ctx = context.WithValue(ctx, upstreamAttemptKey{}, v)
+		}
+
+		b := retry.WithMaxRetries(4, retry.NewFibonacci(1*time.Second))
+		if err := retry.Do(ctx, b, func(ctx context.Context) error {
+			// Call the next service, propagating the combined attempt number so
+			// it can guard its own retries in the same way.
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://backend.example.com/", nil)
+			if err != nil {
+				return err
+			}
+			upstream, _ := ctx.Value(upstreamAttemptKey{}).(uint64)
+			retries, _ := retry.GetRetryCount(ctx)
+			req.Header.Set("x-retry-attempt", strconv.FormatUint(upstream+retries+1, 10))
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return retry.RetryableError(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode >= 500 {
+				return retry.RetryableError(fmt.Errorf("backend: %s", resp.Status))
+			}
+			return nil
+		}, retry.WithAmplificationGuard(func(ctx context.Context) (uint64, bool) {
+			v, ok := ctx.Value(upstreamAttemptKey{}).(uint64)
+			return v, ok
+		}, 5)); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	})
+	_ = handler
+}
+
+func ExampleWithAmplificationGuard_grpcMetadata() {
+	ctx := context.Background()
+
+	// metadataFromContext stands in for google.golang.org/grpc/metadata's
+	// FromIncomingContext, which returns a map[string][]string.
+	metadataFromContext := func(ctx context.Context) (map[string][]string, bool) {
+		return map[string][]string{"x-retry-attempt": {"2"}}, true
+	}
+
+	extract := func(ctx context.Context) (uint64, bool) {
+		md, ok := metadataFromContext(ctx)
+		if !ok {
+			return 0, false
+		}
+		vals := md["x-retry-attempt"]
+		if len(vals) == 0 {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(vals[0], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return v, true
+	}
+
+	b := retry.WithMaxRetries(4, retry.NewFibonacci(1*time.Nanosecond))
+
+	var attempts int
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		attempts++
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}, retry.WithAmplificationGuard(extract, 5)); err != nil {
+		// handle error
+	}
+
+	fmt.Println(attempts)
+	// Output:
+	// 3
+}
+
+func testOnErrorMode[T any](t *testing.T, val T, opts []retry.DoOption, keepLast bool) {
+	t.Helper()
+
+	t.Run("exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		got, err := retry.DoValue(ctx, b, func(_ context.Context) (T, error) {
+			return val, retry.RetryableError(fmt.Errorf("oops"))
+		}, opts...)
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		checkOnErrorValue(t, got, val, keepLast)
+	})
+
+	t.Run("non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		got, err := retry.DoValue(ctx, b, func(_ context.Context) (T, error) {
+			return val, fmt.Errorf("oops")
+		}, opts...)
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		checkOnErrorValue(t, got, val, keepLast)
+	})
+
+	t.Run("canceled_after_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		b := retry.NewConstant(1 * time.Hour)
+
+		got, err := retry.DoValue(ctx, b, func(_ context.Context) (T, error) {
+			cancel()
+			return val, retry.RetryableError(fmt.Errorf("oops"))
+		}, opts...)
+		if got, want := err, context.Canceled; !errors.Is(got, want) {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		checkOnErrorValue(t, got, val, keepLast)
+	})
+}
+
+func checkOnErrorValue[T any](tb testing.TB, got, val T, keepLast bool) {
+	tb.Helper()
+
+	var want T
+	if keepLast {
+		want = val
+	}
+	if !reflect.DeepEqual(got, want) {
+		tb.Errorf("expected %#v to be %#v", got, want)
+	}
+}
+
+func TestZeroOnError(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		Name  string
+		Count int
+	}
+
+	for name, opts := range map[string][]retry.DoOption{
+		"default":       nil,
+		"zero_on_error": {retry.ZeroOnError()},
+		"overrides":     {retry.KeepLastOnError(), retry.ZeroOnError()},
+	} {
+		opts := opts
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Run("pointer", func(t *testing.T) {
+				t.Parallel()
+				testOnErrorMode(t, &result{Name: "partial"}, opts, false)
+			})
+			t.Run("slice", func(t *testing.T) {
+				t.Parallel()
+				testOnErrorMode(t, []string{"partial"}, opts, false)
+			})
+			t.Run("struct", func(t *testing.T) {
+				t.Parallel()
+				testOnErrorMode(t, result{Name: "partial", Count: 1}, opts, false)
+			})
+		})
+	}
+}
+
+func TestKeepLastOnError(t *testing.T) {
+	t.Parallel()
+
+	type result struct {
+		Name  string
+		Count int
+	}
+
+	opts := []retry.DoOption{retry.KeepLastOnError()}
+
+	t.Run("pointer", func(t *testing.T) {
+		t.Parallel()
+		testOnErrorMode(t, &result{Name: "partial"}, opts, true)
+	})
+	t.Run("slice", func(t *testing.T) {
+		t.Parallel()
+		testOnErrorMode(t, []string{"partial"}, opts, true)
+	})
+	t.Run("struct", func(t *testing.T) {
+		t.Parallel()
+		testOnErrorMode(t, result{Name: "partial", Count: 1}, opts, true)
+	})
+
+	t.Run("latest_value", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		got, err := retry.DoValue(ctx, b, func(_ context.Context) (int, error) {
+			i++
+			return i, retry.RetryableError(fmt.Errorf("oops"))
+		}, opts...)
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		if want := 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestWithStopHook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("budget_truncated", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(250*time.Microsecond, retry.NewConstant(100*time.Microsecond),
+			retry.WithNowFunc(clock.Now))
+
+		var reasons []retry.StopReason
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			// Each attempt takes 100µs on the fake clock, so the second delay is
+			// truncated to the remaining budget and the third attempt is final.
+			clock.Advance(100 * time.Microsecond)
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithStopHook(func(reason retry.StopReason, err error) {
+			reasons = append(reasons, reason)
+		}))
+		if err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := reasons, []retry.StopReason{retry.ReasonBudgetTruncatedFinalSleep}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("max_attempts", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := withUpstreamAttempt(context.Background(), 2)
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var reasons []retry.StopReason
+		if err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithAmplificationGuard(upstreamAttempt, 3), retry.WithStopHook(func(reason retry.StopReason, err error) {
+			reasons = append(reasons, reason)
+		})); err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := reasons, []retry.StopReason{retry.ReasonMaxAttempts}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("not_called", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var called bool
+		hook := retry.WithStopHook(func(reason retry.StopReason, err error) {
+			called = true
+		})
+
+		_ = retry.Do(ctx, b, func(_ context.Context) error {
+			return nil
+		}, hook)
+		_ = retry.Do(ctx, b, func(_ context.Context) error {
+			return fmt.Errorf("permanent")
+		}, hook)
+
+		if called {
+			t.Error("expected hook to not be called")
+		}
+	})
+}
+
+func TestWithBaggage(t *testing.T) {
+	t.Parallel()
+
+	type baggageKey struct{}
+
+	ctx := context.Background()
+
+	var setAttempts, retryCounts []uint64
+	var calls int
+	err := retry.Do(ctx, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)),
+		func(attemptCtx context.Context) error {
+			calls++
+
+			attempt, ok := attemptCtx.Value(baggageKey{}).(uint64)
+			if !ok {
+				t.Fatal("expected context returned by setter")
+			}
+			if got, want := attempt, uint64(calls); got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			return retry.RetryableError(fmt.Errorf("oops"))
+		},
+		retry.WithBaggage(func(ctx context.Context, attempt uint64) context.Context {
+			setAttempts = append(setAttempts, attempt)
+
+			count, _ := retry.GetRetryCount(ctx)
+			retryCounts = append(retryCounts, count)
+
+			return context.WithValue(ctx, baggageKey{}, attempt)
+		}))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if got, want := setAttempts, []uint64{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := retryCounts, []uint64{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if ctx.Value(baggageKey{}) != nil {
+		t.Error("expected caller context to be unchanged")
+	}
+	if _, ok := retry.GetRetryCount(ctx); ok {
+		t.Error("expected caller context to have no retry count")
+	}
+}
+
+func TestWithReclassification(t *testing.T) {
+	t.Parallel()
+
+	errReset := errors.New("connection reset")
+	errAuth := errors.New("unauthenticated")
+
+	reclassify := func(_ context.Context, err error) error {
+		if errors.Is(err, errReset) {
+			// A cheap probe reveals the real cause.
+			return errAuth
+		}
+		return err
+	}
+
+	t.Run("upgrades_to_permanent", func(t *testing.T) {
+		t.Parallel()
+
+		clock := newFakeClock()
+		start := clock.Now()
+
+		var attempts int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(5, retry.NewConstant(1*time.Second)), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(errReset)
+		}, retry.WithClock(clock), retry.WithReclassification(50*time.Millisecond, reclassify))
+
+		if got, want := err, errAuth; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := attempts, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := clock.Now().Sub(start), 50*time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		errOther := errors.New("other")
+
+		var attempts int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(errOther)
+		}, retry.WithReclassification(1*time.Nanosecond, reclassify))
+
+		if got, want := err, errOther; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := attempts, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var reclassified bool
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return retry.RetryableError(errReset)
+		}, retry.WithReclassification(1*time.Hour, func(_ context.Context, err error) error {
+			reclassified = true
+			return err
+		}))
+
+		if got, want := err, context.DeadlineExceeded; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if reclassified {
+			t.Error("expected reclassify not to be called")
+		}
+	})
+}
+
+func TestWithAttemptResources(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cleanup_each_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		var events []string
+		var n int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			events = append(events, "attempt")
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+			n++
+			id := n
+			events = append(events, fmt.Sprintf("acquire %d", id))
+			return func() {
+				events = append(events, fmt.Sprintf("cleanup %d", id))
+			}, nil
+		}), retry.WithOutcomeObserver(func(o retry.Outcome) {
+			// Observers run before the sleep.
+			events = append(events, "observe")
+		}))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if got, want := events, []string{
+			"acquire 1", "attempt", "cleanup 1", "observe",
+			"acquire 2", "attempt", "cleanup 2", "observe",
+		}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("acquire_fails", func(t *testing.T) {
+		t.Parallel()
+
+		errFull := errors.New("disk full")
+
+		var acquires, cleanups int
+		var retryCounts []uint64
+		err := retry.Do(context.Background(), retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), func(ctx context.Context) error {
+			count, _ := retry.GetRetryCount(ctx)
+			retryCounts = append(retryCounts, count)
+			return nil
+		}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+			acquires++
+			if acquires == 1 {
+				return nil, retry.RetryableError(errFull)
+			}
+			return func() { cleanups++ }, nil
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The failed acquisition counts as an attempt.
+		if got, want := retryCounts, []uint64{1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := cleanups, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		err = retry.Do(context.Background(), retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			t.Error("expected no attempts")
+			return nil
+		}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+			return nil, errFull
+		}))
+		if got, want := err, errFull; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("releases_earlier_resources", func(t *testing.T) {
+		t.Parallel()
+
+		var cleanups int
+		_ = retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+			return func() { cleanups++ }, nil
+		}), retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+			return nil, errors.New("unavailable")
+		}))
+
+		if got, want := cleanups, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		var acquires, cleanups int
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("expected panic")
+				}
+			}()
+
+			_ = retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+				panic("boom")
+			}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+				acquires++
+				return func() { cleanups++ }, nil
+			}))
+		}()
+
+		if got, want := cleanups, acquires; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := cleanups, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithAttemptResources() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: write scratch files to the attempt's directory
+		return nil
+	}, retry.WithAttemptResources(func(_ context.Context) (func(), error) {
+		dir, err := os.MkdirTemp("", "attempt-")
+		if err != nil {
+			return nil, retry.RetryableError(err)
+		}
+		return func() { os.RemoveAll(dir) }, nil
+	})); err != nil {
+		// handle error
+	}
+}
+
+func TestWithDeadlineAware(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	t.Run("would_exceed", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		var calls int
+		var reason retry.StopReason
+		start := time.Now()
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(errOops)
+		}, retry.WithDeadlineAware(), retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected to return right away, took %v", elapsed)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		for _, want := range []error{retry.ErrDeadlineWouldExceed, retry.ErrExhausted, errOops} {
+			if !errors.Is(err, want) {
+				t.Errorf("expected %v to be %v", err, want)
+			}
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v not to be %v", err, context.DeadlineExceeded)
+		}
+		if got, want := reason, retry.ReasonDeadline; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("fits", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		var calls int
+		if err := retry.Do(ctx, retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+			calls++
+			if calls < 3 {
+				return retry.RetryableError(errOops)
+			}
+			return nil
+		}, retry.WithDeadlineAware()); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("clock", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		// The clock is 1s before the deadline, so a 10s delay does not fit
+		// even though the real deadline is a minute away.
+		deadline, _ := ctx.Deadline()
+		clock := newFakeClock()
+		clock.Advance(deadline.Sub(clock.Now()) - 1*time.Second)
+
+		var calls int
+		err := retry.Do(ctx, retry.NewConstant(10*time.Second), func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(errOops)
+		}, retry.WithDeadlineAware(), retry.WithClock(clock))
+		if !errors.Is(err, retry.ErrDeadlineWouldExceed) {
+			t.Errorf("expected %v to be %v", err, retry.ErrDeadlineWouldExceed)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("no_deadline", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		if err := retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			calls++
+			if calls < 2 {
+				return retry.RetryableError(errOops)
+			}
+			return nil
+		}, retry.WithDeadlineAware(), retry.WithClock(newFakeClock())); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Without the option, Do sleeps until the deadline.
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+		if errors.Is(err, retry.ErrDeadlineWouldExceed) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrDeadlineWouldExceed)
+		}
+	})
+}
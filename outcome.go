@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"time"
+)
+
+// Outcome describes a single attempt made by [Do] or [DoValue].
+type Outcome struct {
+	// Attempt is the attempt number, starting at 1.
+	Attempt uint64
+
+	// Err is the error returned by the attempt, or nil if it succeeded.
+	Err error
+
+	// Duration is the time spent in the retry function, measured with a single
+	// clock reading before and after the call. If the attempt timed out, it is
+	// the attempt timeout, so scheduling delays after the deadline are not
+	// attributed to the attempt. Budget wrappers such as [WithMaxDuration] do
+	// not use it; they measure elapsed time with their own now function.
+	Duration time.Duration
+
+	// TimedOut is true if the attempt exceeded the timeout set with
+	// [WithAttemptTimeout].
+	TimedOut bool
+
+	// Delay is the time that will be slept before the next attempt. It is 0 if
+	// no further attempt will be made.
+	Delay time.Duration
+
+	// ProgressDropped is the number of values passed to [EmitProgress] during
+	// the attempt that were dropped because the channel set with
+	// [WithProgressChannel] was full.
+	ProgressDropped uint64
+}
+
+// WithOutcomeObserver registers a function that is called after every attempt,
+// successful or not, before sleeping. Multiple observers are called in the
+// order they were given.
+func WithOutcomeObserver(fn func(o Outcome)) DoOption {
+	return func(c *doConfig) {
+		c.onOutcome = append(c.onOutcome, fn)
+	}
+}
@@ -0,0 +1,140 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithOutcomeObserver(t *testing.T) {
+	t.Parallel()
+
+	clock := newFakeClock()
+
+	var outcomes []retry.Outcome
+	var calls int
+	if err := retry.Do(context.Background(), retry.NewConstant(5*time.Millisecond), func(_ context.Context) error {
+		calls++
+		clock.Advance(2 * time.Millisecond)
+		if calls < 3 {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+		return nil
+	}, retry.WithClock(clock), retry.WithOutcomeObserver(func(o retry.Outcome) {
+		outcomes = append(outcomes, o)
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := len(outcomes), 3; got != want {
+		t.Fatalf("expected %v to be %v", got, want)
+	}
+	for i, o := range outcomes {
+		if got, want := o.Attempt, uint64(i+1); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := o.Duration, 2*time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}
+	if got, want := outcomes[0].Delay, 5*time.Millisecond; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if o := outcomes[2]; o.Err != nil || o.Delay != 0 {
+		t.Errorf("expected successful final outcome, got %+v", o)
+	}
+}
+
+func TestWithAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	var timedOut []bool
+	err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, retry.WithAttemptTimeout(1*time.Millisecond), retry.WithOutcomeObserver(func(o retry.Outcome) {
+		timedOut = append(timedOut, o.TimedOut)
+		if got, want := o.Duration, 1*time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	}))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+	}
+	if got, want := timedOut, []bool{true, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+// TestAttemptTiming_invariant verifies that the time attributed to attempts and
+// sleeps accounts for all elapsed time, even when some attempts time out and
+// return late.
+func TestAttemptTiming_invariant(t *testing.T) {
+	t.Parallel()
+
+	const tick = 1 * time.Millisecond
+	const timeout = 100 * time.Millisecond
+
+	clock := newFakeClock()
+	start := clock.Now()
+
+	b := retry.WithMaxDuration(10*time.Second, retry.NewExponential(10*time.Millisecond), retry.WithNowFunc(clock.Now))
+
+	var slept, worked time.Duration
+	var timedOut []bool
+	var calls int
+	err := retry.Do(context.Background(), b, func(_ context.Context) error {
+		calls++
+		switch calls {
+		case 2, 4:
+			// The attempt hits its deadline, but the scheduler is late to return
+			// by less than a tick.
+			clock.Advance(timeout + 400*time.Microsecond)
+			return context.DeadlineExceeded
+		case 5:
+			clock.Advance(7 * time.Millisecond)
+			return nil
+		default:
+			clock.Advance(30 * time.Millisecond)
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+	}, retry.WithClock(clock), retry.WithAttemptTimeout(timeout), retry.WithOutcomeObserver(func(o retry.Outcome) {
+		slept += o.Delay
+		worked += o.Duration
+		timedOut = append(timedOut, o.TimedOut)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := timedOut, []bool{false, true, false, true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	elapsed := clock.Now().Sub(start)
+	if diff := elapsed - (slept + worked); diff < 0 || diff > tick {
+		t.Errorf("expected slept (%v) + worked (%v) to be within %v of elapsed (%v)", slept, worked, tick, elapsed)
+	}
+}
+
+func ExampleWithOutcomeObserver() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+	_ = retry.Do(ctx, b, func(_ context.Context) error {
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}, retry.WithOutcomeObserver(func(o retry.Outcome) {
+		fmt.Printf("attempt %d: %v\n", o.Attempt, o.Err)
+	}))
+
+	// Output:
+	// attempt 1: retryable: oops
+	// attempt 2: retryable: oops
+	// attempt 3: retryable: oops
+}
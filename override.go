@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// DelayOverrideEnv is the environment variable read by [EnableDelayOverride].
+const DelayOverrideEnv = "GO_RETRY_MAX_DELAY"
+
+// maxDelayOverride is the maximum delay slept by Do, or 0 if not overridden.
+var maxDelayOverride atomic.Int64
+
+// EnableDelayOverride reads the maximum delay from the GO_RETRY_MAX_DELAY
+// environment variable, such as "10ms", and clamps every delay that [Do] and
+// [DoValue] sleep to it, including the delays set with [WithInitialDelay] and
+// [WithReclassification]. Backoffs, budgets, and attempt counts are unchanged,
+// and outcome observers see the clamped delay. If the variable is unset or
+// empty, any previous override is removed.
+//
+// It is intended for integration tests that want retries to run quickly
+// without changing code, and is typically called from main or TestMain. The
+// environment is never read unless EnableDelayOverride is called, so stray
+// variables cannot affect production binaries.
+func EnableDelayOverride() error {
+	v := os.Getenv(DelayOverrideEnv)
+	if v == "" {
+		DisableDelayOverride()
+		return nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", DelayOverrideEnv, err)
+	}
+	if d < 0 {
+		return fmt.Errorf("invalid %s: must not be negative", DelayOverrideEnv)
+	}
+
+	// A zero override sleeps for 1ns rather than disabling the override.
+	if d == 0 {
+		d = 1
+	}
+	maxDelayOverride.Store(int64(d))
+	return nil
+}
+
+// DisableDelayOverride removes the override set by [EnableDelayOverride].
+func DisableDelayOverride() {
+	maxDelayOverride.Store(0)
+}
+
+// overrideDelay clamps d to the delay override, if any.
+func overrideDelay(d time.Duration) time.Duration {
+	if max := time.Duration(maxDelayOverride.Load()); max > 0 && d > max {
+		return max
+	}
+	return d
+}
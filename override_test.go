@@ -0,0 +1,89 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// TestEnableDelayOverride is not parallel because the override is global.
+func TestEnableDelayOverride(t *testing.T) {
+	t.Cleanup(retry.DisableDelayOverride)
+
+	run := func() []time.Duration {
+		clock := newFakeClock()
+
+		var delays []time.Duration
+		var attempts int
+		if err := retry.Do(context.Background(), retry.WithMaxRetries(4, retry.NewExponential(5*time.Millisecond)), func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithClock(clock), retry.WithOutcomeObserver(func(o retry.Outcome) {
+			if o.Delay > 0 {
+				delays = append(delays, o.Delay)
+			}
+		})); err == nil {
+			t.Fatal("expected error")
+		}
+
+		if got, want := attempts, 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		return delays
+	}
+
+	t.Setenv(retry.DelayOverrideEnv, "10ms")
+
+	// The environment is ignored until the override is enabled.
+	if got, want := run(), []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	if err := retry.EnableDelayOverride(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := run(), []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	// The initial delay and the reclassification delay are clamped too.
+	for name, opt := range map[string]retry.DoOption{
+		"initial_delay": retry.WithInitialDelay(1 * time.Hour),
+		"reclassification": retry.WithReclassification(1*time.Hour, func(_ context.Context, _ error) error {
+			return nil
+		}),
+	} {
+		clock := newFakeClock()
+		start := clock.Now()
+
+		var attempts int
+		if err := retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			if attempts++; attempts == 1 && name == "reclassification" {
+				return fmt.Errorf("ambiguous")
+			}
+			return nil
+		}, opt, retry.WithClock(clock)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, want := clock.Now().Sub(start), 10*time.Millisecond; got != want {
+			t.Errorf("%s: expected %v to be %v", name, got, want)
+		}
+	}
+
+	t.Setenv(retry.DelayOverrideEnv, "soon")
+	if err := retry.EnableDelayOverride(); err == nil {
+		t.Error("expected error for invalid duration")
+	}
+
+	t.Setenv(retry.DelayOverrideEnv, "")
+	if err := retry.EnableDelayOverride(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := run()[3], 40*time.Millisecond; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package retry
+
+import (
+	"math"
+	"time"
+)
+
+// Algorithm is the base backoff algorithm of a [Policy].
+type Algorithm int
+
+const (
+	// AlgorithmConstant uses [NewConstant].
+	AlgorithmConstant Algorithm = iota
+
+	// AlgorithmExponential uses [NewExponential].
+	AlgorithmExponential
+
+	// AlgorithmFibonacci uses [NewFibonacci].
+	AlgorithmFibonacci
+)
+
+// String returns the name of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmConstant:
+		return "constant"
+	case AlgorithmExponential:
+		return "exponential"
+	case AlgorithmFibonacci:
+		return "fibonacci"
+	default:
+		return "unknown"
+	}
+}
+
+// Policy is a declarative description of a backoff built from the built-in
+// algorithms and middleware. Unlike an arbitrary [Backoff], a Policy can be
+// inspected and analyzed with [Analyze].
+//
+// Zero values for the optional fields disable the corresponding middleware.
+type Policy struct {
+	// Algorithm is the base backoff algorithm.
+	Algorithm Algorithm
+
+	// Base is the starting value for the algorithm. It must be greater than 0.
+	Base time.Duration
+
+	// Jitter adds +/- Jitter to each delay. See [WithJitter].
+	Jitter time.Duration
+
+	// JitterPercent adds +/- JitterPercent% to each delay. See
+	// [WithJitterPercent].
+	JitterPercent uint64
+
+	// Cap is the maximum value of each delay. See [WithCappedDuration].
+	Cap time.Duration
+
+	// MaxAttempts is the maximum number of attempts, including the first. A
+	// value of 0 means attempts are not limited. See [WithMaxRetries].
+	MaxAttempts uint64
+
+	// MaxDuration is the maximum total duration. See [WithMaxDuration].
+	MaxDuration time.Duration
+}
+
+// Backoff builds a new backoff from the policy. Middleware is applied in the
+// order jitter, capping, maximum attempts, and maximum duration. It panics if
+// the policy is invalid; use [TryBuild] to receive an error instead.
+func (p Policy) Backoff() Backoff {
+	return must(TryBuild(p))
+}
+
+// build builds a new backoff from the policy, which must be valid.
+func (p Policy) build() Backoff {
+	var b Backoff
+	switch p.Algorithm {
+	case AlgorithmExponential:
+		b = NewExponential(p.Base)
+	case AlgorithmFibonacci:
+		b = NewFibonacci(p.Base)
+	default:
+		b = NewConstant(p.Base)
+	}
+
+	if p.Jitter > 0 {
+		b = WithJitter(p.Jitter, b)
+	}
+	if p.JitterPercent > 0 {
+		b = WithJitterPercent(p.JitterPercent, b)
+	}
+	if p.Cap > 0 {
+		b = WithCappedDuration(p.Cap, b)
+	}
+	if p.MaxAttempts > 0 {
+		b = WithMaxRetries(p.MaxAttempts-1, b)
+	}
+	if p.MaxDuration > 0 {
+		b = WithMaxDuration(p.MaxDuration, b)
+	}
+	return b
+}
+
+// delay returns the delay before retry i, starting at 1, ignoring the total
+// duration. If worst is true, the largest possible jitter is applied; otherwise
+// jitter is ignored.
+func (p Policy) delay(i uint64, worst bool) time.Duration {
+	var d time.Duration
+	switch p.Algorithm {
+	case AlgorithmExponential:
+		if i > 63 {
+			d = math.MaxInt64
+		} else if d = p.Base << (i - 1); d <= 0 || d>>(i-1) != p.Base {
+			d = math.MaxInt64
+		}
+	case AlgorithmFibonacci:
+		prev, curr := time.Duration(0), p.Base
+		for j := uint64(0); j < i; j++ {
+			next := prev + curr
+			if next <= 0 {
+				curr = math.MaxInt64
+				break
+			}
+			prev, curr = curr, next
+		}
+		d = curr
+	default:
+		d = p.Base
+	}
+
+	if worst {
+		if p.Jitter > 0 {
+			d = addDuration(d, p.Jitter)
+		}
+		if p.JitterPercent > 0 {
+			f := float64(d) * (1 + float64(p.JitterPercent)/100)
+			if f >= math.MaxInt64 {
+				d = math.MaxInt64
+			} else {
+				d = time.Duration(f)
+			}
+		}
+	}
+
+	if p.Cap > 0 && d > p.Cap {
+		d = p.Cap
+	}
+	return d
+}
+
+// addDuration adds a and b, saturating at the maximum duration instead of
+// overflowing. Both values must be non-negative.
+func addDuration(a, b time.Duration) time.Duration {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
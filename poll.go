@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrPollTimeout is returned by [Poll] when the backoff stops before the
+// condition is met.
+var ErrPollTimeout = errors.New("retry: poll timeout")
+
+// errPollPending is the error of attempts of Poll whose value did not meet the
+// condition.
+var errPollPending = errors.New("retry: poll condition not met")
+
+// pollPending is returned to the retry loop by attempts of Poll whose value did
+// not meet the condition. It is allocated once, since it is returned on every
+// such attempt.
+var pollPending error = &retryableError{err: errPollPending}
+
+// Poll calls f repeatedly, waiting between calls according to b, until the
+// value it returns satisfies done, such as to wait for a cloud resource to
+// become active, and returns that value. Errors are handled as they are by
+// [DoValue]: retryable errors are retried, and any other error is returned
+// immediately.
+//
+// If b stops before the condition is met, Poll returns the most recent value
+// returned by f without an error, and an error wrapping [ErrPollTimeout]. If
+// the final attempt failed with a retryable error, the error wraps it too. The
+// context's error is returned when ctx is done.
+//
+// Attempts whose value did not meet the condition are reported to observers
+// registered with [WithOutcomeObserver] with a retryable error. All other
+// options behave as they do for DoValue.
+func Poll[T any](ctx context.Context, b Backoff, f RetryFuncValue[T], done func(v T) bool, opts ...DoOption) (T, error) {
+	var last T
+	var stopped bool
+	var stopErr error
+	opts = appendOptions(opts, WithStopHook(func(reason StopReason, err error) {
+		stopped = reason != ReasonGateClosed
+		stopErr = err
+	}))
+
+	v, err := DoValue(ctx, b, func(ctx context.Context) (T, error) {
+		v, err := f(ctx)
+		if err != nil {
+			return v, err
+		}
+		if done(v) {
+			return v, nil
+		}
+		last = v
+		return v, pollPending
+	}, opts...)
+	if stopped && ctx.Err() == nil {
+		if errors.Is(stopErr, errPollPending) {
+			return last, ErrPollTimeout
+		}
+		return last, fmt.Errorf("%w: %w", ErrPollTimeout, stopErr)
+	}
+	return v, err
+}
@@ -0,0 +1,143 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestPoll(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	// states returns a poll function that returns each state in turn, and
+	// repeats the last one.
+	states := func(values ...string) (retry.RetryFuncValue[string], *int) {
+		var calls int
+		return func(_ context.Context) (string, error) {
+			v := values[min(calls, len(values)-1)]
+			calls++
+			switch v {
+			case "transient":
+				return "", retry.RetryableError(errTransient)
+			case "fatal":
+				return "", errFatal
+			}
+			return v, nil
+		}, &calls
+	}
+	active := func(v string) bool { return v == "ACTIVE" }
+
+	cases := []struct {
+		name   string
+		states []string
+		val    string
+		calls  int
+		check  func(err error) bool
+	}{
+		{
+			name:   "done",
+			states: []string{"PENDING", "transient", "PENDING", "ACTIVE"},
+			val:    "ACTIVE",
+			calls:  4,
+			check:  func(err error) bool { return err == nil },
+		},
+		{
+			name:   "timeout",
+			states: []string{"PENDING", "CREATING"},
+			val:    "CREATING",
+			calls:  4,
+			check:  func(err error) bool { return err == retry.ErrPollTimeout },
+		},
+		{
+			name:   "timeout_after_error",
+			states: []string{"PENDING", "transient"},
+			val:    "PENDING",
+			calls:  4,
+			check: func(err error) bool {
+				return errors.Is(err, retry.ErrPollTimeout) && errors.Is(err, errTransient)
+			},
+		},
+		{
+			name:   "fatal",
+			states: []string{"PENDING", "fatal"},
+			val:    "",
+			calls:  2,
+			check: func(err error) bool {
+				return err == errFatal
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, calls := states(tc.states...)
+			b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Second))
+			val, err := retry.Poll(context.Background(), b, f, active, retry.WithClock(newFakeClock()))
+			if !tc.check(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got, want := val, tc.val; got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+			if got, want := *calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPoll_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := retry.Poll(ctx, retry.NewConstant(1*time.Second), func(_ context.Context) (int, error) {
+		cancel()
+		return 1, nil
+	}, func(v int) bool { return v > 1 }, retry.WithClock(newFakeClock()))
+	if got, want := err, context.Canceled; !errors.Is(got, want) {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if errors.Is(err, retry.ErrPollTimeout) {
+		t.Errorf("expected %v not to be %v", err, retry.ErrPollTimeout)
+	}
+}
+
+func ExamplePoll() {
+	ctx := context.Background()
+
+	// getState stands in for a cloud API returning the state of a resource.
+	var calls int
+	getState := func(_ context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "CREATING", nil
+		}
+		return "ACTIVE", nil
+	}
+
+	b := retry.WithMaxRetries(10, retry.NewConstant(1*time.Millisecond))
+	state, err := retry.Poll(ctx, b, getState, func(state string) bool {
+		return state == "ACTIVE"
+	})
+	if errors.Is(err, retry.ErrPollTimeout) {
+		fmt.Println("gave up in state", state)
+		return
+	}
+	if err != nil {
+		// handle error
+		return
+	}
+	fmt.Println(state)
+	// Output: ACTIVE
+}
@@ -0,0 +1,103 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// progressBuffer is the number of outcomes buffered by DoWithChannel.
+const progressBuffer = 64
+
+// DoWithChannel runs [Do] in a new goroutine and sends the [Outcome] of each
+// attempt, in order, on the returned channel, for progress reporting without
+// logging inside f. The channel is closed when retrying ends. The returned
+// function blocks until then and returns the error from Do.
+//
+// The channel buffers 64 outcomes and sending never blocks the retry loop. If
+// the caller falls further behind, outcomes are dropped until it catches up,
+// which shows as a gap in Outcome.Attempt.
+func DoWithChannel(ctx context.Context, b Backoff, f RetryFunc, opts ...DoOption) (<-chan Outcome, func() error) {
+	ch := make(chan Outcome, progressBuffer)
+	done := make(chan struct{})
+
+	var err error
+	go func() {
+		defer close(done)
+		defer close(ch)
+
+		err = Do(ctx, b, f, appendOptions(opts, WithOutcomeObserver(func(o Outcome) {
+			select {
+			case ch <- o:
+			default:
+			}
+		}))...)
+	}()
+
+	return ch, func() error {
+		<-done
+		return err
+	}
+}
+
+// WithProgressChannel lets the retry function report partial progress, such as
+// bytes transferred, with [EmitProgress] before it succeeds. Values are sent to
+// ch without blocking: a value that does not fit in the channel's buffer is
+// dropped and counted in [Outcome].ProgressDropped. Nothing is sent once the
+// attempt that emitted it has completed, so nothing is sent after [Do] or
+// [DoValue] returns. The channel is never closed.
+func WithProgressChannel[T any](ch chan<- T) DoOption {
+	return func(c *doConfig) {
+		c.progress = func(ctx context.Context) (context.Context, func() uint64) {
+			s := &progressSink[T]{ch: ch}
+			return context.WithValue(ctx, progressKey[T]{}, s), s.close
+		}
+	}
+}
+
+// EmitProgress sends v to the channel set with [WithProgressChannel] for the
+// attempt whose context is ctx, without blocking. It reports whether v was
+// sent. It returns false if v was dropped because the channel was full, the
+// attempt has completed, or no channel of values of type T was set.
+func EmitProgress[T any](ctx context.Context, v T) bool {
+	s, ok := ctx.Value(progressKey[T]{}).(*progressSink[T])
+	if !ok {
+		return false
+	}
+	return s.send(v)
+}
+
+type progressKey[T any] struct{}
+
+// progressSink forwards the progress values of a single attempt.
+type progressSink[T any] struct {
+	ch chan<- T
+
+	lock    sync.Mutex
+	closed  bool
+	dropped uint64
+}
+
+func (s *progressSink[T]) send(v T) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return false
+	}
+	select {
+	case s.ch <- v:
+		return true
+	default:
+		s.dropped++
+		return false
+	}
+}
+
+// close stops forwarding values and returns the number of values dropped.
+func (s *progressSink[T]) close() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	return s.dropped
+}
@@ -0,0 +1,307 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithChannel(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	t.Run("ordered", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		ch, wait := retry.DoWithChannel(context.Background(), retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+			calls++
+			if calls < 5 {
+				return retry.RetryableError(errOops)
+			}
+			return nil
+		})
+
+		var outcomes []retry.Outcome
+		for o := range ch {
+			outcomes = append(outcomes, o)
+		}
+		if err := wait(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := len(outcomes), 5; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i, o := range outcomes[:4] {
+			if got, want := o.Attempt, uint64(i+1); got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if !errors.Is(o.Err, errOops) {
+				t.Errorf("expected %v to be %v", o.Err, errOops)
+			}
+			if got, want := o.Delay, 1*time.Millisecond; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}
+		if o := outcomes[4]; o.Attempt != 5 || o.Err != nil || o.Delay != 0 {
+			t.Errorf("expected successful final outcome, got %+v", o)
+		}
+	})
+
+	t.Run("final_error", func(t *testing.T) {
+		t.Parallel()
+
+		ch, wait := retry.DoWithChannel(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+
+		err := wait()
+		if !errors.Is(err, errOops) || !errors.Is(err, retry.ErrExhausted) {
+			t.Errorf("expected %v to be exhausted with %v", err, errOops)
+		}
+
+		// The channel is closed once wait returns.
+		var n int
+		for range ch {
+			n++
+		}
+		if got, want := n, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("never_blocks", func(t *testing.T) {
+		t.Parallel()
+
+		// Nobody reads the channel until the loop has ended.
+		ch, wait := retry.DoWithChannel(context.Background(), retry.WithMaxRetries(199, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+
+		done := make(chan error, 1)
+		go func() {
+			done <- wait()
+		}()
+		select {
+		case err := <-done:
+			if !errors.Is(err, errOops) {
+				t.Errorf("expected %v to be %v", err, errOops)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("retry loop blocked on the channel")
+		}
+
+		// The first outcomes were buffered and the rest dropped.
+		var last uint64
+		var n int
+		for o := range ch {
+			if o.Attempt <= last {
+				t.Errorf("expected attempt %v after %v", o.Attempt, last)
+			}
+			last = o.Attempt
+			n++
+		}
+		if got, want := n, 64; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		ch, wait := retry.DoWithChannel(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+
+		// Cancel once the first attempt has been reported.
+		o := <-ch
+		if got, want := o.Attempt, uint64(1); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		cancel()
+
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed")
+		}
+		if err := wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestWithProgressChannel(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	t.Run("emits", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan int, 10)
+		var attempts int
+		got, err := retry.DoValue(context.Background(), retry.NewConstant(1*time.Nanosecond), func(ctx context.Context) (string, error) {
+			attempts++
+			if attempts == 1 {
+				for _, v := range []int{1, 2} {
+					if !retry.EmitProgress(ctx, v) {
+						t.Errorf("expected %v to be sent", v)
+					}
+				}
+				return "", retry.RetryableError(errOops)
+			}
+			retry.EmitProgress(ctx, 3)
+			return "done", nil
+		}, retry.WithProgressChannel[int](ch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := got, "done"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The channel is not closed.
+		var values []int
+		for len(ch) > 0 {
+			values = append(values, <-ch)
+		}
+		if got, want := fmt.Sprint(values), "[1 2 3]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("drops", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan int, 2)
+		var dropped []uint64
+		var attempts int
+		err := retry.Do(context.Background(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(ctx context.Context) error {
+			attempts++
+			for v := 0; v < 5; v++ {
+				retry.EmitProgress(ctx, v)
+			}
+			return retry.RetryableError(errOops)
+		}, retry.WithProgressChannel[int](ch), retry.WithOutcomeObserver(func(o retry.Outcome) {
+			dropped = append(dropped, o.ProgressDropped)
+		}))
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
+
+		// The first attempt fills the channel, so every value of the second
+		// is dropped.
+		if got, want := fmt.Sprint(dropped), "[3 5]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := len(ch), 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("after_return", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan int, 10)
+		emitted := make(chan bool)
+		release := make(chan struct{})
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(ctx context.Context) error {
+			// The goroutine outlives the attempt and emits once Do has returned.
+			go func() {
+				<-release
+				emitted <- retry.EmitProgress(ctx, 1)
+			}()
+			return nil
+		}, retry.WithProgressChannel[int](ch))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		close(release)
+		if <-emitted {
+			t.Error("expected no send after Do returned")
+		}
+		if got, want := len(ch), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("no_channel", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan int, 10)
+		if err := retry.Do(context.Background(), retry.NewConstant(1*time.Nanosecond), func(ctx context.Context) error {
+			if retry.EmitProgress(ctx, "wrong type") {
+				t.Error("expected a value of another type not to be sent")
+			}
+			return nil
+		}, retry.WithProgressChannel[int](ch)); err != nil {
+			t.Fatal(err)
+		}
+
+		if retry.EmitProgress(context.Background(), 1) {
+			t.Error("expected no send outside an attempt")
+		}
+	})
+}
+
+func ExampleDoWithChannel() {
+	ctx := context.Background()
+
+	b := retry.NewConstant(1 * time.Nanosecond)
+
+	var calls int
+	ch, wait := retry.DoWithChannel(ctx, b, func(_ context.Context) error {
+		calls++
+		if calls < 3 {
+			return retry.RetryableError(fmt.Errorf("not ready"))
+		}
+		return nil
+	})
+
+	for o := range ch {
+		fmt.Printf("attempt %d: %v\n", o.Attempt, o.Err)
+	}
+	if err := wait(); err != nil {
+		// handle error
+	}
+
+	// Output:
+	// attempt 1: retryable: not ready
+	// attempt 2: retryable: not ready
+	// attempt 3: <nil>
+}
+
+func ExampleEmitProgress() {
+	ctx := context.Background()
+
+	b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+	// Report how many chunks of an upload have been sent.
+	progress := make(chan int, 10)
+	if err := retry.Do(ctx, b, func(ctx context.Context) error {
+		for chunk := 1; chunk <= 3; chunk++ {
+			retry.EmitProgress(ctx, chunk)
+		}
+		return nil
+	}, retry.WithProgressChannel[int](progress)); err != nil {
+		// handle error
+	}
+
+	for len(progress) > 0 {
+		fmt.Printf("sent chunk %d\n", <-progress)
+	}
+
+	// Output:
+	// sent chunk 1
+	// sent chunk 2
+	// sent chunk 3
+}
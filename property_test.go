@@ -0,0 +1,369 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// scenario is a randomly generated call to Do: a backoff built from a random
+// chain of wrappers, the outcome of each attempt, and an optional point at
+// which the context is canceled.
+type scenario struct {
+	seed int64
+
+	algorithm string
+	base      time.Duration
+
+	// middleware are the wrappers applied between the algorithm and the cap,
+	// in order.
+	middleware    []string
+	jitter        time.Duration
+	jitterPercent uint64
+	minDuration   time.Duration
+	retries       uint64
+
+	// cap and maxDuration are applied outermost, in that order. Zero means the
+	// wrapper is not used.
+	cap         time.Duration
+	maxDuration time.Duration
+
+	// Attempt i, starting at 1, takes durations[i-1] and fails with a retryable
+	// error, unless it is successAt or permanentAt. A retryable error asks for
+	// after[i-1] instead of the backoff's delay if it is not negative.
+	durations   []time.Duration
+	after       []time.Duration
+	successAt   int
+	permanentAt int
+
+	// The context is canceled during attempt cancelAttempt or during sleep
+	// cancelSleep, if not zero. Sleep i follows attempt i.
+	cancelAttempt int
+	cancelSleep   int
+}
+
+func newScenario(seed int64) *scenario {
+	r := rand.New(rand.NewSource(seed))
+	duration := func(max time.Duration) time.Duration {
+		return time.Duration(r.Int63n(int64(max)))
+	}
+	maybe := func(n int) int {
+		if r.Intn(2) == 0 {
+			return 0
+		}
+		return 1 + r.Intn(n)
+	}
+
+	s := &scenario{
+		seed:      seed,
+		algorithm: []string{"constant", "linear", "exponential", "fibonacci"}[r.Intn(4)],
+		base:      1*time.Millisecond + duration(1*time.Second),
+		retries:   uint64(r.Intn(9)),
+	}
+
+	s.middleware = []string{"retries"}
+	for _, m := range []string{"jitter", "jitter_percent", "min"} {
+		if r.Intn(3) == 0 {
+			s.middleware = append(s.middleware, m)
+		}
+	}
+	r.Shuffle(len(s.middleware), func(i, j int) {
+		s.middleware[i], s.middleware[j] = s.middleware[j], s.middleware[i]
+	})
+	s.jitter = 1 + duration(2*time.Second)
+	s.jitterPercent = 1 + uint64(r.Intn(100))
+	s.minDuration = 1 + duration(500*time.Millisecond)
+
+	if r.Intn(2) == 0 {
+		s.cap = 1 + duration(5*time.Second)
+	}
+	if r.Intn(2) == 0 {
+		s.maxDuration = 1 + duration(20*time.Second)
+	}
+
+	budget := int(s.retries) + 1
+	for i := 0; i < budget; i++ {
+		s.durations = append(s.durations, duration(200*time.Millisecond))
+		after := time.Duration(-1)
+		if r.Intn(4) == 0 {
+			after = duration(3 * time.Second)
+		}
+		s.after = append(s.after, after)
+	}
+	s.successAt = maybe(budget + 1)
+	s.permanentAt = maybe(budget + 1)
+	switch r.Intn(3) {
+	case 1:
+		s.cancelAttempt = 1 + r.Intn(budget+1)
+	case 2:
+		s.cancelSleep = 1 + r.Intn(budget+1)
+	}
+	return s
+}
+
+func (s *scenario) String() string {
+	return fmt.Sprintf("%+v", *s)
+}
+
+// budget returns the maximum number of attempts.
+func (s *scenario) budget() int {
+	return int(s.retries) + 1
+}
+
+// backoff builds the backoff of s, reading the time from now.
+func (s *scenario) backoff(now func() time.Time) retry.Backoff {
+	var b retry.Backoff
+	switch s.algorithm {
+	case "constant":
+		b = retry.NewConstant(s.base)
+	case "linear":
+		b = retry.NewLinear(s.base)
+	case "exponential":
+		b = retry.NewExponential(s.base)
+	case "fibonacci":
+		b = retry.NewFibonacci(s.base)
+	}
+
+	for _, m := range s.middleware {
+		switch m {
+		case "retries":
+			b = retry.WithMaxRetries(s.retries, b)
+		case "jitter":
+			b = retry.WithJitter(s.jitter, b, retry.WithRandSeed(s.seed))
+		case "jitter_percent":
+			b = retry.WithJitterPercent(s.jitterPercent, b, retry.WithRandSeed(s.seed))
+		case "min":
+			b = retry.WithMinDuration(s.minDuration, b)
+		}
+	}
+
+	if s.cap > 0 {
+		b = retry.WithCappedDuration(s.cap, b)
+	}
+	if s.maxDuration > 0 {
+		b = retry.WithMaxDuration(s.maxDuration, b, retry.WithNowFunc(now))
+	}
+	return b
+}
+
+// expectedAttempts returns the number of attempts Do makes for s, and whether
+// the number is exact. The number is an upper bound if a time budget may stop
+// retrying early.
+func (s *scenario) expectedAttempts() (int, bool) {
+	n := s.budget()
+	for _, stop := range []int{s.successAt, s.permanentAt, s.cancelAttempt, s.cancelSleep} {
+		if stop > 0 && stop < n {
+			n = stop
+		}
+	}
+	return n, s.maxDuration == 0
+}
+
+// scenarioClock is a fake clock that records each completed sleep, and
+// cancels the context instead of completing sleep number cancelAt.
+type scenarioClock struct {
+	*fakeClock
+	slept    []time.Duration
+	sleeps   int
+	cancelAt int
+	cancel   context.CancelFunc
+}
+
+func (c *scenarioClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps++
+	if c.sleeps == c.cancelAt {
+		c.cancel()
+	}
+	if err := c.fakeClock.Sleep(ctx, d); err != nil {
+		return err
+	}
+	c.slept = append(c.slept, d)
+	return nil
+}
+
+// checkScenario runs s through Do and checks the invariants that hold for
+// every scenario.
+func checkScenario(t *testing.T, s *scenario) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := &scenarioClock{fakeClock: newFakeClock(), cancelAt: s.cancelSleep, cancel: cancel}
+	start := clock.Now()
+	b := s.backoff(clock.Now)
+
+	errs := make([]error, s.budget())
+	for i := range errs {
+		errs[i] = fmt.Errorf("attempt %d failed", i+1)
+	}
+
+	var calls, outcomes, retries, stops, cancels int
+	var phase retry.CancelPhase
+	var attemptTime time.Duration
+	err := retry.Do(ctx, b, func(_ context.Context) error {
+		calls++
+		if calls > s.budget() {
+			t.Fatalf("%v: expected at most %d attempts", s, s.budget())
+		}
+		d := s.durations[calls-1]
+		clock.Advance(d)
+		attemptTime += d
+
+		if calls == s.cancelAttempt {
+			cancel()
+		}
+		switch {
+		case calls == s.successAt:
+			return nil
+		case calls == s.permanentAt:
+			return errs[calls-1]
+		case s.after[calls-1] >= 0:
+			return retry.RetryableErrorAfter(errs[calls-1], s.after[calls-1])
+		default:
+			return retry.RetryableError(errs[calls-1])
+		}
+	},
+		retry.WithClock(clock),
+		retry.WithOutcomeObserver(func(o retry.Outcome) {
+			outcomes++
+			if got, want := o.Attempt, uint64(outcomes); got != want {
+				t.Errorf("%v: expected outcome attempt %v to be %v", s, got, want)
+			}
+		}),
+		retry.WithHooks(retry.Hooks{
+			OnRetry: func(attempt uint64, _ time.Duration, _ error) {
+				retries++
+				if got, want := attempt, uint64(retries); got != want {
+					t.Errorf("%v: expected retry attempt %v to be %v", s, got, want)
+				}
+			},
+			OnCancel: func(_ uint64, p retry.CancelPhase, _ error) {
+				cancels++
+				phase = p
+			},
+		}),
+		retry.WithStopHook(func(_ retry.StopReason, _ error) {
+			stops++
+		}),
+	)
+
+	// f is called between 1 and budget times, exactly as often as the model
+	// predicts unless a time budget stops retrying early.
+	want, exact := s.expectedAttempts()
+	if calls < 1 {
+		t.Fatalf("%v: expected at least 1 attempt", s)
+	}
+	if exact && calls != want {
+		t.Errorf("%v: expected %d attempts, got %d", s, want, calls)
+	}
+	if !exact && calls > want {
+		t.Errorf("%v: expected at most %d attempts, got %d", s, want, calls)
+	}
+	if outcomes != calls {
+		t.Errorf("%v: expected %d outcomes, got %d", s, calls, outcomes)
+	}
+
+	// The error is nil, the permanent error, the context's error, or the
+	// exhausted root cause, according to the last attempt.
+	last := errs[calls-1]
+	canceled := errors.Is(err, context.Canceled)
+	exhausted := errors.Is(err, retry.ErrExhausted)
+	switch {
+	case calls == s.successAt:
+		if err != nil {
+			t.Errorf("%v: expected %v to be nil", s, err)
+		}
+	case calls == s.permanentAt:
+		if err != last {
+			t.Errorf("%v: expected %v to be %v", s, err, last)
+		}
+	case ctx.Err() != nil && (canceled || exhausted):
+		if !errors.Is(err, last) {
+			t.Errorf("%v: expected %v to be %v", s, err, last)
+		}
+	default:
+		if !exhausted || !errors.Is(err, last) {
+			t.Errorf("%v: expected %v to be exhausted with %v", s, err, last)
+		}
+	}
+	if canceled && exhausted {
+		t.Errorf("%v: expected %v not to be both canceled and exhausted", s, err)
+	}
+
+	// Hooks are called once per retry, the stop hook once on exhaustion, and
+	// the cancel hook once on cancellation. OnRetry is also called for the
+	// last attempt if the context was canceled while waiting after it.
+	wantRetries := calls - 1
+	if canceled && phase == retry.CancelBeforeAttempt {
+		wantRetries = calls
+	}
+	if retries != wantRetries {
+		t.Errorf("%v: expected %d retries, got %d", s, wantRetries, retries)
+	}
+	wantStops := 0
+	if exhausted {
+		wantStops = 1
+	}
+	if stops != wantStops {
+		t.Errorf("%v: expected %d stop hook calls, got %d", s, wantStops, stops)
+	}
+	wantCancels := 0
+	if canceled {
+		wantCancels = 1
+	}
+	if cancels != wantCancels {
+		t.Errorf("%v: expected %d cancel hook calls, got %d", s, wantCancels, cancels)
+	}
+
+	// Every completed sleep is between attempts and within the cap.
+	if got, want := len(clock.slept), calls-1; got != want {
+		t.Errorf("%v: expected %d sleeps, got %d", s, want, got)
+	}
+	var slept time.Duration
+	for _, d := range clock.slept {
+		if d < 0 || (s.cap > 0 && d > s.cap) {
+			t.Errorf("%v: sleep %v is outside [0, %v]", s, d, s.cap)
+		}
+		slept += d
+	}
+
+	// Time only passes during attempts and sleeps, and no attempt starts after
+	// the time budget.
+	elapsed := clock.Now().Sub(start)
+	if got, want := elapsed, slept+attemptTime; got != want {
+		t.Errorf("%v: expected elapsed %v to be %v", s, got, want)
+	}
+	if s.maxDuration > 0 {
+		if final := s.durations[calls-1]; elapsed > s.maxDuration+final {
+			t.Errorf("%v: elapsed %v exceeds budget %v plus final attempt %v", s, elapsed, s.maxDuration, final)
+		}
+	}
+}
+
+func TestDo_properties(t *testing.T) {
+	t.Parallel()
+
+	const cases = 5000
+	for seed := int64(0); seed < cases; seed++ {
+		checkScenario(t, newScenario(seed))
+		if t.Failed() {
+			t.Fatalf("failed with seed %d", seed)
+		}
+	}
+}
+
+func FuzzDo(f *testing.F) {
+	for seed := int64(0); seed < 10; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		checkScenario(t, newScenario(seed))
+	})
+}
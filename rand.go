@@ -13,7 +13,11 @@ type lockedSource struct {
 var _ rand.Source64 = (*lockedSource)(nil)
 
 func newLockedRandom(seed int64) *lockedSource {
-	return &lockedSource{src: rand.New(rand.NewSource(seed))}
+	return newLockedSource(rand.NewSource(seed))
+}
+
+func newLockedSource(src rand.Source) *lockedSource {
+	return &lockedSource{src: rand.New(src)}
 }
 
 // Int63 mimics math/rand.(*Rand).Int63 with mutex locked.
@@ -0,0 +1,33 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// DoRedirect wraps a function that calls one of several targets, such as the
+// nodes of a Raft or etcd cluster, with a backoff to retry. The first attempt
+// calls f with initial. When an attempt fails and f returns a non-zero next
+// target, such as the leader named in a "not leader" error, the following
+// attempt uses it; otherwise the previous target is reused.
+//
+// If retrying fails, the returned error wraps the final error and includes the
+// target of the last attempt.
+func DoRedirect[T any](ctx context.Context, b Backoff, initial T, f func(ctx context.Context, target T) (next T, err error), opts ...DoOption) error {
+	target := initial
+	tried := initial
+
+	if err := Do(ctx, b, func(ctx context.Context) error {
+		tried = target
+
+		next, err := f(ctx, target)
+		if err != nil && !reflect.ValueOf(&next).Elem().IsZero() {
+			target = next
+		}
+		return err
+	}, opts...); err != nil {
+		return fmt.Errorf("retry: last target %v: %w", tried, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoRedirect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("leader_moves", func(t *testing.T) {
+		t.Parallel()
+
+		leader := "b"
+		var targets []string
+		var electing bool
+
+		err := retry.DoRedirect(context.Background(), retry.WithMaxRetries(5, retry.NewConstant(1*time.Nanosecond)), "a",
+			func(_ context.Context, node string) (string, error) {
+				targets = append(targets, node)
+
+				if node == "b" && !electing {
+					// The leader steps down mid-request without knowing its
+					// successor yet.
+					electing = true
+					leader = "c"
+					return "", retry.RetryableError(fmt.Errorf("election in progress"))
+				}
+				if node != leader {
+					return leader, retry.RetryableError(fmt.Errorf("not leader, try %s", leader))
+				}
+				return "", nil
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := targets, []string{"a", "b", "b", "c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("last_target", func(t *testing.T) {
+		t.Parallel()
+
+		errUnavailable := errors.New("unavailable")
+
+		err := retry.DoRedirect(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), 1,
+			func(_ context.Context, node int) (int, error) {
+				return node + 1, retry.RetryableError(errUnavailable)
+			})
+
+		if !errors.Is(err, errUnavailable) {
+			t.Errorf("expected %v to be %v", err, errUnavailable)
+		}
+		if got, want := err.Error(), "retry: last target 3: unavailable"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+}
+
+func ExampleDoRedirect() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Millisecond))
+
+	leader := "node-2"
+	if err := retry.DoRedirect(ctx, b, "node-1", func(_ context.Context, node string) (string, error) {
+		fmt.Println("calling", node)
+		if node != leader {
+			// The error names the current leader.
+			return leader, retry.RetryableError(fmt.Errorf("not leader"))
+		}
+		return "", nil
+	}); err != nil {
+		// handle error
+	}
+
+	// Output:
+	// calling node-1
+	// calling node-2
+}
@@ -0,0 +1,158 @@
+package retry
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+var _ ErrorBackoff = (*localRefundsBackoff)(nil)
+
+type localFailureError struct {
+	err error
+}
+
+// Unwrap implements error wrapping.
+func (e *localFailureError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *localFailureError) Error() string {
+	return e.err.Error()
+}
+
+// LocalFailure marks an error as retryable, like [RetryableError], and as a
+// failure that never reached the network, such as a marshaling error or a
+// misconfigured resolver. [WithLocalRefunds] does not charge the budgets of
+// its backoff for such failures. The error returned when retrying stops still
+// matches err with [errors.Is] and has the same message.
+func LocalFailure(err error) error {
+	if err == nil {
+		return nil
+	}
+	if rerr, ok := err.(*retryableError); ok {
+		return &retryableError{err: &localFailureError{err: rerr.err}, delay: rerr.delay, hasDelay: rerr.hasDelay}
+	}
+	return &retryableError{err: &localFailureError{err: err}}
+}
+
+// IsLocalFailure reports whether err, or any error it wraps, was marked with
+// [LocalFailure].
+func IsLocalFailure(err error) bool {
+	var lerr *localFailureError
+	return errors.As(err, &lerr)
+}
+
+// WithLocalRefunds refunds retries of local failures, marked with
+// [LocalFailure]: for up to maxRefunds such failures, it returns delay, a small
+// fixed delay that avoids a hot loop, without consulting next, so the retry is
+// not charged to the budgets of next, such as [WithMaxRetries] or
+// [WithErrorBudgets]. Once maxRefunds local failures were refunded, further
+// local failures are charged like any other. The cap ensures that a function
+// that always fails locally still stops.
+//
+// Refunded retries are not limited by next, including [WithMaxDuration], but
+// once next stops, the returned backoff keeps stopping, even for local
+// failures. The returned backoff implements [ErrorBackoff] and must be the
+// outermost wrapper. Other errors are passed to next, through NextError if
+// next implements ErrorBackoff. The returned backoff has a Reset method, which
+// clears the refunds and any stop, and resets next if it has a Reset method.
+//
+// It panics if delay is negative or next is nil. It is safe for concurrent use
+// if next is safe for concurrent use.
+func WithLocalRefunds(maxRefunds uint64, delay time.Duration, next Backoff) Backoff {
+	return must(WithLocalRefundsE(maxRefunds, delay, next))
+}
+
+// WithLocalRefundsE is like [WithLocalRefunds], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithLocalRefundsE(maxRefunds uint64, delay time.Duration, next Backoff) (Backoff, error) {
+	if delay < 0 {
+		return nil, &ValidationError{Field: "delay", Reason: "must not be negative"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &localRefundsBackoff{
+		max:   maxRefunds,
+		delay: delay,
+		next:  next,
+	}, nil
+}
+
+type localRefundsBackoff struct {
+	max   uint64
+	delay time.Duration
+	next  Backoff
+
+	refunds atomic.Uint64
+	stopped atomic.Bool
+}
+
+// Next implements Backoff.
+func (b *localRefundsBackoff) Next() (time.Duration, bool) {
+	if b.stopped.Load() {
+		return 0, true
+	}
+	return b.stop(b.next.Next())
+}
+
+// NextError implements ErrorBackoff.
+func (b *localRefundsBackoff) NextError(err error) (time.Duration, bool) {
+	// A refund would resume a backoff that already stopped.
+	if b.stopped.Load() {
+		return 0, true
+	}
+
+	if IsLocalFailure(err) {
+		for {
+			n := b.refunds.Load()
+			if n >= b.max {
+				break
+			}
+			if b.refunds.CompareAndSwap(n, n+1) {
+				return b.delay, false
+			}
+		}
+	}
+
+	if eb, ok := b.next.(ErrorBackoff); ok {
+		return b.stop(eb.NextError(err))
+	}
+	return b.stop(b.next.Next())
+}
+
+// stop records a stop from next, so it is sticky.
+func (b *localRefundsBackoff) stop(val time.Duration, stop bool) (time.Duration, bool) {
+	if stop {
+		b.stopped.Store(true)
+	}
+	return val, stop
+}
+
+// Reset clears the refunds and any stop, and resets next if it has a Reset
+// method.
+func (b *localRefundsBackoff) Reset() {
+	b.refunds.Store(0)
+	b.stopped.Store(false)
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// MaxRefunds returns the configured maximum number of refunds.
+func (b *localRefundsBackoff) MaxRefunds() uint64 {
+	return b.max
+}
+
+// Refunds returns the number of local failures refunded so far.
+func (b *localRefundsBackoff) Refunds() uint64 {
+	return b.refunds.Load()
+}
+
+// Unwrap implements Wrapper.
+func (b *localRefundsBackoff) Unwrap() Backoff {
+	return b.next
+}
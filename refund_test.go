@@ -0,0 +1,172 @@
+package retry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestLocalFailure(t *testing.T) {
+	t.Parallel()
+
+	errLocal := errors.New("marshal failed")
+
+	if got := retry.LocalFailure(nil); got != nil {
+		t.Errorf("expected %v to be nil", got)
+	}
+
+	err := retry.LocalFailure(errLocal)
+	if !retry.IsLocalFailure(err) {
+		t.Errorf("expected %v to be a local failure", err)
+	}
+	if !errors.Is(err, errLocal) {
+		t.Errorf("expected %v to be %v", err, errLocal)
+	}
+	if retry.IsLocalFailure(retry.RetryableError(errLocal)) {
+		t.Errorf("expected retryable error not to be a local failure")
+	}
+
+	// Marking an already retryable error keeps it retryable once.
+	err = retry.LocalFailure(retry.RetryableError(errLocal))
+	if got, want := err.Error(), "retryable: marshal failed"; got != want {
+		t.Errorf("expected %q to be %q", got, want)
+	}
+}
+
+func TestWithLocalRefunds(t *testing.T) {
+	t.Parallel()
+
+	errLocal := retry.LocalFailure(errors.New("dns misconfigured"))
+	errRemote := retry.RetryableError(errors.New("503"))
+
+	t.Run("budget", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithLocalRefunds(10, 5*time.Millisecond, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+
+		// Local failures between remote ones do not consume the 2 retries.
+		sequence := []error{errLocal, errRemote, errLocal, errLocal, errRemote, errLocal, errRemote}
+		var calls int
+		var delays []time.Duration
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			err := sequence[calls]
+			calls++
+			return err
+		}, retry.WithClock(newFakeClock()), retry.WithHooks(retry.Hooks{
+			OnRetry: func(_ uint64, delay time.Duration, _ error) {
+				delays = append(delays, delay)
+			},
+		}))
+		if err == nil || retry.IsLocalFailure(err) {
+			t.Errorf("expected remote error, got %v", err)
+		}
+		if got, want := calls, 7; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(delays), "[5ms 1s 5ms 5ms 1s 5ms]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := b.(interface{ Refunds() uint64 }).Refunds(), uint64(4); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("cap", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithLocalRefunds(3, 5*time.Millisecond, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+
+		// A function that always fails locally gets 3 refunded retries, then
+		// the 2 retries of the budget.
+		var calls int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			calls++
+			return errLocal
+		}, retry.WithClock(newFakeClock()))
+		if !retry.IsLocalFailure(err) {
+			t.Errorf("expected %v to be a local failure", err)
+		}
+		if got, want := calls, 1+3+2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithLocalRefunds(1, 5*time.Millisecond, retry.WithMaxRetries(0, retry.NewConstant(1*time.Second))).(retry.ErrorBackoff)
+		if _, stop := b.NextError(errors.New("oops")); !stop {
+			t.Fatal("should stop")
+		}
+
+		// Reset clears the stop and the refunds.
+		b.(interface{ Reset() }).Reset()
+		if val, stop := b.NextError(errLocal); stop || val != 5*time.Millisecond {
+			t.Errorf("expected 5ms without stopping, got %v, %t", val, stop)
+		}
+	})
+
+	t.Run("zero_refunds", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithLocalRefunds(0, 0, retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))).(retry.ErrorBackoff)
+		if val, stop := b.NextError(errLocal); stop || val != 1*time.Second {
+			t.Errorf("expected 1s without stopping, got %v, %t", val, stop)
+		}
+		if _, stop := b.NextError(errLocal); !stop {
+			t.Errorf("should stop")
+		}
+	})
+
+	t.Run("error_budgets", func(t *testing.T) {
+		t.Parallel()
+
+		// Errors other than refunded local failures reach the inner error-aware
+		// backoff.
+		budgets := retry.WithErrorBudgets(map[string]uint64{"": 1}, func(error) string {
+			return ""
+		}, retry.NewConstant(1*time.Second))
+		b := retry.WithLocalRefunds(1, 0, budgets).(retry.ErrorBackoff)
+
+		steps := []struct {
+			err  error
+			stop bool
+		}{
+			{errLocal, false},
+			{errRemote, false},
+			{errLocal, true},
+		}
+		for i, s := range steps {
+			if _, stop := b.NextError(s.err); stop != s.stop {
+				t.Errorf("%d: expected %t to be %t", i, stop, s.stop)
+			}
+		}
+	})
+}
+
+func ExampleLocalFailure() {
+	ctx := context.Background()
+
+	b := retry.NewExponential(1 * time.Second)
+	b = retry.WithMaxRetries(3, b)
+	b = retry.WithLocalRefunds(5, 10*time.Millisecond, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		body, err := json.Marshal(map[string]string{"hello": "world"})
+		if err != nil {
+			// The request was never sent, so do not spend a retry on it.
+			return retry.LocalFailure(err)
+		}
+		_ = body // TODO: send the request
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
@@ -0,0 +1,156 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RepeatFunc is a function passed to [RepeatWhile].
+type RepeatFunc func(ctx context.Context) error
+
+// repeatContinue is returned to the retry loop by iterations of a repeat that
+// succeeded. It is allocated once, since it is returned on every iteration,
+// and is reported to observers as a nil error.
+var repeatContinue error = &retryableError{err: errors.New("retry: repeat")}
+
+// RepeatWhile calls f repeatedly, waiting between calls according to b, such as
+// to poll a resource. Unlike [Do], a nil error continues the loop. Any other
+// error is passed to while: if it returns true, the error is swallowed and the
+// loop continues; otherwise RepeatWhile returns the error as is, even if it was
+// marked with [RetryableError]. For example, a poller can keep going on
+// transient errors but stop once the resource is gone.
+//
+// RepeatWhile returns nil when b stops, and the context's error when ctx is
+// done. Swallowed errors are reported to observers registered with
+// [WithOutcomeObserver], wrapped with [RetryableError], and successful calls
+// are reported with a nil error. All other options behave as they do for Do.
+func RepeatWhile(ctx context.Context, b Backoff, f RepeatFunc, while func(err error) bool, opts ...DoOption) error {
+	_, err := repeatValue(ctx, b, func(ctx context.Context) (*struct{}, error) {
+		return nil, f(ctx)
+	}, while, opts)
+	return err
+}
+
+// RepeatWithErrors is like [RepeatWhile], with onError as the predicate: each
+// error is passed to onError, which may log it, and the loop continues if it
+// returns true and returns the error if it returns false. This suits periodic
+// health checks that log transient errors but abort on fatal ones.
+func RepeatWithErrors(ctx context.Context, b Backoff, f RepeatFunc, onError func(err error) bool, opts ...DoOption) error {
+	return RepeatWhile(ctx, b, f, onError, opts...)
+}
+
+// RepeatWithRecovery is like [RepeatWhile], with tolerate as the predicate, but
+// waits according to recovery instead of normal after each call that returned
+// a tolerated error. For example, a poller that runs every 30 seconds can
+// re-check after 2 seconds following a transient error, then return to its
+// usual cadence once a call succeeds.
+//
+// Consecutive tolerated errors continue the recovery backoff, which is reset
+// with its Reset method, if any, each time it is entered after a success. If
+// recovery stops, normal is used until the next success. The normal backoff is
+// not advanced while recovering, and the loop ends when it stops.
+func RepeatWithRecovery(ctx context.Context, normal, recovery Backoff, f RepeatFunc, tolerate func(err error) bool, opts ...DoOption) error {
+	b := &recoveryBackoff{normal: normal, recovery: recovery}
+	return RepeatWhile(ctx, b, func(ctx context.Context) error {
+		b.tolerated = false
+		return f(ctx)
+	}, func(err error) bool {
+		b.tolerated = tolerate(err)
+		return b.tolerated
+	}, opts...)
+}
+
+// recoveryBackoff is the backoff of RepeatWithRecovery. It is used by a single
+// loop, so it is not safe for concurrent use.
+type recoveryBackoff struct {
+	normal   Backoff
+	recovery Backoff
+
+	// tolerated reports whether the most recent call returned a tolerated
+	// error, and recovering whether the recovery backoff is in use.
+	tolerated  bool
+	recovering bool
+}
+
+// Next implements Backoff.
+func (b *recoveryBackoff) Next() (time.Duration, bool) {
+	if !b.tolerated {
+		b.recovering = false
+		return b.normal.Next()
+	}
+
+	if !b.recovering {
+		b.recovering = true
+		if r, ok := b.recovery.(interface{ Reset() }); ok {
+			r.Reset()
+		}
+	}
+	if next, stop := b.recovery.Next(); !stop {
+		return next, false
+	}
+	return b.normal.Next()
+}
+
+// RepeatValue calls f repeatedly, waiting between calls according to b, until f
+// returns an error or b stops, and returns the value from the most recent call
+// that succeeded. Like [RepeatWhile], it returns nil when b stops, and the
+// context's error when ctx is done, which is checked before every call and
+// before every wait. Any error returned by f ends the loop and is returned as
+// is, alongside the most recent value.
+func RepeatValue[T any](ctx context.Context, b Backoff, f RetryFuncValue[T], opts ...DoOption) (T, error) {
+	return repeatValue(ctx, b, f, nil, opts)
+}
+
+// repeatValue is the loop shared by RepeatWhile and RepeatValue. A nil while
+// ends the loop on every error.
+func repeatValue[T any](ctx context.Context, b Backoff, f RetryFuncValue[T], while func(err error) bool, opts []DoOption) (T, error) {
+	var last T
+	var stopped bool
+	opts = appendOptions(opts, WithStopHook(func(reason StopReason, _ error) {
+		stopped = reason != ReasonGateClosed
+	}))
+
+	err := Do(ctx, b, func(ctx context.Context) error {
+		v, err := f(ctx)
+		if err == nil {
+			last = v
+			return repeatContinue
+		}
+		if while != nil && while(err) {
+			return RetryableError(err)
+		}
+		if rerr, ok := err.(*retryableError); ok {
+			err = rerr.err
+		}
+		// End the loop even if f marked the error as retryable deeper in its
+		// chain.
+		return &repeatEndError{err: err}
+	}, opts...)
+	if stopped && ctx.Err() == nil {
+		return last, nil
+	}
+	if eerr, ok := err.(*repeatEndError); ok {
+		err = eerr.err
+	}
+	return last, err
+}
+
+// repeatEndError is returned to the retry loop by iterations of a repeat that
+// end it. It is a retry marker, so Do treats the error as permanent without
+// looking for a *retryableError further down the chain.
+type repeatEndError struct {
+	err error
+}
+
+func (*repeatEndError) retryMarker() {}
+
+// Unwrap implements error wrapping.
+func (e *repeatEndError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the error string.
+func (e *repeatEndError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,431 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestRepeatWhile(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	errGone := errors.New("404 gone")
+	while := func(err error) bool {
+		return !errors.Is(err, errGone)
+	}
+
+	t.Run("swallows_transient_errors", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		var observed []error
+		b := retry.WithMaxRetries(4, retry.NewConstant(time.Nanosecond))
+		err := retry.RepeatWhile(context.Background(), b, func(_ context.Context) error {
+			calls++
+			if calls%2 == 0 {
+				return errTransient
+			}
+			return nil
+		}, while, retry.WithOutcomeObserver(func(o retry.Outcome) {
+			observed = append(observed, o.Err)
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got, want := calls, 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := len(observed), 5; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i, err := range observed {
+			if got, want := errors.Is(err, errTransient), i%2 == 1; got != want {
+				t.Errorf("attempt %d: expected %v to be %v (%v)", i+1, got, want, err)
+			}
+			if i%2 == 0 && err != nil {
+				t.Errorf("attempt %d: expected nil, got %v", i+1, err)
+			}
+		}
+	})
+
+	t.Run("stops_on_error", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		b := retry.NewConstant(time.Nanosecond)
+		err := retry.RepeatWhile(context.Background(), b, func(_ context.Context) error {
+			calls++
+			if calls == 3 {
+				return retry.RetryableError(errGone)
+			}
+			return errTransient
+		}, while)
+		if got, want := err, errGone; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops_on_wrapped_retryable_error", func(t *testing.T) {
+		t.Parallel()
+
+		errWrapped := fmt.Errorf("poll: %w", retry.RetryableError(errGone))
+
+		var calls int
+		b := retry.WithMaxRetries(5, retry.NewConstant(time.Nanosecond))
+		err := retry.RepeatWhile(context.Background(), b, func(_ context.Context) error {
+			calls++
+			return errWrapped
+		}, while)
+		if got, want := err, errWrapped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("backoff_stop", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		b := retry.WithMaxRetries(2, retry.NewConstant(time.Nanosecond))
+		err := retry.RepeatWhile(context.Background(), b, func(_ context.Context) error {
+			calls++
+			return errTransient
+		}, while)
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls int
+		b := retry.NewConstant(time.Nanosecond)
+		err := retry.RepeatWhile(ctx, b, func(_ context.Context) error {
+			calls++
+			if calls == 2 {
+				cancel()
+			}
+			return nil
+		}, while)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("shared_options", func(t *testing.T) {
+		t.Parallel()
+
+		// The options have spare capacity, so appending to them in place would
+		// let concurrent calls overwrite each other's options.
+		opts := make([]retry.DoOption, 1, 8)
+		opts[0] = retry.WithOutcomeObserver(func(retry.Outcome) {})
+
+		var wg sync.WaitGroup
+		errs := make([]error, 8)
+		for i := range errs {
+			i := i
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				b := retry.WithMaxRetries(3, retry.NewConstant(time.Nanosecond))
+				errs[i] = retry.RepeatWhile(context.Background(), b, func(_ context.Context) error {
+					return nil
+				}, while, opts...)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				t.Errorf("expected %v to be nil", err)
+			}
+		}
+	})
+}
+
+func TestRepeatWithErrors(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	var calls int
+	var logged []error
+	b := retry.NewConstant(time.Nanosecond)
+	err := retry.RepeatWithErrors(context.Background(), b, func(_ context.Context) error {
+		calls++
+		switch calls {
+		case 2, 3:
+			return errTransient
+		case 5:
+			return errFatal
+		}
+		return nil
+	}, func(err error) bool {
+		logged = append(logged, err)
+		return err != errFatal
+	})
+	if got, want := err, errFatal; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := calls, 5; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := len(logged), 3; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestRepeatWithRecovery(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	run := func(t *testing.T, recovery retry.Backoff, script []error) ([]time.Duration, error) {
+		t.Helper()
+
+		clock := &sleepRecorder{fakeClock: newFakeClock()}
+		var calls int
+		err := retry.RepeatWithRecovery(context.Background(), retry.NewConstant(30*time.Second), recovery, func(_ context.Context) error {
+			calls++
+			return script[calls-1]
+		}, func(err error) bool {
+			return err == errTransient
+		}, retry.WithClock(clock))
+		if got, want := calls, len(script); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		return clock.slept, err
+	}
+
+	t.Run("interleaved", func(t *testing.T) {
+		t.Parallel()
+
+		slept, err := run(t, retry.NewConstant(2*time.Second), []error{
+			nil, errTransient, nil, nil, errTransient, errTransient, nil, errFatal,
+		})
+		if got, want := err, errFatal; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(slept), "[30s 2s 30s 30s 2s 2s 30s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset_on_entry", func(t *testing.T) {
+		t.Parallel()
+
+		slept, err := run(t, retry.NewScheduleRepeatLast(2*time.Second, 4*time.Second), []error{
+			errTransient, errTransient, errTransient, nil, errTransient, errFatal,
+		})
+		if got, want := err, errFatal; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(slept), "[2s 4s 4s 30s 2s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("recovery_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		slept, err := run(t, retry.NewSchedule(2*time.Second), []error{
+			errTransient, errTransient, errTransient, nil, errTransient, errFatal,
+		})
+		if got, want := err, errFatal; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(slept), "[2s 30s 30s 30s 2s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("normal_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &sleepRecorder{fakeClock: newFakeClock()}
+		var calls int
+		err := retry.RepeatWithRecovery(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(30*time.Second)), retry.NewConstant(2*time.Second), func(_ context.Context) error {
+			calls++
+			if calls%2 == 0 {
+				return errTransient
+			}
+			return nil
+		}, func(err error) bool {
+			return err == errTransient
+		}, retry.WithClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := fmt.Sprint(clock.slept), "[30s 2s 30s 2s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestRepeatValue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("backoff_stop", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		b := retry.WithMaxRetries(3, retry.NewConstant(time.Nanosecond))
+		v, err := retry.RepeatValue(context.Background(), b, func(_ context.Context) (int, error) {
+			calls++
+			return calls, nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := v, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops_on_error", func(t *testing.T) {
+		t.Parallel()
+
+		errOops := errors.New("oops")
+
+		var calls int
+		b := retry.NewConstant(time.Nanosecond)
+		v, err := retry.RepeatValue(context.Background(), b, func(_ context.Context) (string, error) {
+			calls++
+			if calls == 3 {
+				// Retryable errors also end the loop.
+				return "failed", retry.RetryableError(errOops)
+			}
+			return "ready", nil
+		})
+		if got, want := err, errOops; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := v, "ready"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops_on_wrapped_retryable_error", func(t *testing.T) {
+		t.Parallel()
+
+		errWrapped := fmt.Errorf("poll: %w", retry.RetryableError(errors.New("oops")))
+
+		var calls int
+		b := retry.WithMaxRetries(5, retry.NewConstant(time.Nanosecond))
+		_, err := retry.RepeatValue(context.Background(), b, func(_ context.Context) (int, error) {
+			calls++
+			return 0, errWrapped
+		})
+		if got, want := err, errWrapped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled_before_call", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var calls int
+		_, err := retry.RepeatValue(ctx, retry.NewConstant(time.Nanosecond), func(_ context.Context) (int, error) {
+			calls++
+			return 1, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("context_canceled_before_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls int
+		v, err := retry.RepeatValue(ctx, retry.NewConstant(time.Hour), func(_ context.Context) (int, error) {
+			calls++
+			cancel()
+			return 7, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if got, want := v, 7; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleRepeatWhile() {
+	ctx := context.Background()
+
+	errTransient := errors.New("transient")
+	errGone := errors.New("gone")
+
+	// Poll a resource, tolerating transient errors, until it is gone.
+	var polls int
+	b := retry.NewConstant(1 * time.Nanosecond)
+	err := retry.RepeatWhile(ctx, b, func(_ context.Context) error {
+		polls++
+		fmt.Printf("poll %d\n", polls)
+		switch polls {
+		case 2:
+			return errTransient
+		case 4:
+			return errGone
+		}
+		return nil
+	}, func(err error) bool {
+		return err == errTransient
+	})
+	fmt.Println(err)
+
+	// Output:
+	// poll 1
+	// poll 2
+	// poll 3
+	// poll 4
+	// gone
+}
@@ -0,0 +1,34 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Report summarizes the attempts made by [DoWithReport].
+type Report struct {
+	// Attempts is the number of attempts made, including the first.
+	Attempts uint64
+
+	// TotalSleep is the total delay slept before attempts, including the delay
+	// set with [WithInitialDelay]. A delay cut short because retrying stopped is
+	// not included.
+	TotalSleep time.Duration
+}
+
+// DoWithReport wraps a function with a backoff to retry, like [Do], and also
+// returns a report of the attempts made, whether the call succeeded or not.
+// This allows callers to log how many attempts an operation took.
+func DoWithReport(ctx context.Context, b Backoff, f RetryFunc, opts ...DoOption) (Report, error) {
+	var r Report
+	var pending time.Duration
+	err := Do(ctx, b, f, appendOptions(opts, func(c *doConfig) {
+		pending = overrideDelay(max(c.initialDelay, 0))
+		c.onOutcome = append(c.onOutcome, func(o Outcome) {
+			r.Attempts = o.Attempt
+			r.TotalSleep += pending
+			pending = o.Delay
+		})
+	})...)
+	return r, err
+}
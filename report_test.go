@@ -0,0 +1,98 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoWithReport(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	cases := []struct {
+		name     string
+		fails    int
+		opts     []retry.DoOption
+		err      error
+		attempts uint64
+		sleep    time.Duration
+	}{
+		{
+			name:     "first_try",
+			attempts: 1,
+		},
+		{
+			name:     "after_retries",
+			fails:    2,
+			attempts: 3,
+			sleep:    3 * time.Second,
+		},
+		{
+			name:     "exhausted",
+			fails:    10,
+			err:      errFail,
+			attempts: 4,
+			sleep:    7 * time.Second,
+		},
+		{
+			name:     "initial_delay",
+			fails:    1,
+			opts:     []retry.DoOption{retry.WithInitialDelay(5 * time.Second)},
+			attempts: 2,
+			sleep:    6 * time.Second,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := newFakeClock()
+			b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+
+			var calls int
+			report, err := retry.DoWithReport(context.Background(), b, func(_ context.Context) error {
+				calls++
+				if calls <= tc.fails {
+					return retry.RetryableError(errFail)
+				}
+				return nil
+			}, append(tc.opts, retry.WithClock(clock))...)
+			if got, want := err, tc.err; !errors.Is(got, want) || (want == nil && got != nil) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := report.Attempts, tc.attempts; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := report.TotalSleep, tc.sleep; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("canceled_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		report, err := retry.DoWithReport(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			cancel()
+			return retry.RetryableError(errFail)
+		})
+		if got, want := err, context.Canceled; !errors.Is(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := report.Attempts, uint64(1); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := report.TotalSleep, time.Duration(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// HostResolver resolves a host name to a list of addresses. [net.Resolver]
+// implements HostResolver.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) (addrs []string, err error)
+}
+
+var _ HostResolver = (*net.Resolver)(nil)
+
+type resolvedAddrKey struct{}
+
+// WithFreshResolution resolves host at the start of every attempt and picks one
+// of the returned addresses, preferring a different address than the previous
+// attempt. The picked address is available to the retry function via
+// [GetResolvedAddr].
+//
+// This is useful when retrying against a host name that resolves to many
+// addresses, since a failing backend is less likely to be picked twice in a
+// row. If resolution fails, the attempt fails with a retryable error and the
+// retry function is not called.
+//
+// If resolver is nil, [net.DefaultResolver] is used.
+func WithFreshResolution(resolver HostResolver, host string) DoOption {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	r := newLockedRandom(time.Now().UnixNano())
+
+	var lock sync.Mutex
+	var prev string
+
+	return func(c *doConfig) {
+		c.beforeAttempt = append(c.beforeAttempt, func(ctx context.Context) (context.Context, error) {
+			addrs, err := resolver.LookupHost(ctx, host)
+			if err != nil {
+				return nil, RetryableError(fmt.Errorf("failed to resolve %q: %w", host, err))
+			}
+			if len(addrs) == 0 {
+				return nil, RetryableError(fmt.Errorf("failed to resolve %q: no addresses", host))
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			addr := addrs[r.Int63n(int64(len(addrs)))]
+			if addr == prev && len(addrs) > 1 {
+				// Pick from the remaining addresses instead.
+				i := r.Int63n(int64(len(addrs)) - 1)
+				for _, a := range addrs {
+					if a == prev {
+						continue
+					}
+					if i == 0 {
+						addr = a
+						break
+					}
+					i--
+				}
+			}
+			prev = addr
+
+			return context.WithValue(ctx, resolvedAddrKey{}, addr), nil
+		})
+	}
+}
+
+// GetResolvedAddr returns the address picked for the current attempt by
+// [WithFreshResolution]. It returns false if no address was picked.
+func GetResolvedAddr(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(resolvedAddrKey{}).(string)
+	return addr, ok
+}
@@ -0,0 +1,182 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+type fakeResolver struct {
+	lock  sync.Mutex
+	addrs []string
+	errs  []error
+	calls int
+}
+
+func (r *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	i := r.calls
+	r.calls++
+	if i < len(r.errs) && r.errs[i] != nil {
+		return nil, r.errs[i]
+	}
+
+	// Rotate the addresses on each lookup, like round-robin DNS.
+	out := make([]string, 0, len(r.addrs))
+	for j := range r.addrs {
+		out = append(out, r.addrs[(i+j)%len(r.addrs)])
+	}
+	return out, nil
+}
+
+func TestWithFreshResolution(t *testing.T) {
+	t.Parallel()
+
+	t.Run("different_addrs", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		resolver := &fakeResolver{addrs: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}}
+		b := retry.WithMaxRetries(20, retry.NewConstant(1*time.Nanosecond))
+
+		var seen []string
+		if err := retry.Do(ctx, b, func(ctx context.Context) error {
+			addr, ok := retry.GetResolvedAddr(ctx)
+			if !ok {
+				t.Fatal("expected resolved addr")
+			}
+			seen = append(seen, addr)
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithFreshResolution(resolver, "example.com")); err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := len(seen), 21; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		for i := 1; i < len(seen); i++ {
+			if seen[i] == seen[i-1] {
+				t.Errorf("attempt %d: expected %v to differ from previous attempt", i, seen[i])
+			}
+		}
+	})
+
+	t.Run("single_addr", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		resolver := &fakeResolver{addrs: []string{"10.0.0.1"}}
+		b := retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond))
+
+		if err := retry.Do(ctx, b, func(ctx context.Context) error {
+			if addr, _ := retry.GetResolvedAddr(ctx); addr != "10.0.0.1" {
+				t.Errorf("expected %v to be %v", addr, "10.0.0.1")
+			}
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithFreshResolution(resolver, "example.com")); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("resolution_failure_retries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		errDNS := errors.New("dns failure")
+		resolver := &fakeResolver{
+			addrs: []string{"10.0.0.1"},
+			errs:  []error{errDNS, errDNS},
+		}
+		b := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		if err := retry.Do(ctx, b, func(ctx context.Context) error {
+			i++
+			return nil
+		}, retry.WithFreshResolution(resolver, "example.com")); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := resolver.calls, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("resolution_failure_exhausted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		errDNS := errors.New("dns failure")
+		resolver := &fakeResolver{
+			errs: []error{errDNS, errDNS},
+		}
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		err := retry.Do(ctx, b, func(ctx context.Context) error {
+			t.Error("should not be called")
+			return nil
+		}, retry.WithFreshResolution(resolver, "example.com"))
+		if !errors.Is(err, errDNS) {
+			t.Errorf("expected %v to be %v", err, errDNS)
+		}
+	})
+
+	t.Run("no_option", func(t *testing.T) {
+		t.Parallel()
+
+		if _, ok := retry.GetResolvedAddr(context.Background()); ok {
+			t.Error("expected no resolved addr")
+		}
+	})
+}
+
+func ExampleWithFreshResolution() {
+	ctx := context.Background()
+
+	// Dial the per-attempt address instead of letting the transport resolve the
+	// host name again.
+	dialer := &net.Dialer{}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if resolved, ok := retry.GetResolvedAddr(ctx); ok {
+					_, port, err := net.SplitHostPort(addr)
+					if err != nil {
+						return nil, err
+					}
+					addr = net.JoinHostPort(resolved, port)
+				}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	b := retry.WithMaxRetries(3, retry.NewFibonacci(1*time.Second))
+
+	if err := retry.Do(ctx, b, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com/", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return retry.RetryableError(err)
+		}
+		defer resp.Body.Close()
+		return nil
+	}, retry.WithFreshResolution(net.DefaultResolver, "example.com")); err != nil {
+		// handle error
+	}
+}
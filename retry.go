@@ -15,6 +15,7 @@ package retry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -24,18 +25,89 @@ type RetryFunc func(ctx context.Context) error
 // RetryFuncValue is a function passed to [Do] which returns a value.
 type RetryFuncValue[T any] func(ctx context.Context) (T, error)
 
+// ErrAttemptSuperseded is the cause used to cancel the context of an attempt
+// whose result is no longer needed, such as a sibling that lost a race in
+// [FirstSuccess].
+var ErrAttemptSuperseded = errors.New("retry: attempt superseded")
+
+// ErrExhausted matches, with [errors.Is], the error returned by [Do] and
+// [DoValue] when they stop retrying a retryable error, such as when the backoff
+// stops or the attempt limit is reached. The error keeps the message of the
+// final error, which [errors.Unwrap] returns. Permanent errors and context
+// errors are returned as is, so they never match.
+//
+// Before ErrExhausted was added, the final error was returned as is. Callers
+// that compare it with == must use [errors.Is] instead.
+var ErrExhausted = errors.New("retry: exhausted")
+
+// exhaustedError wraps the final error when retrying stops, to match
+// ErrExhausted.
+type exhaustedError struct {
+	err error
+}
+
+// Error returns the message of the final error.
+func (e *exhaustedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the final error.
+func (e *exhaustedError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether target is ErrExhausted.
+func (e *exhaustedError) Is(target error) bool {
+	return target == ErrExhausted
+}
+
 type retryableError struct {
 	err error
+
+	// delay is the delay requested with RetryableErrorAfter, if hasDelay.
+	delay    time.Duration
+	hasDelay bool
 }
 
-// RetryableError marks an error as retryable.
+// RetryableError marks an error as retryable. If err was itself returned by
+// RetryableError, it is returned unchanged.
 func RetryableError(err error) error {
 	if err == nil {
 		return nil
 	}
-	return &retryableError{err}
+	if rerr, ok := err.(*retryableError); ok {
+		return rerr
+	}
+	return &retryableError{err: err}
 }
 
+// RetryableErrorAfter marks an error as retryable, like [RetryableError], and
+// requests that the next attempt be made after delay, such as the delay from a
+// server's Retry-After header; see [RetryAfter]. The delay replaces the delay
+// from the backoff, but is still limited by [WithCappedDuration],
+// [WithMaxDuration], and [WithLease] in the backoff's chain, and the attempt
+// still counts toward limits such as [WithMaxRetries]. A negative delay is
+// treated as 0.
+func RetryableErrorAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	if rerr, ok := err.(*retryableError); ok {
+		err = rerr.err
+	}
+	return &retryableError{err: err, delay: max(delay, 0), hasDelay: true}
+}
+
+// retryMarker is implemented by errors returned from [RetryableError] and
+// [RetryableErrorAfter], and by the permanent errors that end a repeat loop. It
+// is checked with a cheap type assertion while walking an error chain, and the
+// first marker in the chain decides whether the error is retryable.
+type retryMarker interface {
+	retryMarker()
+}
+
+func (*retryableError) retryMarker() {}
+
 // Unwrap implements error wrapping.
 func (e *retryableError) Unwrap() error {
 	return e.err
@@ -49,56 +121,431 @@ func (e *retryableError) Error() string {
 	return "retryable: " + e.err.Error()
 }
 
-func DoValue[T any](ctx context.Context, b Backoff, f RetryFuncValue[T]) (T, error) {
-	var nilT T
+type retryCountKey struct{}
+
+// GetRetryCount returns the number of retries that preceded the current attempt
+// from the context passed to a [RetryFunc] or [RetryFuncValue]. It is 0 on the
+// first attempt. It returns false if ctx is not the context of an attempt.
+func GetRetryCount(ctx context.Context) (uint64, bool) {
+	n, ok := ctx.Value(retryCountKey{}).(uint64)
+	return n, ok
+}
+
+// AttemptFromContext returns the number of the current attempt, starting at 1,
+// from the context passed to a [RetryFunc] or [RetryFuncValue]. It returns 0 if
+// ctx is not the context of an attempt.
+func AttemptFromContext(ctx context.Context) uint64 {
+	n, ok := GetRetryCount(ctx)
+	if !ok {
+		return 0
+	}
+	return n + 1
+}
+
+// DoValue wraps a function with a backoff to retry, returning the value from
+// the first successful attempt. The provided context is the same context passed
+// to the [RetryFuncValue].
+//
+// By default, the zero value of T is returned alongside any error, including
+// when the context is canceled after an attempt returned a value. Use
+// [KeepLastOnError] to instead return the value from the most recent attempt.
+//
+// If ctx is done after an attempt failed with a retryable error, the returned
+// error matches both the context's error and the attempt's error with
+// [errors.Is]. If ctx is done before any attempt failed, the context's error is
+// returned.
+func DoValue[T any](ctx context.Context, b Backoff, f RetryFuncValue[T], opts ...DoOption) (T, error) {
+	cfg := newDoConfig(opts)
+	if cfg.attribute {
+		// Skip lookupCallSite and DoValue.
+		cfg.site = lookupCallSite(2)
+	}
+	if cfg.historyMax <= 0 && !cfg.exhaustionDetail {
+		return doValue(ctx, b, f, cfg)
+	}
+
+	// The options may be shared between calls, so the history and the
+	// exhaustion are not.
+	var h *errorHistory
+	if cfg.historyMax > 0 {
+		h = &errorHistory{max: cfg.historyMax}
+		cfg.history = h
+	}
+	var x *exhaustion
+	if cfg.exhaustionDetail {
+		x = &exhaustion{start: cfg.clock.Now()}
+		cfg.exhaustion = x
+	}
+
+	v, err := doValue(ctx, b, f, cfg)
+	if x != nil {
+		err = x.wrap(err, b)
+	}
+	if h != nil {
+		err = h.wrap(err)
+	}
+	return v, err
+}
+
+func doValue[T any](ctx context.Context, b Backoff, f RetryFuncValue[T], cfg *doConfig) (T, error) {
+	// last is the value returned alongside an error. It remains the zero value
+	// unless KeepLastOnError is set.
+	var last T
+
+	a := newAttempter(b, cfg, cfg.maxAttempts(ctx))
+	d := newDelivery(cfg, a)
+	observers := backoffObservers(b)
+	var budgets []budgetChecker
+	if !cfg.skipBudgetRecheck {
+		budgets = backoffBudgets(b)
+	}
+
+	// End the call for the backoffs when returning without finishing, such as
+	// on success or cancellation.
+	defer endCalls(b)
+
+	// sleepCtx is used for sleeping between attempts. It is also canceled on
+	// shutdown, which skips the sleep without canceling in-flight attempts.
+	sleepCtx := ctx
+	var final bool
+	if c := cfg.shutdown; c != nil {
+		defer c.register()()
+
+		var cancel context.CancelFunc
+		sleepCtx, cancel = c.sleepContext(ctx)
+		defer cancel()
 
+		// Loops started after shutdown make a single attempt.
+		final = c.isShutdown()
+	}
+
+	if cfg.initialDelay > 0 {
+		if err := cfg.clock.Sleep(sleepCtx, overrideDelay(cfg.initialDelay)); err != nil {
+			if ctx.Err() != nil {
+				return last, d.cancel(contextError(ctx))
+			}
+		}
+	}
+
+	// start is when the first attempt started, which is only needed to report
+	// a degraded success.
+	var start time.Time
+	if len(cfg.onDegraded) > 0 {
+		start = cfg.clock.Now()
+	}
+
+	// This loop drives every entry point in the package, which are checked
+	// against each other by the conformance tests.
 	for {
 		// Return immediately if ctx is canceled
 		select {
 		case <-ctx.Done():
-			return nilT, ctx.Err()
+			return last, d.cancel(canceledError(ctx, a.lastErr))
 		default:
 		}
 
-		v, err := f(ctx)
+		if !gateOpen(a.Attempts() == 0) {
+			a.closeGate()
+			return last, a.Err()
+		}
+		d.start()
+
+		var v T
+		o := Outcome{Attempt: a.Attempts() + 1}
+		attemptCtx, release, err := cfg.attemptContext(ctx, o.Attempt)
 		if err == nil {
+			// Progress is closed with the attempt's resources, even if f panics.
+			var dropped uint64
+			if cfg.progress != nil {
+				var closeProgress func() uint64
+				attemptCtx, closeProgress = cfg.progress(attemptCtx)
+				releaseAttempt := release
+				release = func() {
+					dropped = closeProgress()
+					releaseAttempt()
+				}
+			}
+			o = cfg.runAttempt(attemptCtx, o.Attempt, release, func(ctx context.Context) error {
+				var err error
+				v, err = f(ctx)
+				return err
+			})
+			o.ProgressDropped = dropped
+			err = o.Err
+		} else {
+			o.Err = err
+		}
+		if err != nil && cfg.keepLast {
+			last = v
+		}
+		if err != nil && cfg.reclassify != nil {
+			if sleepErr := cfg.clock.Sleep(ctx, overrideDelay(cfg.reclassifyDelay)); sleepErr != nil {
+				if ctx.Err() != nil {
+					// The attempt is still reported to the observers.
+					cfg.observe(o)
+					observeBackoffs(observers, o)
+					return last, d.cancel(canceledError(ctx, a.lastErr))
+				}
+			}
+			err = cfg.reclassify(ctx, err)
+			o.Err = err
+		}
+		if err == nil {
+			cfg.observe(o)
+			observeBackoffs(observers, o)
+			if o.Attempt > 1 {
+				for _, fn := range cfg.onDegraded {
+					fn(ctx, o.Attempt, cfg.clock.Now().Sub(start))
+				}
+			}
 			return v, nil
 		}
 
-		// Not retryable
-		var rerr *retryableError
-		if !errors.As(err, &rerr) {
-			return nilT, err
+		next, done := a.Next(err)
+		if !done {
+			next = overrideDelay(next)
+			if cfg.deadlineAware && exceedsDeadline(ctx, cfg.clock, next) {
+				a.abort(ReasonDeadline)
+				next, done = 0, true
+			}
 		}
 
-		next, stop := b.Next()
-		if stop {
-			return nilT, rerr.Unwrap()
+		// ctx.Done() has priority over scheduling a retry, even if it was
+		// canceled after the attempt returned.
+		canceled := !done && ctx.Err() != nil
+		if !done && !canceled {
+			o.Delay = next
+		}
+		retryErr := a.lastErr
+		if o.Err == repeatContinue {
+			// A successful iteration of RepeatWhile, which is not a retry.
+			o.Err = nil
+			retryErr = nil
+		}
+		cfg.observe(o)
+		observeBackoffs(observers, o)
+		if done {
+			return last, a.Err()
+		}
+		if canceled {
+			return last, d.cancel(canceledError(ctx, a.lastErr))
 		}
 
-		// ctx.Done() has priority, so we test it alone first
-		select {
-		case <-ctx.Done():
-			return nilT, ctx.Err()
-		default:
+		if final {
+			// The final attempt after shutdown has been made.
+			a.abort(ReasonShutdown)
+			return last, a.Err()
 		}
 
-		t := time.NewTimer(next)
-		select {
-		case <-ctx.Done():
-			t.Stop()
-			return nilT, ctx.Err()
-		case <-t.C:
-			continue
+		if !gateOpen(false) {
+			a.closeGate()
+			return last, a.Err()
+		}
+
+		// From here on, the call ending before the next attempt is reported for
+		// that attempt.
+		d.retry(next, retryErr)
+
+		var sleepStart time.Time
+		if len(budgets) > 0 {
+			sleepStart = cfg.clock.Now()
+		}
+
+		if l := cfg.limiter; l != nil {
+			if err := l.Wait(sleepCtx); err != nil {
+				if ctx.Err() != nil {
+					return last, d.cancel(canceledError(ctx, a.lastErr))
+				}
+				// Errors other than a shutdown, such as from a limiter whose wait
+				// would exceed the context's deadline, mean no retry is possible.
+				if errors.Is(err, ErrRateLimited) || sleepCtx.Err() == nil {
+					a.abort(ReasonRateLimited)
+					return last, a.Err()
+				}
+			}
+		}
+
+		if err := cfg.clock.Sleep(sleepCtx, next); err != nil {
+			if ctx.Err() != nil {
+				return last, d.cancel(canceledError(ctx, a.lastErr))
+			}
+		}
+
+		if len(budgets) > 0 {
+			if now := cfg.clock.Now(); now.Sub(sleepStart)-next > oversleepTolerance && budgetExceeded(budgets, now) {
+				a.abort(ReasonMaxDuration)
+				return last, a.Err()
+			}
+		}
+
+		// If shutdown was requested during the attempt or the sleep, make one
+		// final immediate attempt if configured.
+		if c := cfg.shutdown; c != nil && c.isShutdown() {
+			if !c.finalAttempt() {
+				a.abort(ReasonShutdown)
+				return last, a.Err()
+			}
+			final = true
 		}
 	}
 }
 
 // Do wraps a function with a backoff to retry. The provided context is the same
 // context passed to the [RetryFunc].
-func Do(ctx context.Context, b Backoff, f RetryFunc) error {
+//
+// Most backoffs carry state, such as the current delay of [NewExponential] or
+// the retries counted by [WithMaxRetries]. Passing the same backoff to another
+// call continues from where the previous call left off; use [DoWithFactory] to
+// start every call from the base delay.
+//
+// If ctx is done after an attempt failed with a retryable error, the returned
+// error also matches that attempt's error with [errors.Is].
+func Do(ctx context.Context, b Backoff, f RetryFunc, opts ...DoOption) error {
 	_, err := DoValue(ctx, b, func(ctx context.Context) (*struct{}, error) {
 		return nil, f(ctx)
+	}, opts...)
+	return err
+}
+
+// DoWithFactory wraps a function with a backoff to retry, like [Do], but calls
+// newBackoff to build a fresh backoff for the call. This allows a policy to be
+// defined once and shared, for example by a client's methods, while every call
+// starts from the base delay with its full budget. newBackoff must return a
+// new backoff on every call.
+func DoWithFactory(ctx context.Context, newBackoff func() Backoff, f RetryFunc, opts ...DoOption) error {
+	return Do(ctx, newBackoff(), f, opts...)
+}
+
+// DoWithPredicate wraps a function with a backoff to retry, like [Do], but
+// errors for which pred returns true are retried without needing to be wrapped
+// with [RetryableError]. This is useful for errors from clients that cannot be
+// changed. Errors wrapped with RetryableError are retried regardless of pred.
+// When retrying stops, the error from the last attempt is returned wrapped to
+// match [ErrExhausted], like that of any retryable error, while an error for
+// which pred returns false is returned as is. A panic in pred propagates to the
+// caller. [RetryOn] and [RetryOnTypes] build predicates that match errors
+// anywhere in their chain.
+func DoWithPredicate(ctx context.Context, b Backoff, pred func(err error) bool, f RetryFunc, opts ...DoOption) error {
+	return Do(ctx, b, f, appendOptions(opts, withRetryPredicate(pred))...)
+}
+
+// DoWithInitialDelay wraps a function with a backoff to retry, like [Do], but
+// sleeps for d before the first attempt, such as when the first attempt is
+// known to fail. See [WithInitialDelay].
+func DoWithInitialDelay(ctx context.Context, b Backoff, d time.Duration, f RetryFunc, opts ...DoOption) error {
+	return Do(ctx, b, f, appendOptions(opts, WithInitialDelay(d))...)
+}
+
+// DoWithTimeout wraps a function with a backoff to retry, like [Do], but limits
+// each attempt to perAttempt, such as when an attempt can hang on a dead
+// connection. Each attempt's context is canceled after perAttempt, and an
+// attempt that times out is retried, while canceling ctx still stops retrying.
+// See [WithAttemptTimeout].
+func DoWithTimeout(ctx context.Context, b Backoff, perAttempt time.Duration, f RetryFunc, opts ...DoOption) error {
+	return Do(ctx, b, f, appendOptions(opts, WithAttemptTimeout(perAttempt))...)
+}
+
+// DoValueWithPredicate is like [DoWithPredicate], but returns the value from
+// the first successful attempt, like [DoValue].
+func DoValueWithPredicate[T any](ctx context.Context, b Backoff, pred func(err error) bool, f RetryFuncValue[T], opts ...DoOption) (T, error) {
+	return DoValue(ctx, b, f, appendOptions(opts, withRetryPredicate(pred))...)
+}
+
+// backoffObservers returns every backoff in the chain b that implements
+// [ObserverBackoff]. It returns nil for the common chain without any.
+func backoffObservers(b Backoff) []ObserverBackoff {
+	var observers []ObserverBackoff
+	Walk(b, func(node Backoff) bool {
+		if o, ok := node.(ObserverBackoff); ok {
+			observers = append(observers, o)
+		}
+		return true
+	})
+	return observers
+}
+
+// callEnder is implemented by backoffs that keep state for the duration of a
+// call to [Do], such as the lock of [WithLeaderOnly].
+type callEnder interface {
+	endCall()
+}
+
+// endCalls tells every backoff in the chain b that implements callEnder that
+// the call ended. It is called when Do returns, after the final outcome was
+// observed.
+func endCalls(b Backoff) {
+	Walk(b, func(node Backoff) bool {
+		if c, ok := node.(callEnder); ok {
+			c.endCall()
+		}
+		return true
+	})
+}
+
+// oversleepTolerance is how much later than its delay a sleep between attempts
+// may end before time budgets are checked again.
+const oversleepTolerance = 50 * time.Millisecond
+
+// budgetChecker is implemented by backoffs with a time budget, such as
+// [WithMaxDuration].
+type budgetChecker interface {
+	Exceeded(now time.Time) bool
+}
+
+// backoffBudgets returns every backoff in the chain b that implements
+// budgetChecker. It returns nil for the common chain without any.
+func backoffBudgets(b Backoff) []budgetChecker {
+	var budgets []budgetChecker
+	Walk(b, func(node Backoff) bool {
+		if c, ok := node.(budgetChecker); ok {
+			budgets = append(budgets, c)
+		}
+		return true
 	})
+	return budgets
+}
+
+func budgetExceeded(budgets []budgetChecker, now time.Time) bool {
+	for _, b := range budgets {
+		if b.Exceeded(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func observeBackoffs(observers []ObserverBackoff, o Outcome) {
+	for _, b := range observers {
+		b.Observe(o)
+	}
+}
+
+// exceedsDeadline reports whether sleeping for d on clock would end after the
+// deadline of ctx.
+func exceedsDeadline(ctx context.Context, clock Clock, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && d > deadline.Sub(clock.Now())
+}
+
+// contextError returns the error for the done context ctx. If ctx was canceled
+// with a cause that differs from ctx.Err(), the returned error includes the
+// cause and matches both it and ctx.Err() with [errors.Is].
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if cause := context.Cause(ctx); cause != nil && cause != err {
+		return fmt.Errorf("%w: %w", err, cause)
+	}
 	return err
 }
+
+// canceledError returns the error for the done context ctx after a retryable
+// attempt failed with lastErr. The returned error matches both the context's
+// error and lastErr with [errors.Is]. If no attempt has failed yet, it is the
+// same as contextError.
+func canceledError(ctx context.Context, lastErr error) error {
+	err := contextError(ctx)
+	if lastErr == nil {
+		return err
+	}
+	return fmt.Errorf("%w: last attempt error: %w", err, lastErr)
+}
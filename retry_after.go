@@ -0,0 +1,77 @@
+package retry
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// delayLimiter is implemented by wrappers that bound delays, so that a delay
+// requested with RetryableErrorAfter is bound by them too.
+type delayLimiter interface {
+	limitDelay(d time.Duration) time.Duration
+}
+
+// limitDelay returns d limited by every delayLimiter in the chain b.
+func limitDelay(b Backoff, d time.Duration) time.Duration {
+	Walk(b, func(node Backoff) bool {
+		if l, ok := node.(delayLimiter); ok {
+			d = l.limitDelay(d)
+		}
+		return true
+	})
+	return max(d, 0)
+}
+
+func (b *cappedDurationBackoff) limitDelay(d time.Duration) time.Duration {
+	return min(d, b.cap)
+}
+
+func (b *maxDurationBackoff) limitDelay(d time.Duration) time.Duration {
+	if b.timeout >= UnboundedDuration {
+		return d
+	}
+	return min(d, b.remaining())
+}
+
+func (b *leaseBackoff) limitDelay(d time.Duration) time.Duration {
+	return min(d, b.remaining()-b.margin)
+}
+
+// RetryAfter returns the delay requested by the Retry-After header of resp,
+// which is either a number of seconds or an HTTP date. It returns false if resp
+// is nil or the header is missing or invalid. A date in the past yields 0.
+//
+// The delay is typically passed to [RetryableErrorAfter] for responses with a
+// status such as 429 Too Many Requests or 503 Service Unavailable.
+func RetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.ParseUint(v, 10, 64); err == nil {
+		if secs > uint64(maxRetryAfterSeconds) {
+			return time.Duration(maxRetryAfterSeconds) * time.Second, true
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// maxRetryAfterSeconds is the largest number of seconds that fits in a
+// time.Duration.
+const maxRetryAfterSeconds = int64(1<<63-1) / int64(time.Second)
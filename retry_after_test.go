@@ -0,0 +1,249 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// sleepRecorder is a clock that records every sleep instead of sleeping.
+type sleepRecorder struct {
+	*fakeClock
+	slept []time.Duration
+}
+
+func (c *sleepRecorder) Sleep(ctx context.Context, d time.Duration) error {
+	c.slept = append(c.slept, d)
+	return c.fakeClock.Sleep(ctx, d)
+}
+
+func TestRetryableErrorAfter(t *testing.T) {
+	t.Parallel()
+
+	errLimited := errors.New("429 too many requests")
+
+	cases := []struct {
+		name  string
+		b     func(now func() time.Time) retry.Backoff
+		delay time.Duration
+		calls int
+		slept string
+	}{
+		{
+			name: "overrides_backoff",
+			b: func(_ func() time.Time) retry.Backoff {
+				return retry.WithMaxRetries(2, retry.NewConstant(1*time.Second))
+			},
+			delay: 30 * time.Second,
+			calls: 3,
+			slept: "[30s 30s]",
+		},
+		{
+			name: "shorter_than_backoff",
+			b: func(_ func() time.Time) retry.Backoff {
+				return retry.WithMaxRetries(1, retry.NewConstant(1*time.Minute))
+			},
+			delay: 1 * time.Second,
+			calls: 2,
+			slept: "[1s]",
+		},
+		{
+			name: "capped",
+			b: func(_ func() time.Time) retry.Backoff {
+				return retry.WithMaxRetries(1, retry.WithCappedDuration(10*time.Second, retry.NewConstant(1*time.Second)))
+			},
+			delay: 30 * time.Second,
+			calls: 2,
+			slept: "[10s]",
+		},
+		{
+			name: "max_duration",
+			b: func(now func() time.Time) retry.Backoff {
+				return retry.WithMaxDuration(45*time.Second, retry.NewConstant(1*time.Second), retry.WithNowFunc(now))
+			},
+			delay: 30 * time.Second,
+			calls: 3,
+			slept: "[30s 15s]",
+		},
+		{
+			name: "negative",
+			b: func(_ func() time.Time) retry.Backoff {
+				return retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))
+			},
+			delay: -1 * time.Second,
+			calls: 2,
+			slept: "[0s]",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &sleepRecorder{fakeClock: newFakeClock()}
+			b := tc.b(clock.Now)
+
+			var calls int
+			err := retry.Do(context.Background(), b, func(_ context.Context) error {
+				calls++
+				return retry.RetryableErrorAfter(errLimited, tc.delay)
+			}, retry.WithClock(clock))
+			if got, want := err, errLimited; !errors.Is(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := calls, tc.calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := fmt.Sprint(clock.slept), tc.slept; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if err := retry.RetryableErrorAfter(nil, time.Second); err != nil {
+			t.Errorf("expected nil, got %v", err)
+		}
+	})
+
+	t.Run("rewraps_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.RetryableErrorAfter(retry.RetryableError(errLimited), time.Second)
+		if got, want := err.Error(), "retryable: "+errLimited.Error(); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if !errors.Is(err, errLimited) {
+			t.Errorf("expected %v to be %v", err, errLimited)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+		ok     bool
+	}{
+		{
+			name:   "seconds",
+			header: "30",
+			min:    30 * time.Second,
+			max:    30 * time.Second,
+			ok:     true,
+		},
+		{
+			name:   "zero",
+			header: "0",
+			ok:     true,
+		},
+		{
+			name:   "date",
+			header: time.Now().Add(1 * time.Hour).UTC().Format(http.TimeFormat),
+			min:    59 * time.Minute,
+			max:    1 * time.Hour,
+			ok:     true,
+		},
+		{
+			name:   "past_date",
+			header: time.Now().Add(-1 * time.Hour).UTC().Format(http.TimeFormat),
+			ok:     true,
+		},
+		{
+			name:   "huge",
+			header: "99999999999",
+			min:    200 * 365 * 24 * time.Hour,
+			max:    math.MaxInt64,
+			ok:     true,
+		},
+		{
+			name:   "negative",
+			header: "-5",
+			ok:     false,
+		},
+		{
+			name:   "invalid",
+			header: "soon",
+			ok:     false,
+		},
+		{
+			name:   "missing",
+			header: "",
+			ok:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := &http.Response{Header: make(http.Header)}
+			if tc.header != "" {
+				resp.Header.Set("Retry-After", tc.header)
+			}
+
+			d, ok := retry.RetryAfter(resp)
+			if got, want := ok, tc.ok; got != want {
+				t.Fatalf("expected %v to be %v", got, want)
+			}
+			if d < tc.min || d > tc.max {
+				t.Errorf("expected %v to be between %v and %v", d, tc.min, tc.max)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		if _, ok := retry.RetryAfter(nil); ok {
+			t.Error("expected no delay")
+		}
+	})
+}
+
+func ExampleRetryableErrorAfter() {
+	ctx := context.Background()
+
+	b := retry.WithMaxRetries(3, retry.NewExponential(1*time.Second))
+	err := retry.Do(ctx, b, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:0", nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			// Not retryable
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			err := fmt.Errorf("too many requests")
+			if d, ok := retry.RetryAfter(resp); ok {
+				return retry.RetryableErrorAfter(err, d)
+			}
+			return retry.RetryableError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		// handle error
+	}
+}
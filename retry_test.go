@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -20,6 +21,12 @@ func TestRetryableError(t *testing.T) {
 	if got, want := err.Error(), "retryable: "; !strings.Contains(got, want) {
 		t.Errorf("expected %v to contain %v", got, want)
 	}
+	if got, want := retry.RetryableError(err), err; got != want {
+		t.Errorf("expected double wrapping to return %v, got %v", want, got)
+	}
+	if got, want := retry.RetryableError(err).Error(), "retryable: oops"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
 }
 
 func TestDoValue(t *testing.T) {
@@ -107,7 +114,49 @@ func TestDo(t *testing.T) {
 			t.Fatal("expected err")
 		}
 
-		if got, want := err, io.EOF; got != want {
+		if got, want := err, io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+
+	t.Run("unwraps_double_wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(retry.RetryableError(io.EOF))
+		})
+		if got, want := err, io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+	})
+
+	t.Run("unwraps_wrapped_chain", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+		var i int
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			i++
+			return fmt.Errorf("context: %w", retry.RetryableError(io.EOF))
+		})
+		if got, want := i, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := err, io.EOF; !errors.Is(got, want) {
+			t.Errorf("expected %#v to be %#v", got, want)
+		}
+		if got, want := errors.Unwrap(err), io.EOF; got != want {
 			t.Errorf("expected %#v to be %#v", got, want)
 		}
 	})
@@ -140,14 +189,19 @@ func TestDo(t *testing.T) {
 			return 5 * time.Second, false
 		})
 
-		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
 		defer cancel()
 
-		if err := retry.Do(ctx, b, func(_ context.Context) error {
-			return retry.RetryableError(fmt.Errorf("oops")) // no error
-		}); err != context.DeadlineExceeded {
+		errOops := fmt.Errorf("oops")
+		err := retry.Do(ctx, b, func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
 			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
 		}
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
 	})
 }
 
@@ -175,12 +229,22 @@ func ExampleDo_simple() {
 func ExampleDo_customRetry() {
 	ctx := context.Background()
 
+	// The server fails twice with a 503 before responding successfully.
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
 	b := retry.NewFibonacci(1 * time.Nanosecond)
 
 	// This example demonstrates selectively retrying specific errors. Only errors
 	// wrapped with RetryableError are eligible to be retried.
 	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
-		resp, err := http.Get("https://google.com/")
+		resp, err := http.Get(srv.URL)
 		if err != nil {
 			return err
 		}
@@ -190,6 +254,7 @@ func ExampleDo_customRetry() {
 		case 4:
 			return fmt.Errorf("bad response: %v", resp.StatusCode)
 		case 5:
+			fmt.Printf("retrying: %v\n", resp.StatusCode)
 			return retry.RetryableError(fmt.Errorf("bad response: %v", resp.StatusCode))
 		default:
 			return nil
@@ -197,15 +262,34 @@ func ExampleDo_customRetry() {
 	}); err != nil {
 		// handle error
 	}
+
+	fmt.Printf("succeeded after %d requests\n", requests)
+
+	// Output:
+	// retrying: 503
+	// retrying: 503
+	// succeeded after 3 requests
 }
 
 func ExampleDoValue() {
 	ctx := context.Background()
 
+	// The server fails once with a 503 before responding successfully.
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "hello")
+	}))
+	defer srv.Close()
+
 	b := retry.NewFibonacci(1 * time.Nanosecond)
 
 	body, err := retry.DoValue(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) ([]byte, error) {
-		resp, err := http.Get("https://google.com/")
+		resp, err := http.Get(srv.URL)
 		if err != nil {
 			return nil, err
 		}
@@ -224,7 +308,33 @@ func ExampleDoValue() {
 	if err != nil {
 		// handle error
 	}
-	_ = body
+
+	fmt.Printf("%s\n", body)
+
+	// Output:
+	// hello
+}
+
+func ExampleGetRetryCount() {
+	ctx := context.Background()
+
+	b := retry.NewConstant(1 * time.Nanosecond)
+
+	if err := retry.Do(ctx, retry.WithMaxRetries(3, b), func(ctx context.Context) error {
+		n, _ := retry.GetRetryCount(ctx)
+		fmt.Printf("retry %d\n", n)
+		if n < 2 {
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}
+		return nil
+	}); err != nil {
+		// handle error
+	}
+
+	// Output:
+	// retry 0
+	// retry 1
+	// retry 2
 }
 
 func TestCancel(t *testing.T) {
@@ -257,3 +367,738 @@ func TestCancel(t *testing.T) {
 		}
 	}
 }
+
+func TestDo_cancelCause(t *testing.T) {
+	t.Parallel()
+
+	errShutdown := errors.New("shutting down")
+
+	t.Run("before_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(errShutdown)
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			t.Error("expected no attempts")
+			return nil
+		})
+
+		if !errors.Is(err, errShutdown) {
+			t.Errorf("expected %v to be %v", err, errShutdown)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			time.AfterFunc(1*time.Millisecond, func() {
+				cancel(errShutdown)
+			})
+			return retry.RetryableError(fmt.Errorf("oops"))
+		})
+
+		if !errors.Is(err, errShutdown) {
+			t.Errorf("expected %v to be %v", err, errShutdown)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("without_cause", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			return nil
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDo_canceledLastError(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	t.Run("during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		})
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
+		if errors.Is(err, retry.ErrExhausted) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrExhausted)
+		}
+		if got, want := err.Error(), "context deadline exceeded: last attempt error: oops"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("with_cause", func(t *testing.T) {
+		t.Parallel()
+
+		errShutdown := errors.New("shutting down")
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		defer cancel(nil)
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			cancel(errShutdown)
+			return retry.RetryableError(errOops)
+		})
+
+		for _, want := range []error{context.Canceled, errShutdown, errOops} {
+			if !errors.Is(err, want) {
+				t.Errorf("expected %v to be %v", err, want)
+			}
+		}
+	})
+
+	t.Run("before_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		}, retry.WithInitialDelay(1*time.Hour))
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := retry.Do(ctx, retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			cancel()
+			return errOops
+		})
+		if got, want := err, errOops; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestErrExhausted(t *testing.T) {
+	t.Parallel()
+
+	errPermanent := errors.New("permanent")
+
+	cases := []struct {
+		name      string
+		ctx       func() context.Context
+		err       error
+		exhausted bool
+	}{
+		{
+			name:      "exhausted",
+			err:       retry.RetryableError(io.EOF),
+			exhausted: true,
+		},
+		{
+			name: "permanent",
+			err:  errPermanent,
+		},
+		{
+			name: "canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			err: retry.RetryableError(io.EOF),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tc.ctx != nil {
+				ctx = tc.ctx()
+			}
+
+			var hookErr error
+			err := retry.Do(ctx, retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+				return tc.err
+			}, retry.WithStopHook(func(_ retry.StopReason, err error) {
+				hookErr = err
+			}))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got, want := errors.Is(err, retry.ErrExhausted), tc.exhausted; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if !tc.exhausted {
+				return
+			}
+
+			if got, want := errors.Unwrap(err), io.EOF; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := err.Error(), io.EOF.Error(); got != want {
+				t.Errorf("expected %q to be %q", got, want)
+			}
+			if got, want := hookErr, err; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDoWithPredicate(t *testing.T) {
+	t.Parallel()
+
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+	isTransient := func(err error) bool {
+		return errors.Is(err, errTransient)
+	}
+
+	t.Run("retries_matching", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts int
+		err := retry.DoWithPredicate(context.Background(), retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), isTransient, func(_ context.Context) error {
+			attempts++
+			return fmt.Errorf("read: %w", errTransient)
+		})
+		if got, want := attempts, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The last error is returned as is.
+		if got, want := err.Error(), "read: transient"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("stops_on_other", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts int
+		err := retry.DoWithPredicate(context.Background(), retry.NewConstant(1*time.Nanosecond), isTransient, func(_ context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errTransient
+			}
+			return errFatal
+		})
+		if !errors.Is(err, errFatal) {
+			t.Errorf("expected %v to be %v", err, errFatal)
+		}
+		if got, want := attempts, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retryable_and_matching", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts, preds int
+		_ = retry.DoWithPredicate(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(err error) bool {
+			preds++
+			return true
+		}, func(_ context.Context) error {
+			attempts++
+			return retry.RetryableError(errTransient)
+		})
+		if got, want := attempts, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := preds, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts int
+		v, err := retry.DoValueWithPredicate(context.Background(), retry.NewConstant(1*time.Nanosecond), isTransient, func(_ context.Context) (string, error) {
+			attempts++
+			if attempts < 3 {
+				return "", errTransient
+			}
+			return "ok", nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := v, "ok"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("predicate_panics", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected %v to be %v", r, "boom")
+			}
+		}()
+
+		_ = retry.DoWithPredicate(context.Background(), retry.NewConstant(1*time.Nanosecond), func(error) bool {
+			panic("boom")
+		}, func(_ context.Context) error {
+			return errFatal
+		})
+		t.Error("expected panic")
+	})
+}
+
+func TestDoWithFactory(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	// run makes a call that fails 3 times and returns the delays it slept.
+	run := func(do func(f retry.RetryFunc, opts ...retry.DoOption) error) string {
+		var calls int
+		var delays []time.Duration
+		if err := do(func(_ context.Context) error {
+			calls++
+			if calls > 3 {
+				return nil
+			}
+			return retry.RetryableError(errOops)
+		}, retry.WithClock(newFakeClock()), retry.WithHooks(retry.Hooks{
+			OnRetry: func(_ uint64, delay time.Duration, _ error) {
+				delays = append(delays, delay)
+			},
+		})); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprint(delays)
+	}
+
+	t.Run("factory", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		newBackoff := func() retry.Backoff {
+			return retry.WithMaxRetries(5, retry.NewExponential(1*time.Second))
+		}
+		do := func(f retry.RetryFunc, opts ...retry.DoOption) error {
+			return retry.DoWithFactory(ctx, newBackoff, f, opts...)
+		}
+
+		// Both calls start from the base delay.
+		for i := 0; i < 2; i++ {
+			if got, want := run(do), "[1s 2s 4s]"; got != want {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		b := retry.NewExponential(1 * time.Second)
+		do := func(f retry.RetryFunc, opts ...retry.DoOption) error {
+			return retry.Do(ctx, b, f, opts...)
+		}
+
+		// A shared backoff continues where the previous call left off.
+		if got, want := run(do), "[1s 2s 4s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := run(do), "[8s 16s 32s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleDoWithFactory() {
+	ctx := context.Background()
+
+	// Define the policy once; every call gets a fresh backoff.
+	newBackoff := func() retry.Backoff {
+		b := retry.NewExponential(100 * time.Millisecond)
+		return retry.WithMaxRetries(3, b)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := retry.DoWithFactory(ctx, newBackoff, func(_ context.Context) error {
+			// TODO: logic here
+			return nil
+		}); err != nil {
+			// handle error
+		}
+	}
+}
+
+func TestDoWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("later_attempt_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		err := retry.DoWithTimeout(context.Background(), retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), 10*time.Millisecond, func(ctx context.Context) error {
+			calls++
+			if calls == 1 {
+				// Hang until the attempt times out.
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return ctx.Err()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := calls, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("parent_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var calls int
+		err := retry.DoWithTimeout(ctx, retry.NewConstant(1*time.Nanosecond), 1*time.Hour, func(ctx context.Context) error {
+			calls++
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestDoWithInitialDelay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("sleeps_first", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &sleepRecorder{fakeClock: newFakeClock()}
+		b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Second))
+
+		var first string
+		err := retry.DoWithInitialDelay(context.Background(), b, 5*time.Second, func(_ context.Context) error {
+			if first == "" {
+				first = fmt.Sprint(clock.slept)
+			}
+			return retry.RetryableError(errors.New("not ready"))
+		}, retry.WithClock(clock))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if got, want := first, "[5s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(clock.slept), "[5s 1s]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled_during_delay", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		var calls int
+		err := retry.DoWithInitialDelay(ctx, retry.NewConstant(1*time.Nanosecond), 1*time.Hour, func(_ context.Context) error {
+			calls++
+			return nil
+		})
+		if got, want := err, context.DeadlineExceeded; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := calls, 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("default_unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		clock := &sleepRecorder{fakeClock: newFakeClock()}
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Second), func(_ context.Context) error {
+			return nil
+		}, retry.WithClock(clock), retry.WithInitialDelay(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := len(clock.slept), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+// wrapN wraps err n times with fmt.Errorf.
+func wrapN(err error, n int) error {
+	for i := 0; i < n; i++ {
+		err = fmt.Errorf("layer %d: %w", i, err)
+	}
+	return err
+}
+
+// retryableAs is an error that reports itself as retryable only through its As
+// method, and counts how often that method is called.
+type retryableAs struct {
+	calls int
+}
+
+func (e *retryableAs) Error() string { return "custom" }
+
+func (e *retryableAs) As(target any) bool {
+	e.calls++
+	return errors.As(retry.RetryableError(errors.New("custom")), target)
+}
+
+// sliceError is an error with an incomparable type that wraps err.
+type sliceError struct {
+	msgs []string
+	err  error
+}
+
+func (e sliceError) Error() string { return fmt.Sprint(e.msgs) }
+func (e sliceError) Unwrap() error { return e.err }
+
+func TestDo_errorChains(t *testing.T) {
+	t.Parallel()
+
+	errFail := errors.New("fail")
+
+	cases := []struct {
+		name  string
+		err   func() error
+		opts  []retry.DoOption
+		retry bool
+	}{
+		{
+			name:  "deep",
+			err:   func() error { return wrapN(retry.RetryableError(errFail), 16) },
+			retry: true,
+		},
+		{
+			name:  "beyond_depth",
+			err:   func() error { return wrapN(retry.RetryableError(errFail), 16) },
+			opts:  []retry.DoOption{retry.WithErrorWalkDepth(4)},
+			retry: true,
+		},
+		{
+			name:  "not_retryable",
+			err:   func() error { return wrapN(errFail, 16) },
+			retry: false,
+		},
+		{
+			name:  "joined",
+			err:   func() error { return wrapN(errors.Join(errFail, retry.RetryableError(errFail)), 4) },
+			retry: true,
+		},
+		{
+			name:  "joined_not_retryable",
+			err:   func() error { return errors.Join(errFail, wrapN(errFail, 4)) },
+			retry: false,
+		},
+		{
+			name:  "custom_as",
+			err:   func() error { return wrapN(&retryableAs{}, 4) },
+			retry: true,
+		},
+		{
+			name:  "incomparable",
+			err:   func() error { return sliceError{msgs: []string{"a"}, err: retry.RetryableError(errFail)} },
+			retry: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int
+			_ = retry.Do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+				calls++
+				return tc.err()
+			}, tc.opts...)
+
+			want := 1
+			if tc.retry {
+				want = 3
+			}
+			if got := calls; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		custom := &retryableAs{}
+		err := wrapN(custom, 4)
+
+		var calls int
+		_ = retry.Do(context.Background(), retry.WithMaxRetries(4, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			calls++
+			return err
+		})
+		if got, want := calls, 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := custom.calls, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func BenchmarkDo(b *testing.B) {
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		backoff := retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond))
+
+		_ = retry.Do(ctx, backoff, func(_ context.Context) error {
+			return retry.RetryableError(errors.New("oops"))
+		})
+	}
+}
+
+func BenchmarkDo_highCancellation(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ctx, cancel := context.WithCancel(context.Background())
+			backoff := retry.NewConstant(1 * time.Hour)
+
+			// Cancel concurrently, typically during the first sleep.
+			_ = retry.Do(ctx, backoff, func(_ context.Context) error {
+				go cancel()
+				return retry.RetryableError(errors.New("oops"))
+			})
+			cancel()
+		}
+	})
+}
+
+func BenchmarkDo_failOnce(b *testing.B) {
+	ctx := context.Background()
+	errFail := errors.New("fail")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var calls int
+		_ = retry.Do(ctx, retry.NewConstant(1*time.Nanosecond), func(_ context.Context) error {
+			calls++
+			if calls == 1 {
+				return retry.RetryableError(errFail)
+			}
+			return nil
+		})
+	}
+}
+
+func BenchmarkRetryableError_doubleWrap(b *testing.B) {
+	err := retry.RetryableError(errors.New("fail"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		errSink = retry.RetryableError(err)
+	}
+}
+
+// BenchmarkDo_deepChain compares classifying an error wrapped 16 deep by
+// walking the chain, and by falling back to errors.As immediately. Each call
+// returns a new chain, so the classification is not cached.
+func BenchmarkDo_deepChain(b *testing.B) {
+	ctx := context.Background()
+	errFail := retry.RetryableError(errors.New("fail"))
+
+	chains := make([]error, 4)
+	for i := range chains {
+		chains[i] = wrapN(errFail, 16)
+	}
+
+	run := func(b *testing.B, opts ...retry.DoOption) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var calls int
+			_ = retry.Do(ctx, retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+				calls++
+				return chains[calls-1]
+			}, opts...)
+		}
+	}
+
+	b.Run("walk", func(b *testing.B) {
+		run(b, retry.WithErrorWalkDepth(32))
+	})
+	b.Run("errors_as", func(b *testing.B) {
+		run(b, retry.WithErrorWalkDepth(1))
+	})
+}
+
+// BenchmarkDo_repeatedError measures classifying the same error value, which
+// has a custom As method, on every attempt.
+func BenchmarkDo_repeatedError(b *testing.B) {
+	ctx := context.Background()
+	err := wrapN(&retryableAs{}, 16)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = retry.Do(ctx, retry.WithMaxRetries(3, retry.NewConstant(1*time.Nanosecond)), func(_ context.Context) error {
+			return err
+		})
+	}
+}
+
+var errSink error
@@ -0,0 +1,12 @@
+module github.com/sethvargo/go-retry/retryaws
+
+go 1.24
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.47.1
+	github.com/sethvargo/go-retry v0.3.1-0.20261015135354-ae41c838adbc
+)
+
+require github.com/aws/smithy-go v1.28.1 // indirect
+
+replace github.com/sethvargo/go-retry => ../
@@ -0,0 +1,123 @@
+// Package retryaws adapts backoffs from github.com/sethvargo/go-retry to the
+// AWS SDK for Go v2 retryer interface.
+//
+// It lives in a separate module so that the parent module remains free of
+// external dependencies.
+package retryaws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsretry "github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/sethvargo/go-retry"
+)
+
+const (
+	// ClassThrottle is the error class for errors the SDK considers throttling.
+	ClassThrottle = "throttle"
+
+	// ClassTransient is the error class for retryable errors that are not
+	// throttling, such as connection errors and 5xx responses.
+	ClassTransient = "transient"
+)
+
+var _ aws.RetryerV2 = (*Retryer)(nil)
+
+// Retryer implements [aws.RetryerV2] using a backoff from the retry package.
+type Retryer struct {
+	factory     func() retry.Backoff
+	maxAttempts int
+
+	retryables []awsretry.IsErrorRetryable
+	throttles  []awsretry.IsErrorThrottle
+}
+
+// NewRetryer creates a new AWS SDK retryer. The factory is called to build a
+// fresh backoff for every delay computation, so it must return a new backoff
+// each time it is called. maxAttempts is the maximum number of attempts,
+// including the first; a value of 0 means attempts are limited only by the
+// backoff.
+//
+// Errors are classified using the SDK's default retryable and throttle checks.
+// If the backoff implements [retry.ErrorBackoff], it receives the error being
+// retried, so it can be combined with [retry.WithErrorBudgets] and [Classify].
+func NewRetryer(factory func() retry.Backoff, maxAttempts int) aws.RetryerV2 {
+	return &Retryer{
+		factory:     factory,
+		maxAttempts: maxAttempts,
+		retryables:  append([]awsretry.IsErrorRetryable{}, awsretry.DefaultRetryables...),
+		throttles:   append([]awsretry.IsErrorThrottle{}, awsretry.DefaultThrottles...),
+	}
+}
+
+// Classify returns the error class of err according to the SDK's default
+// classifications: [ClassThrottle], [ClassTransient], or the empty string if
+// err is not retryable.
+func Classify(err error) string {
+	if awsretry.IsErrorThrottles(awsretry.DefaultThrottles).IsErrorThrottle(err).Bool() {
+		return ClassThrottle
+	}
+	if awsretry.IsErrorRetryables(awsretry.DefaultRetryables).IsErrorRetryable(err).Bool() {
+		return ClassTransient
+	}
+	return ""
+}
+
+// IsErrorRetryable implements aws.Retryer.
+func (r *Retryer) IsErrorRetryable(err error) bool {
+	return awsretry.IsErrorRetryables(r.retryables).IsErrorRetryable(err).Bool()
+}
+
+// MaxAttempts implements aws.Retryer.
+func (r *Retryer) MaxAttempts() int {
+	return r.maxAttempts
+}
+
+// RetryDelay implements aws.Retryer. The attempt is the number of attempts made
+// so far, starting at 1. A fresh backoff is built from the factory and advanced
+// attempt times, so the delay depends only on the attempt number and not on
+// other requests sharing the retryer.
+func (r *Retryer) RetryDelay(attempt int, opErr error) (time.Duration, error) {
+	if attempt < 1 {
+		return 0, fmt.Errorf("invalid attempt %d", attempt)
+	}
+
+	b := r.factory()
+	eb, isErrorBackoff := b.(retry.ErrorBackoff)
+
+	var val time.Duration
+	for i := 0; i < attempt; i++ {
+		var stop bool
+		if isErrorBackoff {
+			val, stop = eb.NextError(opErr)
+		} else {
+			val, stop = b.Next()
+		}
+		if stop {
+			return 0, fmt.Errorf("backoff stopped after %d attempts: %w", i+1, opErr)
+		}
+	}
+	return val, nil
+}
+
+// GetRetryToken implements aws.Retryer. Retry tokens are not used.
+func (r *Retryer) GetRetryToken(context.Context, error) (func(error) error, error) {
+	return nopReleaseToken, nil
+}
+
+// GetInitialToken implements aws.Retryer. Retry tokens are not used.
+func (r *Retryer) GetInitialToken() func(error) error {
+	return nopReleaseToken
+}
+
+// GetAttemptToken implements aws.RetryerV2. Retry tokens are not used.
+func (r *Retryer) GetAttemptToken(context.Context) (func(error) error, error) {
+	return nopReleaseToken, nil
+}
+
+func nopReleaseToken(error) error {
+	return nil
+}
@@ -0,0 +1,211 @@
+package retryaws_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryaws"
+)
+
+type apiError struct {
+	code string
+}
+
+func (e *apiError) Error() string {
+	return "api error " + e.code
+}
+
+func (e *apiError) ErrorCode() string {
+	return e.code
+}
+
+func TestRetryer(t *testing.T) {
+	t.Parallel()
+
+	errThrottle := &apiError{code: "Throttling"}
+	errTransient := &apiError{code: "RequestTimeout"}
+	errPermanent := &apiError{code: "AccessDenied"}
+
+	t.Run("is_error_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.NewExponential(1 * time.Second)
+		}, 3)
+
+		cases := []struct {
+			err error
+			exp bool
+		}{
+			{errThrottle, true},
+			{errTransient, true},
+			{errPermanent, false},
+			{fmt.Errorf("wrapped: %w", errThrottle), true},
+			{errors.New("oops"), false},
+		}
+
+		for _, tc := range cases {
+			if got, want := r.IsErrorRetryable(tc.err), tc.exp; got != want {
+				t.Errorf("%v: expected %v to be %v", tc.err, got, want)
+			}
+		}
+	})
+
+	t.Run("max_attempts", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.NewExponential(1 * time.Second)
+		}, 5)
+
+		if got, want := r.MaxAttempts(), 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retry_delay_sequence", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.NewExponential(100 * time.Millisecond)
+		}, 5)
+
+		// A recorded sequence of throttling errors, as the SDK's attempt
+		// middleware would report them.
+		exp := []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+		}
+		for i, want := range exp {
+			got, err := r.RetryDelay(i+1, errThrottle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("attempt %d: expected %v to be %v", i+1, got, want)
+			}
+		}
+
+		// Delays are per-request; asking again for an earlier attempt yields the
+		// same value.
+		got, err := r.RetryDelay(1, errThrottle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 100 * time.Millisecond; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("retry_delay_stop", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.WithMaxRetries(2, retry.NewConstant(1*time.Second))
+		}, 0)
+
+		if _, err := r.RetryDelay(2, errTransient); err != nil {
+			t.Fatal(err)
+		}
+		_, err := r.RetryDelay(3, errTransient)
+		if err == nil {
+			t.Fatal("expected err")
+		}
+		if !errors.Is(err, errTransient) {
+			t.Errorf("expected %v to wrap %v", err, errTransient)
+		}
+	})
+
+	t.Run("retry_delay_invalid_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.NewConstant(1 * time.Second)
+		}, 0)
+
+		if _, err := r.RetryDelay(0, errTransient); err == nil {
+			t.Fatal("expected err")
+		}
+	})
+
+	t.Run("error_budgets", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.WithErrorBudgets(map[string]uint64{
+				retryaws.ClassThrottle:  4,
+				retryaws.ClassTransient: 1,
+			}, retryaws.Classify, retry.NewConstant(1*time.Second))
+		}, 0)
+
+		if _, err := r.RetryDelay(4, errThrottle); err != nil {
+			t.Errorf("expected throttle attempt 4 to be allowed: %v", err)
+		}
+		if _, err := r.RetryDelay(1, errTransient); err != nil {
+			t.Errorf("expected transient attempt 1 to be allowed: %v", err)
+		}
+		if _, err := r.RetryDelay(2, errTransient); err == nil {
+			t.Error("expected transient attempt 2 to be denied")
+		}
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		t.Parallel()
+
+		r := retryaws.NewRetryer(func() retry.Backoff {
+			return retry.NewConstant(1 * time.Second)
+		}, 0)
+
+		if err := r.GetInitialToken()(nil); err != nil {
+			t.Error(err)
+		}
+
+		release, err := r.GetRetryToken(context.Background(), errThrottle)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := release(nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err error
+		exp string
+	}{
+		{&apiError{code: "Throttling"}, retryaws.ClassThrottle},
+		{&apiError{code: "ThrottlingException"}, retryaws.ClassThrottle},
+		{&apiError{code: "RequestTimeout"}, retryaws.ClassTransient},
+		{&apiError{code: "AccessDenied"}, ""},
+	}
+
+	for _, tc := range cases {
+		if got, want := retryaws.Classify(tc.err), tc.exp; got != want {
+			t.Errorf("%v: expected %q to be %q", tc.err, got, want)
+		}
+	}
+}
+
+func ExampleNewRetryer() {
+	cfg := aws.Config{
+		Retryer: func() aws.Retryer {
+			return retryaws.NewRetryer(func() retry.Backoff {
+				b := retry.NewExponential(100 * time.Millisecond)
+				b = retry.WithJitterPercent(10, b)
+				return retry.WithCappedDuration(20*time.Second, b)
+			}, 5)
+		},
+	}
+	_ = cfg
+}
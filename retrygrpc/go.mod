@@ -0,0 +1,16 @@
+module github.com/sethvargo/go-retry/retrygrpc
+
+go 1.25.0
+
+require (
+	github.com/sethvargo/go-retry v0.3.0
+	google.golang.org/grpc v1.84.0
+)
+
+require (
+	golang.org/x/sys v0.47.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260706201446-f0a921348800 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
+
+replace github.com/sethvargo/go-retry => ../
@@ -0,0 +1,231 @@
+// Package retrygrpc integrates github.com/sethvargo/go-retry with gRPC.
+//
+// It lives in a separate module so that the parent module remains free of
+// external dependencies.
+package retrygrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxAttemptsLimit is the maximum number of attempts gRPC clients allow,
+// regardless of the service config.
+const maxAttemptsLimit = 5
+
+type serviceConfig struct {
+	MethodConfig []methodConfig `json:"methodConfig"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy *retryPolicy `json:"retryPolicy"`
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int               `json:"maxAttempts"`
+	InitialBackoff       string            `json:"initialBackoff"`
+	MaxBackoff           string            `json:"maxBackoff"`
+	BackoffMultiplier    float64           `json:"backoffMultiplier"`
+	RetryableStatusCodes []json.RawMessage `json:"retryableStatusCodes"`
+}
+
+// FromServiceConfig builds a backoff and a retryable predicate from the
+// retryPolicy for method in a gRPC service config, as described in gRFC A6.
+// The method has the form "package.Service/Method", optionally with a leading
+// slash. As in gRPC, a config naming the method takes precedence over one
+// naming only its service, which takes precedence over a config with an empty
+// name.
+//
+// The backoff starts at initialBackoff, is multiplied by backoffMultiplier
+// after each retry, is capped at maxBackoff, and stops after maxAttempts
+// attempts, which gRPC limits to 5. Unlike gRPC clients, the delays are not
+// randomized; wrap the backoff with [retry.WithJitterPercent] if needed. The
+// backoff is stateful, so FromServiceConfig must be called for every retry
+// loop.
+//
+// The predicate reports whether an error carries one of the
+// retryableStatusCodes, which decides whether to wrap the error with
+// [retry.RetryableError].
+//
+// Unknown fields are ignored. An error is returned if the JSON is malformed, no
+// retry policy applies to method, or the policy is invalid.
+func FromServiceConfig(jsonBlob []byte, method string) (retry.Backoff, func(error) bool, error) {
+	var cfg serviceConfig
+	if err := json.Unmarshal(jsonBlob, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse service config: %w", err)
+	}
+
+	service, name, ok := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if !ok || service == "" || name == "" {
+		return nil, nil, fmt.Errorf("invalid method %q: must be of the form \"service/method\"", method)
+	}
+
+	policy := findRetryPolicy(cfg.MethodConfig, service, name)
+	if policy == nil {
+		return nil, nil, fmt.Errorf("no retry policy for method %q", method)
+	}
+	return policy.build()
+}
+
+// findRetryPolicy returns the retry policy of the most specific method config
+// matching the service and method, or nil if none match.
+func findRetryPolicy(configs []methodConfig, service, method string) *retryPolicy {
+	var byService, byDefault *methodConfig
+	for i := range configs {
+		mc := &configs[i]
+		for _, n := range mc.Name {
+			switch {
+			case n.Service == service && n.Method == method:
+				return mc.RetryPolicy
+			case n.Service == service && n.Method == "":
+				if byService == nil {
+					byService = mc
+				}
+			case n.Service == "" && n.Method == "":
+				if byDefault == nil {
+					byDefault = mc
+				}
+			}
+		}
+	}
+
+	if byService != nil {
+		return byService.RetryPolicy
+	}
+	if byDefault != nil {
+		return byDefault.RetryPolicy
+	}
+	return nil
+}
+
+func (p *retryPolicy) build() (retry.Backoff, func(error) bool, error) {
+	if p.MaxAttempts < 2 {
+		return nil, nil, fmt.Errorf("invalid maxAttempts %d: must be greater than 1", p.MaxAttempts)
+	}
+	maxAttempts := p.MaxAttempts
+	if maxAttempts > maxAttemptsLimit {
+		maxAttempts = maxAttemptsLimit
+	}
+
+	initial, err := parseDuration(p.InitialBackoff)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid initialBackoff: %w", err)
+	}
+	max, err := parseDuration(p.MaxBackoff)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid maxBackoff: %w", err)
+	}
+
+	// The negated comparison also rejects NaN.
+	if !(p.BackoffMultiplier > 0) || math.IsInf(p.BackoffMultiplier, 1) {
+		return nil, nil, fmt.Errorf("invalid backoffMultiplier %v: must be greater than 0", p.BackoffMultiplier)
+	}
+
+	if len(p.RetryableStatusCodes) == 0 {
+		return nil, nil, fmt.Errorf("invalid retryableStatusCodes: must not be empty")
+	}
+	retryable := make(map[codes.Code]struct{}, len(p.RetryableStatusCodes))
+	for _, raw := range p.RetryableStatusCodes {
+		code, err := parseCode(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid retryableStatusCodes: %w", err)
+		}
+		retryable[code] = struct{}{}
+	}
+
+	b := retry.WithMaxRetries(uint64(maxAttempts-1), &multiplierBackoff{
+		next:       float64(initial),
+		multiplier: p.BackoffMultiplier,
+		max:        max,
+	})
+
+	isRetryable := func(err error) bool {
+		s, ok := status.FromError(err)
+		if !ok {
+			return false
+		}
+		_, ok = retryable[s.Code()]
+		return ok
+	}
+	return b, isRetryable, nil
+}
+
+// durationRe matches the JSON encoding of google.protobuf.Duration.
+var durationRe = regexp.MustCompile(`^\d+(\.\d{1,9})?s$`)
+
+// parseDuration parses a positive duration in the JSON encoding of
+// google.protobuf.Duration, such as "0.1s".
+func parseDuration(s string) (time.Duration, error) {
+	if !durationRe.MatchString(s) {
+		return 0, fmt.Errorf("%q is not a duration in seconds, such as \"0.1s\"", s)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q: %w", s, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%q must be greater than 0", s)
+	}
+	return d, nil
+}
+
+// parseCode parses a status code given as a name, such as "UNAVAILABLE", or as
+// a number.
+func parseCode(raw json.RawMessage) (codes.Code, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		var code codes.Code
+		if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name)))); err != nil {
+			return 0, fmt.Errorf("unknown status code %q", name)
+		}
+		return code, nil
+	}
+
+	var n uint32
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return 0, fmt.Errorf("status code %s must be a name or a number", raw)
+	}
+	if n > uint32(codes.Unauthenticated) {
+		return 0, fmt.Errorf("unknown status code %d", n)
+	}
+	return codes.Code(n), nil
+}
+
+// multiplierBackoff is an exponential backoff with an arbitrary multiplier,
+// capped at max.
+type multiplierBackoff struct {
+	multiplier float64
+	max        time.Duration
+
+	lock sync.Mutex
+	next float64
+}
+
+// Next implements retry.Backoff.
+func (b *multiplierBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	val := b.max
+	if b.next < float64(b.max) {
+		val = time.Duration(b.next)
+		b.next *= b.multiplier
+	}
+	return val, false
+}
@@ -0,0 +1,208 @@
+package retrygrpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retrygrpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serviceConfig is the example from gRFC A6, with an additional service-wide
+// and default config.
+const serviceConfig = `{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [
+    {
+      "name": [{"service": "grpc.testing.TestService", "method": "UnaryCall"}],
+      "retryPolicy": {
+        "maxAttempts": 4,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 2,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    },
+    {
+      "name": [{"service": "grpc.testing.TestService"}],
+      "waitForReady": true,
+      "retryPolicy": {
+        "maxAttempts": 10,
+        "initialBackoff": "0.5s",
+        "maxBackoff": "2s",
+        "backoffMultiplier": 1.5,
+        "retryableStatusCodes": ["RESOURCE_EXHAUSTED", 14]
+      }
+    },
+    {
+      "name": [{}],
+      "retryPolicy": {
+        "maxAttempts": 2,
+        "initialBackoff": "1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 1,
+        "retryableStatusCodes": ["unavailable"]
+      }
+    }
+  ]
+}`
+
+// preview returns the delays of b until it stops, up to 10.
+func preview(b retry.Backoff) []time.Duration {
+	var delays []time.Duration
+	for i := 0; i < 10; i++ {
+		val, stop := b.Next()
+		if stop {
+			break
+		}
+		delays = append(delays, val)
+	}
+	return delays
+}
+
+func TestFromServiceConfig(t *testing.T) {
+	t.Parallel()
+
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	exhausted := status.Error(codes.ResourceExhausted, "exhausted")
+	internal := status.Error(codes.Internal, "internal")
+
+	cases := []struct {
+		name      string
+		method    string
+		delays    []time.Duration
+		retryable []error
+		permanent []error
+	}{
+		{
+			name:   "method",
+			method: "/grpc.testing.TestService/UnaryCall",
+			delays: []time.Duration{
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				400 * time.Millisecond,
+			},
+			retryable: []error{unavailable, fmt.Errorf("wrapped: %w", unavailable)},
+			permanent: []error{exhausted, internal, errors.New("not a status")},
+		},
+		{
+			name:   "service",
+			method: "grpc.testing.TestService/StreamingCall",
+			// maxAttempts is limited to 5.
+			delays: []time.Duration{
+				500 * time.Millisecond,
+				750 * time.Millisecond,
+				1125 * time.Millisecond,
+				1687500 * time.Microsecond,
+			},
+			retryable: []error{unavailable, exhausted},
+			permanent: []error{internal},
+		},
+		{
+			name:      "default",
+			method:    "other.Service/Call",
+			delays:    []time.Duration{1 * time.Second},
+			retryable: []error{unavailable},
+			permanent: []error{exhausted},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, isRetryable, err := retrygrpc.FromServiceConfig([]byte(serviceConfig), tc.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := preview(b), tc.delays; !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			for _, err := range tc.retryable {
+				if !isRetryable(err) {
+					t.Errorf("expected %v to be retryable", err)
+				}
+			}
+			for _, err := range tc.permanent {
+				if isRetryable(err) {
+					t.Errorf("expected %v not to be retryable", err)
+				}
+			}
+		})
+	}
+}
+
+func TestFromServiceConfig_errors(t *testing.T) {
+	t.Parallel()
+
+	policy := func(p string) string {
+		return `{"methodConfig": [{"name": [{"service": "s"}], "retryPolicy": ` + p + `}]}`
+	}
+
+	cases := []struct {
+		name   string
+		config string
+		method string
+		want   string
+	}{
+		{"malformed", `{`, "s/m", "failed to parse"},
+		{"bad_method", policy(`{}`), "s", "invalid method"},
+		{"no_policy", `{"methodConfig": [{"name": [{"service": "other"}]}]}`, "s/m", "no retry policy"},
+		{"max_attempts", policy(`{"maxAttempts": 1}`), "s/m", "invalid maxAttempts"},
+		{"duration_unit", policy(`{"maxAttempts": 2, "initialBackoff": "100ms"}`), "s/m", "invalid initialBackoff"},
+		{"duration_negative", policy(`{"maxAttempts": 2, "initialBackoff": "-1s"}`), "s/m", "invalid initialBackoff"},
+		{"duration_zero", policy(`{"maxAttempts": 2, "initialBackoff": "1s", "maxBackoff": "0s"}`), "s/m", "invalid maxBackoff"},
+		{"multiplier", policy(`{"maxAttempts": 2, "initialBackoff": "1s", "maxBackoff": "1s"}`), "s/m", "invalid backoffMultiplier"},
+		{"no_codes", policy(`{"maxAttempts": 2, "initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 2}`), "s/m", "must not be empty"},
+		{"unknown_code", policy(`{"maxAttempts": 2, "initialBackoff": "1s", "maxBackoff": "1s", "backoffMultiplier": 2, "retryableStatusCodes": ["NOPE"]}`), "s/m", "unknown status code"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := retrygrpc.FromServiceConfig([]byte(tc.config), tc.method)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected %v to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func ExampleFromServiceConfig() {
+	b, isRetryable, err := retrygrpc.FromServiceConfig([]byte(serviceConfig), "grpc.testing.TestService/UnaryCall")
+	if err != nil {
+		// handle error
+	}
+
+	ctx := context.Background()
+	var attempts int
+	err = retry.Do(ctx, retry.WithMaxDuration(1*time.Second, b), func(_ context.Context) error {
+		attempts++
+		err := status.Error(codes.Unavailable, "unavailable")
+		if attempts == 2 {
+			err = status.Error(codes.PermissionDenied, "denied")
+		}
+
+		if isRetryable(err) {
+			return retry.RetryableError(err)
+		}
+		return err
+	})
+	fmt.Println(attempts, status.Code(err))
+
+	// Output:
+	// 2 PermissionDenied
+}
@@ -0,0 +1,40 @@
+// Package retryotel integrates github.com/sethvargo/go-retry with OpenTelemetry.
+//
+// It lives in a separate module so that the parent module remains free of
+// external dependencies.
+package retryotel
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sethvargo/go-retry"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// AttemptKey is the baggage key holding the attempt number, starting at 1.
+const AttemptKey = "retry.attempt"
+
+// SetBaggage returns a copy of ctx whose OpenTelemetry baggage contains the
+// attempt number under [AttemptKey], replacing any previous value. Other
+// members of the baggage are preserved. It has the signature expected by
+// [retry.WithBaggage].
+func SetBaggage(ctx context.Context, attempt uint64) context.Context {
+	m, err := baggage.NewMemberRaw(AttemptKey, strconv.FormatUint(attempt, 10))
+	if err != nil {
+		return ctx
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(m)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, b)
+}
+
+// WithBaggage returns an option that adds the attempt number to the
+// OpenTelemetry baggage of each attempt's context. It is shorthand for
+// retry.WithBaggage(SetBaggage).
+func WithBaggage() retry.DoOption {
+	return retry.WithBaggage(SetBaggage)
+}
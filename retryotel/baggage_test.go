@@ -0,0 +1,73 @@
+package retryotel_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+	"github.com/sethvargo/go-retry/retryotel"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+func TestSetBaggage(t *testing.T) {
+	t.Parallel()
+
+	m, err := baggage.NewMemberRaw("tenant", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := baggage.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := baggage.ContextWithBaggage(context.Background(), b)
+
+	ctx = retryotel.SetBaggage(ctx, 1)
+	ctx = retryotel.SetBaggage(ctx, 2)
+
+	got := baggage.FromContext(ctx)
+	if got, want := got.Member(retryotel.AttemptKey).Value(), "2"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := got.Member("tenant").Value(), "acme"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestWithBaggage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var attempts []string
+	if err := retry.Do(ctx, retry.WithMaxRetries(2, retry.NewConstant(1*time.Nanosecond)), func(ctx context.Context) error {
+		attempts = append(attempts, baggage.FromContext(ctx).Member(retryotel.AttemptKey).Value())
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}, retryotel.WithBaggage()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if got, want := fmt.Sprint(attempts), "[1 2 3]"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got := baggage.FromContext(ctx).Len(); got != 0 {
+		t.Errorf("expected caller baggage to be empty, got %d members", got)
+	}
+}
+
+func ExampleWithBaggage() {
+	ctx := context.Background()
+	b := retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond))
+
+	_ = retry.Do(ctx, b, func(ctx context.Context) error {
+		// Instrumented clients propagate the baggage to downstream services.
+		fmt.Println(baggage.FromContext(ctx).Member(retryotel.AttemptKey).Value())
+		return retry.RetryableError(fmt.Errorf("oops"))
+	}, retryotel.WithBaggage())
+
+	// Output:
+	// 1
+	// 2
+}
@@ -0,0 +1,10 @@
+module github.com/sethvargo/go-retry/retryotel
+
+go 1.25.0
+
+require (
+	github.com/sethvargo/go-retry v0.3.1-0.20261015135354-ae41c838adbc
+	go.opentelemetry.io/otel v1.46.0
+)
+
+replace github.com/sethvargo/go-retry => ../
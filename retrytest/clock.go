@@ -0,0 +1,100 @@
+package retrytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+var _ retry.Clock = (*FakeClock)(nil)
+
+// FakeClock is a [retry.Clock] whose time only moves when Advance is called.
+// Calls to Sleep block until the clock has been advanced past their deadline or
+// their context is done.
+//
+// It is safe for concurrent use.
+type FakeClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	until time.Time
+	ch    chan struct{}
+}
+
+// NewFakeClock creates a new fake clock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Sleep blocks until the clock is advanced by at least d or ctx is done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+
+	c.lock.Lock()
+	w := &fakeWaiter{until: c.now.Add(d), ch: make(chan struct{})}
+	c.waiters = append(c.waiters, w)
+	c.lock.Unlock()
+
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+		c.lock.Lock()
+		c.remove(w)
+		c.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Advance moves the clock forward by d, waking every sleeper whose deadline has
+// been reached.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = c.now.Add(d)
+
+	remaining := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.until.After(c.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		close(w.ch)
+	}
+	c.waiters = remaining
+}
+
+// Sleepers returns the number of calls to Sleep that are currently blocked.
+func (c *FakeClock) Sleepers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.waiters)
+}
+
+// remove removes w from the waiters. The caller must hold the lock.
+func (c *FakeClock) remove(w *fakeWaiter) {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
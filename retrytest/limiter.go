@@ -0,0 +1,192 @@
+// Package retrytest provides utilities for testing code that uses and extends
+// github.com/sethvargo/go-retry.
+package retrytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// NewLimiterFunc creates a new limiter for a contract test. The limiter must
+// add rate tokens per second, hold at most burst tokens, start full, and read
+// time from clock.
+type NewLimiterFunc func(rate float64, burst int, clock retry.Clock) retry.Limiter
+
+// promptly is how long the contract waits for operations that must not block.
+const promptly = 5 * time.Second
+
+// LimiterContract runs the behavior every [retry.Limiter] must satisfy against
+// limiters created by newLimiter:
+//
+//   - tokens are never granted twice under concurrency
+//   - canceling the context while waiting returns promptly
+//   - tokens are refilled at the configured rate
+//   - a limiter with a burst of 0 rejects immediately
+func LimiterContract(t *testing.T, newLimiter NewLimiterFunc) {
+	t.Helper()
+
+	t.Run("no_double_grant", func(t *testing.T) {
+		t.Parallel()
+
+		const burst = 10
+		const callers = 100
+
+		l := newLimiter(1, burst, NewFakeClock(time.Unix(0, 0)))
+
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		var granted int
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Allow() {
+					lock.Lock()
+					granted++
+					lock.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if got, want := granted, burst; got != want {
+			t.Errorf("expected %d tokens to be granted, got %d", want, got)
+		}
+
+		// Waiters must not be granted tokens either, since the clock does not
+		// move.
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, callers)
+		for i := 0; i < callers; i++ {
+			go func() {
+				errCh <- l.Wait(ctx)
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		for i := 0; i < callers; i++ {
+			select {
+			case err := <-errCh:
+				if err == nil {
+					t.Error("expected no token to be granted while waiting")
+				}
+			case <-time.After(promptly):
+				t.Fatal("timeout waiting for canceled waiters")
+			}
+		}
+	})
+
+	t.Run("cancel_while_waiting", func(t *testing.T) {
+		t.Parallel()
+
+		l := newLimiter(1, 1, NewFakeClock(time.Unix(0, 0)))
+		if !l.Allow() {
+			t.Fatal("expected a full limiter to grant a token")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- l.Wait(ctx)
+		}()
+
+		select {
+		case err := <-errCh:
+			t.Fatalf("expected Wait to block, got %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+		cancel()
+
+		select {
+		case err := <-errCh:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("expected %v to be %v", err, context.Canceled)
+			}
+		case <-time.After(promptly):
+			t.Fatal("expected Wait to return promptly after cancellation")
+		}
+	})
+
+	t.Run("refill_rate", func(t *testing.T) {
+		t.Parallel()
+
+		clock := NewFakeClock(time.Unix(0, 0))
+		l := newLimiter(10, 1, clock)
+		if !l.Allow() {
+			t.Fatal("expected a full limiter to grant a token")
+		}
+		if l.Allow() {
+			t.Fatal("expected an empty limiter to reject")
+		}
+
+		// A token is added every 100ms.
+		clock.Advance(50 * time.Millisecond)
+		if l.Allow() {
+			t.Error("expected no token after half the refill interval")
+		}
+		clock.Advance(50 * time.Millisecond)
+		if !l.Allow() {
+			t.Error("expected a token after the refill interval")
+		}
+
+		// Refills never exceed the burst.
+		clock.Advance(10 * time.Second)
+		if !l.Allow() {
+			t.Error("expected a token after a long idle period")
+		}
+		if l.Allow() {
+			t.Error("expected refills to be capped at the burst")
+		}
+
+		// Waiters are woken by the refill.
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- l.Wait(context.Background())
+		}()
+
+		select {
+		case err := <-errCh:
+			t.Fatalf("expected Wait to block, got %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(100 * time.Millisecond)
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Errorf("expected a token after the refill interval, got %v", err)
+			}
+		case <-time.After(promptly):
+			t.Fatal("expected Wait to return after the refill interval")
+		}
+	})
+
+	t.Run("zero_burst", func(t *testing.T) {
+		t.Parallel()
+
+		l := newLimiter(10, 0, NewFakeClock(time.Unix(0, 0)))
+		if l.Allow() {
+			t.Error("expected a zero-burst limiter to reject")
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- l.Wait(context.Background())
+		}()
+
+		select {
+		case err := <-errCh:
+			if !errors.Is(err, retry.ErrRateLimited) {
+				t.Errorf("expected %v to be %v", err, retry.ErrRateLimited)
+			}
+		case <-time.After(promptly):
+			t.Fatal("expected a zero-burst limiter to reject immediately")
+		}
+	})
+}
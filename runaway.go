@@ -0,0 +1,23 @@
+package retry
+
+import "errors"
+
+// ErrRunaway is wrapped around the error returned by [Do] and [DoValue] when
+// retrying stopped because the limit set with [MaxLoopIterations] was reached.
+var ErrRunaway = errors.New("retry: runaway retry loop")
+
+// MaxLoopIterations stops retrying after n attempts, returning an error that
+// wraps [ErrRunaway] and the error of the final attempt, with the stop reason
+// [ReasonRunaway]. It is a circuit breaker against programming mistakes, such
+// as a backoff that never stops combined with a context without a deadline,
+// rather than a retry policy: limit retries with [WithMaxRetries] instead, and
+// set n well above any limit the backoff is expected to reach, such as 1<<20.
+//
+// The limit is checked in constant time per attempt. The backoff is not
+// consulted for the final attempt. A value of 0, the default, means there is no
+// limit.
+func MaxLoopIterations(n uint64) DoOption {
+	return func(c *doConfig) {
+		c.maxIterations = n
+	}
+}
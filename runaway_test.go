@@ -0,0 +1,129 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestMaxLoopIterations(t *testing.T) {
+	t.Parallel()
+
+	errOops := errors.New("oops")
+
+	t.Run("runaway", func(t *testing.T) {
+		t.Parallel()
+
+		// A backoff that never stops, counting how often it is consulted.
+		var nexts int
+		b := retry.BackoffFunc(func() (time.Duration, bool) {
+			nexts++
+			return 1 * time.Second, false
+		})
+
+		var calls int
+		var outcomes []retry.Outcome
+		var retries []uint64
+		var reasons []retry.StopReason
+		var stopErr error
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(fmt.Errorf("attempt %d: %w", calls, errOops))
+		},
+			retry.MaxLoopIterations(5),
+			retry.WithClock(newFakeClock()),
+			retry.WithOutcomeObserver(func(o retry.Outcome) {
+				outcomes = append(outcomes, o)
+			}),
+			retry.WithHooks(retry.Hooks{
+				OnRetry: func(attempt uint64, _ time.Duration, _ error) {
+					retries = append(retries, attempt)
+				},
+			}),
+			retry.WithStopHook(func(reason retry.StopReason, err error) {
+				reasons = append(reasons, reason)
+				stopErr = err
+			}),
+		)
+
+		if !errors.Is(err, retry.ErrRunaway) {
+			t.Errorf("expected %v to be %v", err, retry.ErrRunaway)
+		}
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
+		if got, want := err.Error(), "retry: runaway retry loop: attempt 5: oops"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := calls, 5; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The backoff is not consulted for the final attempt, and neither is the
+		// retry hook called.
+		if got, want := nexts, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(retries), "[1 2 3 4]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// Every attempt is observed, and the final one has no delay.
+		if got, want := len(outcomes), 5; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+		if got, want := outcomes[4].Delay, time.Duration(0); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		if got, want := fmt.Sprint(reasons), "[runaway]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := stopErr, err; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("backoff_stops_first", func(t *testing.T) {
+		t.Parallel()
+
+		var reason retry.StopReason
+		err := retry.Do(context.Background(), retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		}, retry.MaxLoopIterations(5), retry.WithClock(newFakeClock()), retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+		if errors.Is(err, retry.ErrRunaway) {
+			t.Errorf("expected %v not to be %v", err, retry.ErrRunaway)
+		}
+		if got, want := reason, retry.ReasonStopped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exhaustion_detail", func(t *testing.T) {
+		t.Parallel()
+
+		err := retry.Do(context.Background(), retry.NewConstant(1*time.Second), func(_ context.Context) error {
+			return retry.RetryableError(errOops)
+		}, retry.MaxLoopIterations(3), retry.WithExhaustionDetail(), retry.WithClock(newFakeClock()))
+
+		var eerr *retry.ExhaustedError
+		if !errors.As(err, &eerr) {
+			t.Fatalf("expected %v to be an ExhaustedError", err)
+		}
+		if got, want := eerr.Reason, retry.ReasonRunaway; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := eerr.Attempts, uint64(3); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if !errors.Is(err, retry.ErrRunaway) {
+			t.Errorf("expected %v to be %v", err, retry.ErrRunaway)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrScheduleStopped is returned by [SharedSchedule.Wait] once the backoff of
+// the schedule has stopped.
+var ErrScheduleStopped = errors.New("retry: shared schedule stopped")
+
+// ErrProbeTimeout is the outcome recorded for a probe of a [SharedSchedule]
+// that was not reported within the probe timeout, such as because the caller
+// performing it gave up or panicked.
+var ErrProbeTimeout = errors.New("retry: shared schedule probe timed out")
+
+// defaultProbeTimeout is the probe timeout of a SharedSchedule unless
+// WithProbeTimeout is given.
+const defaultProbeTimeout = 1 * time.Minute
+
+// SharedSchedule shares a single retry schedule among many callers of a scarce
+// resource, such as every connection of a pool reconnecting to the same
+// server. Rather than each caller retrying on its own, callers wait for the
+// next probe, exactly one of them performs it, and its outcome decides whether
+// everyone proceeds or waits for the following probe.
+//
+// It is safe for concurrent use.
+type SharedSchedule struct {
+	b            Backoff
+	probeTimeout time.Duration
+
+	lock      sync.Mutex
+	next      time.Time
+	probing   bool
+	deadline  time.Time
+	successes uint64
+	err       error
+
+	// changed is closed and replaced whenever a probe is reported.
+	changed chan struct{}
+}
+
+// SharedScheduleOption is an option that configures a [SharedSchedule].
+type SharedScheduleOption func(s *SharedSchedule)
+
+// WithProbeTimeout sets how long the caller designated to probe has to report
+// the outcome with [SharedSchedule.Report]. Once it elapses, the probe fails
+// with [ErrProbeTimeout], so the schedule continues without it, and a report
+// made afterwards is attributed to the following probe, if one is in flight.
+// The default is one minute.
+func WithProbeTimeout(d time.Duration) SharedScheduleOption {
+	return func(s *SharedSchedule) {
+		s.probeTimeout = d
+	}
+}
+
+// NewSharedSchedule creates a new shared schedule that spaces probes according
+// to b. The first probe is made immediately. After a failed probe, the next one
+// is made after the delay from b; after a successful probe, b is reset if it
+// has a Reset method, and the schedule starts over.
+//
+// It panics if b is nil or the probe timeout is not positive; see
+// [NewSharedScheduleE].
+func NewSharedSchedule(b Backoff, opts ...SharedScheduleOption) *SharedSchedule {
+	return must(NewSharedScheduleE(b, opts...))
+}
+
+// NewSharedScheduleE is like [NewSharedSchedule], but returns an error instead
+// of panicking if the arguments are invalid.
+func NewSharedScheduleE(b Backoff, opts ...SharedScheduleOption) (*SharedSchedule, error) {
+	if b == nil {
+		return nil, &ValidationError{Field: "b", Reason: "must not be nil"}
+	}
+
+	s := &SharedSchedule{
+		b:            b,
+		probeTimeout: defaultProbeTimeout,
+		changed:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := validatePositive("probeTimeout", s.probeTimeout); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Wait blocks until the next probe. Exactly one caller per probe gets probe set
+// to true, and must perform the probe and pass its outcome to
+// [SharedSchedule.Report]. The other callers keep waiting until a probe
+// succeeds, and then return with probe set to false.
+//
+// If the probe is not reported within the probe timeout, set with
+// [WithProbeTimeout], it fails with [ErrProbeTimeout], and the next probe is
+// handed to another waiting caller.
+//
+// Wait returns an error wrapping [ErrScheduleStopped] and the error of the
+// last probe once the backoff has stopped, and the context's error if ctx is
+// done first.
+func (s *SharedSchedule) Wait(ctx context.Context) (probe bool, err error) {
+	s.lock.Lock()
+	successes := s.successes
+
+	for {
+		if s.probing && !time.Now().Before(s.deadline) {
+			s.report(ErrProbeTimeout)
+		}
+		if s.err != nil {
+			s.lock.Unlock()
+			return false, s.err
+		}
+		if s.successes != successes {
+			s.lock.Unlock()
+			return false, nil
+		}
+
+		// While a probe is in flight, wait for its outcome or its deadline.
+		// Otherwise, wait for the time of the next probe.
+		until := s.deadline
+		if !s.probing {
+			if !time.Now().Before(s.next) {
+				s.probing = true
+				s.deadline = time.Now().Add(s.probeTimeout)
+				s.lock.Unlock()
+				return true, nil
+			}
+			until = s.next
+		}
+		changed := s.changed
+		s.lock.Unlock()
+
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, contextError(ctx)
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+
+		s.lock.Lock()
+	}
+}
+
+// Report records the outcome of the probe the caller was designated to perform
+// by [SharedSchedule.Wait]. A nil error releases every waiting caller and
+// resets the schedule; any other error schedules the next probe.
+func (s *SharedSchedule) Report(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.probing {
+		return
+	}
+	s.report(err)
+}
+
+// report records the outcome of the probe in flight. The caller must hold the
+// lock.
+func (s *SharedSchedule) report(err error) {
+	s.probing = false
+
+	if err == nil {
+		s.successes++
+		s.next = time.Time{}
+		if r, ok := s.b.(interface{ Reset() }); ok {
+			r.Reset()
+		}
+	} else if d, stop := s.b.Next(); stop {
+		s.err = fmt.Errorf("%w: %w", ErrScheduleStopped, err)
+	} else {
+		s.next = time.Now().Add(d)
+	}
+
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
@@ -0,0 +1,222 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestSharedSchedule(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("down")
+
+	t.Run("one_prober_per_cycle", func(t *testing.T) {
+		t.Parallel()
+
+		const waiters = 20
+		const failures = 3
+		base := 5 * time.Millisecond
+
+		s := retry.NewSharedSchedule(retry.NewExponential(base))
+
+		var lock sync.Mutex
+		var probes []time.Time
+		var inFlight atomic.Int64
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		errCh := make(chan error, waiters)
+		var wg sync.WaitGroup
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					probe, err := s.Wait(ctx)
+					if err != nil {
+						errCh <- err
+						return
+					}
+					if !probe {
+						return
+					}
+
+					if n := inFlight.Add(1); n != 1 {
+						errCh <- errors.New("concurrent probes")
+					}
+					lock.Lock()
+					probes = append(probes, time.Now())
+					n := len(probes)
+					lock.Unlock()
+					time.Sleep(1 * time.Millisecond)
+					inFlight.Add(-1)
+
+					if n <= failures {
+						s.Report(errDown)
+						continue
+					}
+					s.Report(nil)
+					return
+				}
+			}()
+		}
+		wg.Wait()
+		close(errCh)
+
+		for err := range errCh {
+			t.Error(err)
+		}
+		if got, want := len(probes), failures+1; got != want {
+			t.Fatalf("expected %v to be %v", got, want)
+		}
+
+		// Each failed probe pushes the next one back by the next delay.
+		want := base
+		for i := 1; i < len(probes); i++ {
+			if got := probes[i].Sub(probes[i-1]); got < want {
+				t.Errorf("probe %d: expected %v to be at least %v", i, got, want)
+			}
+			want *= 2
+		}
+	})
+
+	t.Run("resets_after_success", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewSharedSchedule(retry.NewConstant(1 * time.Hour))
+		ctx := context.Background()
+
+		for i := 0; i < 3; i++ {
+			probe, err := s.Wait(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !probe {
+				t.Fatalf("%d: expected an immediate probe", i)
+			}
+			s.Report(nil)
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewSharedSchedule(retry.WithMaxRetries(1, retry.NewConstant(1*time.Millisecond)))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		for i := 0; i < 2; i++ {
+			probe, err := s.Wait(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !probe {
+				t.Fatalf("%d: expected a probe", i)
+			}
+			s.Report(errDown)
+		}
+
+		_, err := s.Wait(ctx)
+		if !errors.Is(err, retry.ErrScheduleStopped) {
+			t.Errorf("expected %v to be %v", err, retry.ErrScheduleStopped)
+		}
+		if !errors.Is(err, errDown) {
+			t.Errorf("expected %v to be %v", err, errDown)
+		}
+	})
+
+	t.Run("abandoned_probe", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewSharedSchedule(retry.NewConstant(1*time.Millisecond), retry.WithProbeTimeout(10*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		// The first prober never reports, so the probe is handed over.
+		if probe, _ := s.Wait(ctx); !probe {
+			t.Fatal("expected a probe")
+		}
+		start := time.Now()
+		probe, err := s.Wait(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !probe {
+			t.Fatal("expected the probe to be handed over")
+		}
+		if got, want := time.Since(start), 10*time.Millisecond; got < want {
+			t.Errorf("expected %v to be at least %v", got, want)
+		}
+		s.Report(nil)
+	})
+
+	t.Run("abandoned_probe_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewSharedSchedule(retry.WithMaxRetries(0, retry.NewConstant(1*time.Millisecond)), retry.WithProbeTimeout(1*time.Millisecond))
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if probe, _ := s.Wait(ctx); !probe {
+			t.Fatal("expected a probe")
+		}
+		_, err := s.Wait(ctx)
+		if !errors.Is(err, retry.ErrScheduleStopped) || !errors.Is(err, retry.ErrProbeTimeout) {
+			t.Errorf("expected %v to be %v and %v", err, retry.ErrScheduleStopped, retry.ErrProbeTimeout)
+		}
+	})
+
+	t.Run("context_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		s := retry.NewSharedSchedule(retry.NewConstant(1 * time.Hour))
+		if probe, _ := s.Wait(context.Background()); !probe {
+			t.Fatal("expected a probe")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := s.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestNewSharedScheduleE(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name  string
+		b     retry.Backoff
+		opts  []retry.SharedScheduleOption
+		field string
+	}{
+		{"nil", nil, nil, "b"},
+		{"probe_timeout_zero", retry.NewConstant(1 * time.Second), []retry.SharedScheduleOption{retry.WithProbeTimeout(0)}, "probeTimeout"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := retry.NewSharedScheduleE(tc.b, tc.opts...)
+			var verr *retry.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected %v to be a ValidationError", err)
+			}
+			if got, want := verr.Field, tc.field; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package retry
+
+import (
+	"context"
+	"sync"
+)
+
+// ShutdownCoordinator coordinates graceful shutdown of retry loops. Loops
+// registered with [WithShutdown] stop sleeping when [ShutdownCoordinator.Shutdown]
+// is called, instead of delaying shutdown until their backoff elapses.
+//
+// Unlike canceling the context, shutdown does not interrupt an attempt that is
+// in progress. Each loop finishes its current attempt, skips any pending sleep,
+// makes at most one final immediate attempt, and returns.
+//
+// It is safe for concurrent use.
+type ShutdownCoordinator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock         sync.Mutex
+	active       int
+	noFinal      bool
+	drained      chan struct{}
+	drainedOnce  sync.Once
+	shuttingDown bool
+}
+
+// NewShutdownCoordinator creates a new shutdown coordinator. By default, loops
+// make one final immediate attempt after their sleep is skipped.
+func NewShutdownCoordinator() *ShutdownCoordinator {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ShutdownCoordinator{
+		ctx:     ctx,
+		cancel:  cancel,
+		drained: make(chan struct{}),
+	}
+}
+
+// SetFinalAttempt configures whether loops make one final immediate attempt
+// when their sleep is skipped by shutdown. If disabled, loops return the error
+// from their most recent attempt instead.
+func (c *ShutdownCoordinator) SetFinalAttempt(enabled bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.noFinal = !enabled
+}
+
+// Shutdown signals all registered loops to stop retrying and waits for them to
+// return. It returns the context's error if ctx is done before all loops have
+// returned. Loops started after Shutdown make a single attempt.
+func (c *ShutdownCoordinator) Shutdown(ctx context.Context) error {
+	c.lock.Lock()
+	c.shuttingDown = true
+	c.cancel()
+	if c.active == 0 {
+		c.drainedOnce.Do(func() { close(c.drained) })
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-c.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// register records a new active loop. The returned function must be called
+// when the loop returns.
+func (c *ShutdownCoordinator) register() func() {
+	c.lock.Lock()
+	c.active++
+	c.lock.Unlock()
+
+	return func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		c.active--
+		if c.active == 0 && c.shuttingDown {
+			c.drainedOnce.Do(func() { close(c.drained) })
+		}
+	}
+}
+
+// isShutdown reports whether Shutdown has been called.
+func (c *ShutdownCoordinator) isShutdown() bool {
+	return c.ctx.Err() != nil
+}
+
+// finalAttempt reports whether loops make a final attempt on shutdown.
+func (c *ShutdownCoordinator) finalAttempt() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return !c.noFinal
+}
+
+// sleepContext returns a context derived from ctx that is also canceled when
+// Shutdown is called.
+func (c *ShutdownCoordinator) sleepContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// WithShutdown registers [Do] and [DoValue] with the shutdown coordinator c.
+func WithShutdown(c *ShutdownCoordinator) DoOption {
+	return func(cfg *doConfig) {
+		cfg.shutdown = c
+	}
+}
@@ -0,0 +1,253 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestShutdownCoordinator(t *testing.T) {
+	t.Parallel()
+
+	// startLoops starts n retry loops that fail and sleep for an hour, and waits
+	// until each has made its first attempt.
+	startLoops := func(tb testing.TB, c *retry.ShutdownCoordinator, n int) (*sync.WaitGroup, []*atomic.Int64, []error) {
+		tb.Helper()
+
+		var wg sync.WaitGroup
+		var started sync.WaitGroup
+		attempts := make([]*atomic.Int64, n)
+		errs := make([]error, n)
+		for i := 0; i < n; i++ {
+			i := i
+			attempts[i] = new(atomic.Int64)
+
+			wg.Add(1)
+			started.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var once sync.Once
+				errs[i] = retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+					attempts[i].Add(1)
+					once.Do(started.Done)
+					return retry.RetryableError(io.EOF)
+				}, retry.WithShutdown(c))
+			}()
+		}
+		started.Wait()
+		return &wg, attempts, errs
+	}
+
+	t.Run("final_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+		wg, attempts, errs := startLoops(t, c, 10)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		if err := c.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+
+		if got, max := time.Since(start), 1*time.Second; got > max {
+			t.Errorf("expected shutdown in %v to be less than %v", got, max)
+		}
+		for i := range attempts {
+			if got, want := attempts[i].Load(), int64(2); got != want {
+				t.Errorf("%d: expected %v attempts to be %v", i, got, want)
+			}
+			if got, want := errs[i], io.EOF; !errors.Is(got, want) {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("no_final_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+		c.SetFinalAttempt(false)
+		wg, attempts, errs := startLoops(t, c, 10)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := c.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+
+		for i := range attempts {
+			if got, want := attempts[i].Load(), int64(1); got != want {
+				t.Errorf("%d: expected %v attempts to be %v", i, got, want)
+			}
+			if got, want := errs[i], io.EOF; !errors.Is(got, want) {
+				t.Errorf("%d: expected %v to be %v", i, got, want)
+			}
+		}
+	})
+
+	t.Run("final_attempt_succeeds", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+
+		started := make(chan struct{})
+		errCh := make(chan error, 1)
+		go func() {
+			var i int
+			errCh <- retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+				i++
+				if i == 1 {
+					close(started)
+					return retry.RetryableError(io.EOF)
+				}
+				return nil
+			}, retry.WithShutdown(c))
+		}()
+		<-started
+
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("in_flight_attempt_not_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+
+		inAttempt := make(chan struct{})
+		release := make(chan struct{})
+		var attemptErr atomic.Value
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(ctx context.Context) error {
+				close(inAttempt)
+				<-release
+				if err := ctx.Err(); err != nil {
+					attemptErr.Store(err)
+				}
+				return nil
+			}, retry.WithShutdown(c))
+		}()
+		<-inAttempt
+
+		shutdownCh := make(chan error, 1)
+		go func() {
+			shutdownCh <- c.Shutdown(context.Background())
+		}()
+
+		// Shutdown waits for the in-flight attempt.
+		select {
+		case err := <-shutdownCh:
+			t.Fatalf("expected shutdown to wait, got %v", err)
+		case <-time.After(5 * time.Millisecond):
+		}
+
+		close(release)
+		if err := <-shutdownCh; err != nil {
+			t.Fatal(err)
+		}
+		if err := <-errCh; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if v := attemptErr.Load(); v != nil {
+			t.Errorf("expected attempt context to not be canceled, got %v", v)
+		}
+	})
+
+	t.Run("shutdown_timeout", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+
+		inAttempt := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		go func() {
+			_ = retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(ctx context.Context) error {
+				close(inAttempt)
+				<-release
+				return nil
+			}, retry.WithShutdown(c))
+		}()
+		<-inAttempt
+
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+		defer cancel()
+
+		if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected %v to be %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("after_shutdown", func(t *testing.T) {
+		t.Parallel()
+
+		c := retry.NewShutdownCoordinator()
+		if err := c.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		var i int
+		var reason retry.StopReason
+		if err := retry.Do(context.Background(), retry.NewConstant(1*time.Hour), func(_ context.Context) error {
+			i++
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithShutdown(c), retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		})); err == nil {
+			t.Fatal("expected err")
+		}
+
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := reason, retry.ReasonShutdown; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func ExampleWithShutdown() {
+	c := retry.NewShutdownCoordinator()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ctx := context.Background()
+		b := retry.NewExponential(1 * time.Second)
+		if err := retry.Do(ctx, b, func(_ context.Context) error {
+			// TODO: logic here
+			return nil
+		}, retry.WithShutdown(c)); err != nil {
+			// handle error
+		}
+	}()
+
+	// On shutdown, stop sleeping retry loops and wait for them to return.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		// handle error
+	}
+	wg.Wait()
+}
@@ -0,0 +1,241 @@
+package retry_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// modelRange is the set of delays the reference model allows for one step of a
+// backoff chain, or a stop.
+type modelRange struct {
+	lo, hi time.Duration
+	stop   bool
+}
+
+// The functions below are the reference model of the delay pipeline. Each one
+// mirrors a wrapper in plain arithmetic on ranges, favoring obviousness over
+// tightness.
+
+func modelCap(c time.Duration, r modelRange) modelRange {
+	switch {
+	case r.stop:
+		return r
+	case r.hi <= 0 || r.lo > c:
+		// Every value is replaced by the cap.
+		return modelRange{lo: c, hi: c}
+	case r.lo <= 0:
+		// Non-positive values become the cap, and the smallest positive value
+		// is a nanosecond.
+		return modelRange{lo: min(c, 1), hi: c}
+	default:
+		return modelRange{lo: r.lo, hi: min(r.hi, c)}
+	}
+}
+
+func modelJitter(j time.Duration, r modelRange) modelRange {
+	if r.stop {
+		return r
+	}
+	return modelRange{lo: max(r.lo-j, 0), hi: max(r.hi+j, 0)}
+}
+
+func modelJitterPercent(p uint64, r modelRange) modelRange {
+	if r.stop {
+		return r
+	}
+	// Allow a nanosecond either way for floating point rounding.
+	lo := time.Duration(float64(r.lo)*(1-float64(p)/100)) - 1
+	hi := time.Duration(float64(r.hi)*(1+float64(p)/100)) + 1
+	return modelRange{lo: max(lo, 0), hi: max(hi, 0)}
+}
+
+func modelMaxRetries(n uint64, step int, r modelRange) modelRange {
+	if r.stop || uint64(step) > n {
+		return modelRange{stop: true}
+	}
+	return r
+}
+
+func modelMaxDuration(timeout, elapsed time.Duration, r modelRange) modelRange {
+	remaining := timeout - elapsed
+	if r.stop || remaining <= 0 {
+		return modelRange{stop: true}
+	}
+	return modelCap(remaining, r)
+}
+
+// simLayer is one wrapper of a randomly composed chain, along with its model.
+type simLayer struct {
+	name  string
+	wrap  func(next retry.Backoff, clock *fakeClock) retry.Backoff
+	model func(step int, elapsed time.Duration, r modelRange) modelRange
+}
+
+// simChain is a randomly composed backoff chain and its reference model.
+type simChain struct {
+	desc  string
+	build func(clock *fakeClock) retry.Backoff
+	model func(step int, elapsed time.Duration) modelRange
+}
+
+func randomSimChain(rng *rand.Rand) simChain {
+	var parts []string
+	var base func() retry.Backoff
+	var baseModel func(step int) time.Duration
+
+	d := time.Duration(1+rng.Intn(1000)) * time.Millisecond
+	switch rng.Intn(3) {
+	case 0:
+		parts = append(parts, fmt.Sprintf("constant(%v)", d))
+		base = func() retry.Backoff { return retry.NewConstant(d) }
+		baseModel = func(int) time.Duration { return d }
+	case 1:
+		parts = append(parts, fmt.Sprintf("exponential(%v)", d))
+		base = func() retry.Backoff { return retry.NewExponential(d) }
+		baseModel = func(step int) time.Duration { return d << (step - 1) }
+	default:
+		parts = append(parts, fmt.Sprintf("fibonacci(%v)", d))
+		base = func() retry.Backoff { return retry.NewFibonacci(d) }
+		baseModel = func(step int) time.Duration {
+			a, b := d, 2*d
+			for i := 1; i < step; i++ {
+				a, b = b, a+b
+			}
+			return a
+		}
+	}
+
+	layers := make([]simLayer, rng.Intn(5))
+	for i := range layers {
+		switch rng.Intn(5) {
+		case 0:
+			c := time.Duration(1+rng.Intn(5000)) * time.Millisecond
+			layers[i] = simLayer{
+				name: fmt.Sprintf("cap(%v)", c),
+				wrap: func(next retry.Backoff, _ *fakeClock) retry.Backoff {
+					return retry.WithCappedDuration(c, next)
+				},
+				model: func(_ int, _ time.Duration, r modelRange) modelRange {
+					return modelCap(c, r)
+				},
+			}
+		case 1:
+			j := time.Duration(1+rng.Intn(1000)) * time.Millisecond
+			seed := rng.Int63()
+			layers[i] = simLayer{
+				name: fmt.Sprintf("jitter(%v)", j),
+				wrap: func(next retry.Backoff, _ *fakeClock) retry.Backoff {
+					return retry.WithJitter(j, next, retry.WithRandSeed(seed))
+				},
+				model: func(_ int, _ time.Duration, r modelRange) modelRange {
+					return modelJitter(j, r)
+				},
+			}
+		case 2:
+			p := uint64(1 + rng.Intn(100))
+			seed := rng.Int63()
+			layers[i] = simLayer{
+				name: fmt.Sprintf("jitter_percent(%d)", p),
+				wrap: func(next retry.Backoff, _ *fakeClock) retry.Backoff {
+					return retry.WithJitterPercent(p, next, retry.WithRandSeed(seed))
+				},
+				model: func(_ int, _ time.Duration, r modelRange) modelRange {
+					return modelJitterPercent(p, r)
+				},
+			}
+		case 3:
+			n := uint64(rng.Intn(20))
+			layers[i] = simLayer{
+				name: fmt.Sprintf("max_retries(%d)", n),
+				wrap: func(next retry.Backoff, _ *fakeClock) retry.Backoff {
+					return retry.WithMaxRetries(n, next)
+				},
+				model: func(step int, _ time.Duration, r modelRange) modelRange {
+					return modelMaxRetries(n, step, r)
+				},
+			}
+		default:
+			timeout := time.Duration(rng.Intn(60)) * time.Second
+			layers[i] = simLayer{
+				name: fmt.Sprintf("max_duration(%v)", timeout),
+				wrap: func(next retry.Backoff, clock *fakeClock) retry.Backoff {
+					return retry.WithMaxDuration(timeout, next, retry.WithNowFunc(clock.Now))
+				},
+				model: func(_ int, elapsed time.Duration, r modelRange) modelRange {
+					return modelMaxDuration(timeout, elapsed, r)
+				},
+			}
+		}
+		parts = append(parts, layers[i].name)
+	}
+
+	return simChain{
+		desc: strings.Join(parts, " > "),
+		build: func(clock *fakeClock) retry.Backoff {
+			b := base()
+			for _, l := range layers {
+				b = l.wrap(b, clock)
+			}
+			return b
+		},
+		model: func(step int, elapsed time.Duration) modelRange {
+			r := modelRange{lo: baseModel(step), hi: baseModel(step)}
+			for _, l := range layers {
+				r = l.model(step, elapsed, r)
+			}
+			return r
+		},
+	}
+}
+
+// TestSimulation composes random backoff chains and checks each step of the
+// real wrappers against the reference model, driving time with a fake clock
+// that advances by every returned delay.
+func TestSimulation(t *testing.T) {
+	t.Parallel()
+
+	const chains = 3000
+	const steps = 30
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < chains; i++ {
+		chain := randomSimChain(rng)
+
+		run := func() []time.Duration {
+			clock := newFakeClock()
+			start := clock.Now()
+			b := chain.build(clock)
+
+			var delays []time.Duration
+			for step := 1; step <= steps; step++ {
+				elapsed := clock.Now().Sub(start)
+				want := chain.model(step, elapsed)
+
+				val, stop := b.Next()
+				if stop != want.stop {
+					t.Fatalf("chain %d (%s): step %d: expected stop %t to be %t", i, chain.desc, step, stop, want.stop)
+				}
+				if stop {
+					break
+				}
+				if val < want.lo || val > want.hi {
+					t.Fatalf("chain %d (%s): step %d: expected %v to be in [%v, %v]", i, chain.desc, step, val, want.lo, want.hi)
+				}
+				delays = append(delays, val)
+				clock.Advance(val)
+			}
+			return delays
+		}
+
+		// The same seeds and clock reproduce the same delays.
+		first, second := run(), run()
+		if got, want := fmt.Sprint(second), fmt.Sprint(first); got != want {
+			t.Fatalf("chain %d (%s): expected %v to be %v", i, chain.desc, got, want)
+		}
+	}
+}
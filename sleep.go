@@ -0,0 +1,37 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// sleep waits for the duration d or until ctx is done, whichever happens first.
+// It returns the context's error if the context was canceled.
+//
+// Instead of selecting over the timer and context channels, the timer and the
+// context each signal a single wake channel via [time.AfterFunc] and
+// [context.AfterFunc]. BenchmarkSleep compares the two approaches.
+func sleep(ctx context.Context, d time.Duration) error {
+	// A context that can never be canceled needs no wake channel.
+	if ctx.Done() == nil {
+		time.Sleep(d)
+		return nil
+	}
+
+	wake := make(chan struct{})
+
+	t := time.AfterFunc(d, func() {
+		close(wake)
+	})
+	stop := context.AfterFunc(ctx, func() {
+		// Only close if the timer has not already fired, so the channel is closed
+		// exactly once.
+		if t.Stop() {
+			close(wake)
+		}
+	})
+
+	<-wake
+	stop()
+	return ctx.Err()
+}
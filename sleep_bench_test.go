@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// timerSleep is the sleep used before sleep switched to context.AfterFunc,
+// selecting over a timer channel and the context.
+func timerSleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	select {
+	case <-ctx.Done():
+		t.Stop()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// BenchmarkSleep compares sleep with the previous timer and select path, for
+// sleeps that expire and for sleeps canceled concurrently.
+func BenchmarkSleep(b *testing.B) {
+	impls := []struct {
+		name  string
+		sleep func(ctx context.Context, d time.Duration) error
+	}{
+		{"timer", timerSleep},
+		{"after_func", sleep},
+	}
+
+	for _, impl := range impls {
+		impl := impl
+
+		b.Run(impl.name+"/expire", func(b *testing.B) {
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				for pb.Next() {
+					_ = impl.sleep(ctx, 1*time.Nanosecond)
+				}
+			})
+		})
+
+		b.Run(impl.name+"/cancel", func(b *testing.B) {
+			b.ReportAllocs()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					ctx, cancel := context.WithCancel(context.Background())
+					go cancel()
+					_ = impl.sleep(ctx, 1*time.Hour)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	_ Backoff      = (*maxSleepBackoff)(nil)
+	_ StopReasoner = (*maxSleepBackoff)(nil)
+)
+
+// WithTruncateFinalSleep causes a backoff from [WithMaxSleep] to truncate a
+// delay that exceeds the remaining budget to exactly consume it, and stop on
+// the following call. By default, such a delay stops the backoff.
+func WithTruncateFinalSleep() BackoffOption {
+	return func(c *backoffConfig) {
+		c.truncate = true
+	}
+}
+
+// WithMaxSleep sets a maximum on the total of the delays returned from the
+// next backoff. Unlike [WithMaxDuration], it does not measure wall-clock time,
+// so time spent in the retried function, such as a slow RPC, does not consume
+// the budget. It stops once returning the next delay would exceed the budget,
+// unless [WithTruncateFinalSleep] is given.
+//
+// The returned backoff implements [StopReasoner], and reports
+// [ReasonMaxSleep] when the budget is exhausted, or
+// [ReasonBudgetTruncatedFinalSleep] after a truncated delay. It has a Reset
+// method, which restores the full budget, clears any stop, and resets next if
+// it has a Reset method.
+//
+// It panics if budget is negative or next is nil. It is safe for concurrent use
+// if next is safe for concurrent use.
+func WithMaxSleep(budget time.Duration, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithMaxSleepE(budget, next, opts...))
+}
+
+// WithMaxSleepE is like [WithMaxSleep], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithMaxSleepE(budget time.Duration, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if budget < 0 {
+		return nil, &ValidationError{Field: "budget", Reason: "must not be negative"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &maxSleepBackoff{
+		budget:   budget,
+		truncate: newBackoffConfig(opts).truncate,
+		next:     next,
+	}, nil
+}
+
+type maxSleepBackoff struct {
+	budget   time.Duration
+	truncate bool
+	next     Backoff
+
+	lock      sync.Mutex
+	slept     time.Duration
+	truncated bool
+	reason    StopReason
+}
+
+// Next implements Backoff.
+func (b *maxSleepBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.reason != ReasonNone {
+		return 0, true
+	}
+
+	val, stop := b.next.Next()
+	if stop {
+		b.reason = stopReasonOf(b.next)
+		return 0, true
+	}
+	val = max(val, 0)
+
+	remaining := b.budget - b.slept
+	switch {
+	case val <= remaining:
+		b.slept += val
+		return val, false
+	case b.truncated:
+		b.reason = ReasonBudgetTruncatedFinalSleep
+		return 0, true
+	case b.truncate && remaining > 0:
+		b.slept = b.budget
+		b.truncated = true
+		return remaining, false
+	default:
+		b.reason = ReasonMaxSleep
+		return 0, true
+	}
+}
+
+// Reset restores the full budget, clears any stop, and resets next, if it has
+// a Reset method.
+func (b *maxSleepBackoff) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.slept = 0
+	b.truncated = false
+	b.reason = ReasonNone
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+}
+
+// StopReason implements StopReasoner.
+func (b *maxSleepBackoff) StopReason() StopReason {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.reason
+}
+
+// MaxSleep returns the configured budget.
+func (b *maxSleepBackoff) MaxSleep() time.Duration {
+	return b.budget
+}
+
+// Unwrap implements Wrapper.
+func (b *maxSleepBackoff) Unwrap() Backoff {
+	return b.next
+}
@@ -0,0 +1,198 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithMaxSleep(t *testing.T) {
+	t.Parallel()
+
+	delays := func(b retry.Backoff) string {
+		var out []time.Duration
+		for i := 0; i < 20; i++ {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			out = append(out, val)
+		}
+		return fmt.Sprint(out)
+	}
+
+	cases := []struct {
+		name   string
+		b      func() retry.Backoff
+		exp    string
+		reason retry.StopReason
+	}{
+		{
+			name: "exact",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(6*time.Second, retry.NewExponential(1*time.Second))
+			},
+			exp:    "[1s 2s]",
+			reason: retry.ReasonMaxSleep,
+		},
+		{
+			name: "fills_budget",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(7*time.Second, retry.NewExponential(1*time.Second))
+			},
+			exp:    "[1s 2s 4s]",
+			reason: retry.ReasonMaxSleep,
+		},
+		{
+			name: "truncate",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(6*time.Second, retry.NewExponential(1*time.Second), retry.WithTruncateFinalSleep())
+			},
+			exp:    "[1s 2s 3s]",
+			reason: retry.ReasonBudgetTruncatedFinalSleep,
+		},
+		{
+			name: "zero",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(0, retry.NewConstant(1*time.Second), retry.WithTruncateFinalSleep())
+			},
+			exp:    "[]",
+			reason: retry.ReasonMaxSleep,
+		},
+		{
+			name: "next_stops",
+			b: func() retry.Backoff {
+				return retry.WithMaxSleep(time.Hour, retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)))
+			},
+			exp:    "[1s 1s]",
+			reason: retry.ReasonStopped,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := tc.b()
+			if got, want := delays(b), tc.exp; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+			if got, want := b.(retry.StopReasoner).StopReason(), tc.reason; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+
+			// Keeps stopping.
+			if _, stop := b.Next(); !stop {
+				t.Errorf("should stop")
+			}
+		})
+	}
+}
+
+func TestWithMaxSleep_reset(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithMaxSleep(3*time.Second, retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+		return time.Duration(retry) * time.Second, false
+	}))
+
+	var got []time.Duration
+	for round := 0; round < 2; round++ {
+		for {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			got = append(got, val)
+		}
+		b.(interface{ Reset() }).Reset()
+	}
+	if got, want := fmt.Sprint(got), "[1s 2s 1s 2s]"; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+// TestWithMaxSleep_slowFunction shows that time spent in the retried function
+// does not consume the budget of WithMaxSleep, unlike WithMaxDuration.
+func TestWithMaxSleep_slowFunction(t *testing.T) {
+	t.Parallel()
+
+	errSlow := errors.New("slow rpc failed")
+
+	run := func(newBackoff func(clock *fakeClock) retry.Backoff) int {
+		clock := newFakeClock()
+		var calls int
+		_ = retry.Do(context.Background(), newBackoff(clock), func(_ context.Context) error {
+			calls++
+			// Each attempt takes 2 minutes.
+			clock.Advance(2 * time.Minute)
+			return retry.RetryableError(errSlow)
+		}, retry.WithClock(clock))
+		return calls
+	}
+
+	sleep := run(func(*fakeClock) retry.Backoff {
+		return retry.WithMaxSleep(5*time.Second, retry.NewConstant(1*time.Second))
+	})
+	if got, want := sleep, 6; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	wall := run(func(clock *fakeClock) retry.Backoff {
+		return retry.WithMaxDuration(5*time.Second, retry.NewConstant(1*time.Second), retry.WithNowFunc(clock.Now))
+	})
+	if got, want := wall, 1; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func TestWithMaxSleep_concurrent(t *testing.T) {
+	t.Parallel()
+
+	b := retry.WithMaxSleep(1000*time.Millisecond, retry.NewConstant(1*time.Millisecond))
+
+	var lock sync.Mutex
+	var total time.Duration
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				val, stop := b.Next()
+				if stop {
+					return
+				}
+				lock.Lock()
+				total += val
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := total, 1000*time.Millisecond; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
+
+func ExampleWithMaxSleep() {
+	ctx := context.Background()
+
+	b := retry.NewExponential(1 * time.Second)
+	b = retry.WithMaxSleep(30*time.Second, b, retry.WithTruncateFinalSleep())
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}); err != nil {
+		// handle error
+	}
+}
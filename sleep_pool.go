@@ -0,0 +1,158 @@
+package retry
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Clock = (*SleepPool)(nil)
+
+// SleepPool is a [Clock] that parks sleeping retry loops in a heap of wake-up
+// times served by a single runtime timer, instead of creating a timer for each
+// sleep. The caller's goroutine still blocks, on a channel closed when its
+// wake-up time is reached, but the number of runtime timers stays constant no
+// matter how many loops are sleeping. Unlike [CoalescedTimers], sleeps are not
+// rounded.
+//
+// SleepPool is experimental and may change in future releases.
+//
+// The zero value is ready to use. It is safe for concurrent use.
+type SleepPool struct {
+	lock    sync.Mutex
+	waiters sleepHeap
+	timer   *time.Timer
+}
+
+// NewSleepPool creates a new, empty sleep pool.
+func NewSleepPool() *SleepPool {
+	return &SleepPool{}
+}
+
+// DoPooled wraps a function with a backoff to retry, like [Do], but sleeps
+// between attempts in pool, which is typically shared by every retry loop in a
+// server. See [SleepPool].
+//
+// DoPooled is experimental and may change in future releases.
+func DoPooled(ctx context.Context, pool *SleepPool, b Backoff, f RetryFunc, opts ...DoOption) error {
+	return Do(ctx, b, f, appendOptions(opts, WithClock(pool))...)
+}
+
+// Now implements Clock.
+func (p *SleepPool) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep implements Clock. If ctx is done first, the sleep is removed from the
+// pool.
+func (p *SleepPool) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	w := p.park(time.Now().Add(d))
+	select {
+	case <-w.ch:
+		return nil
+	case <-ctx.Done():
+		p.unpark(w)
+		return ctx.Err()
+	}
+}
+
+// Waiting returns the number of sleeps currently parked in the pool.
+func (p *SleepPool) Waiting() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return len(p.waiters)
+}
+
+// park adds a sleep until the given time, arming the timer if it is the
+// earliest.
+func (p *SleepPool) park(until time.Time) *sleepWaiter {
+	w := &sleepWaiter{until: until, ch: make(chan struct{})}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	heap.Push(&p.waiters, w)
+	if w.index == 0 {
+		p.arm(time.Until(until))
+	}
+	return w
+}
+
+// unpark removes w from the heap if it has not been woken yet.
+func (p *SleepPool) unpark(w *sleepWaiter) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if w.index >= 0 {
+		heap.Remove(&p.waiters, w.index)
+	}
+}
+
+// arm schedules the timer to fire after d. The caller must hold the lock.
+func (p *SleepPool) arm(d time.Duration) {
+	if p.timer == nil {
+		p.timer = time.AfterFunc(d, p.fire)
+		return
+	}
+	p.timer.Reset(d)
+}
+
+// fire wakes every sleep whose time has come and re-arms the timer for the
+// earliest remaining one.
+func (p *SleepPool) fire() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	for len(p.waiters) > 0 && !p.waiters[0].until.After(now) {
+		w := heap.Pop(&p.waiters).(*sleepWaiter)
+		close(w.ch)
+	}
+	if len(p.waiters) > 0 {
+		p.arm(p.waiters[0].until.Sub(now))
+	}
+}
+
+// sleepWaiter is a sleep parked in a SleepPool.
+type sleepWaiter struct {
+	until time.Time
+	ch    chan struct{}
+
+	// index is the position in the heap, or -1 once removed.
+	index int
+}
+
+// sleepHeap is a min-heap of sleeps by wake-up time, implementing
+// heap.Interface.
+type sleepHeap []*sleepWaiter
+
+func (h sleepHeap) Len() int           { return len(h) }
+func (h sleepHeap) Less(i, j int) bool { return h[i].until.Before(h[j].until) }
+
+func (h sleepHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *sleepHeap) Push(x any) {
+	w := x.(*sleepWaiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *sleepHeap) Pop() any {
+	old := *h
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*h = old[:n-1]
+	return w
+}
@@ -0,0 +1,73 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// BenchmarkSleepPool runs 200k concurrent retry loops that each sleep once,
+// comparing a timer per sleep with a shared SleepPool. It reports the number of
+// runtime timers created and the heap in use while the loops are sleeping.
+func BenchmarkSleepPool(b *testing.B) {
+	const loops = 200_000
+	const maxDelay = 200 * time.Millisecond
+
+	errRetry := RetryableError(errors.New("retry"))
+
+	run := func(b *testing.B, timers int64, do func(ctx context.Context, bo Backoff, f RetryFunc) error) {
+		b.ReportAllocs()
+
+		var heapInUse uint64
+		for i := 0; i < b.N; i++ {
+			var wg sync.WaitGroup
+			var started sync.WaitGroup
+			started.Add(loops)
+			for j := 0; j < loops; j++ {
+				delay := time.Duration(j)*maxDelay/loops + maxDelay
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					var calls int
+					_ = do(context.Background(), WithMaxRetries(1, NewConstant(delay)), func(_ context.Context) error {
+						calls++
+						if calls == 1 {
+							started.Done()
+							return errRetry
+						}
+						return nil
+					})
+				}()
+			}
+
+			// Measure while every loop is sleeping.
+			started.Wait()
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			heapInUse += m.HeapInuse
+
+			wg.Wait()
+		}
+
+		b.ReportMetric(float64(timers), "timers/op")
+		b.ReportMetric(float64(heapInUse)/float64(b.N), "heap-bytes/op")
+	}
+
+	b.Run("timer_per_sleep", func(b *testing.B) {
+		run(b, loops, func(ctx context.Context, bo Backoff, f RetryFunc) error {
+			return Do(ctx, bo, f)
+		})
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		pool := NewSleepPool()
+		run(b, 1, func(ctx context.Context, bo Backoff, f RetryFunc) error {
+			return DoPooled(ctx, pool, bo, f)
+		})
+	})
+}
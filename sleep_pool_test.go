@@ -0,0 +1,132 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestSleepPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("order", func(t *testing.T) {
+		t.Parallel()
+
+		pool := retry.NewSleepPool()
+		delays := []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond}
+
+		var lock sync.Mutex
+		var woke []time.Duration
+		var wg sync.WaitGroup
+		for _, d := range delays {
+			d := d
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				start := time.Now()
+				if err := pool.Sleep(context.Background(), d); err != nil {
+					t.Error(err)
+				}
+				if elapsed := time.Since(start); elapsed < d {
+					t.Errorf("expected %v to be at least %v", elapsed, d)
+				}
+
+				lock.Lock()
+				defer lock.Unlock()
+				woke = append(woke, d)
+			}()
+		}
+		wg.Wait()
+
+		for i := 1; i < len(woke); i++ {
+			if woke[i] < woke[i-1] {
+				t.Errorf("expected wake-ups in order, got %v", woke)
+			}
+		}
+		if got, want := pool.Waiting(), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		pool := retry.NewSleepPool()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error)
+		for i := 0; i < 3; i++ {
+			go func() {
+				done <- pool.Sleep(ctx, time.Hour)
+			}()
+		}
+		for pool.Waiting() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+
+		cancel()
+		for i := 0; i < 3; i++ {
+			if got, want := <-done, context.Canceled; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}
+		if got, want := pool.Waiting(), 0; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// A sleep after the canceled ones is still woken.
+		if err := pool.Sleep(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		t.Parallel()
+
+		var pool retry.SleepPool
+		if err := pool.Sleep(context.Background(), 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := pool.Sleep(context.Background(), time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDoPooled(t *testing.T) {
+	t.Parallel()
+
+	pool := retry.NewSleepPool()
+	errFail := errors.New("fail")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var calls int
+			err := retry.DoPooled(context.Background(), pool, retry.NewConstant(1*time.Millisecond), func(_ context.Context) error {
+				calls++
+				if calls < 3 {
+					return retry.RetryableError(errFail)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := pool.Waiting(), 0; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+}
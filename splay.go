@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// WithResplayOnReset causes a backoff from [WithStartupSplay] to add a new
+// splay to the first delay after it is reset. By default, the splay is only
+// added once per backoff.
+func WithResplayOnReset() BackoffOption {
+	return func(c *backoffConfig) {
+		c.resplay = true
+	}
+}
+
+// WithStartupSplay adds a random delay between 0 and max to the first delay of
+// next, and passes later delays through unchanged. This spreads out the first
+// retries of a fleet of instances that start failing at the same moment, such
+// as after a failover, independently of any per-step jitter.
+//
+// The returned backoff has a Reset method, which resets next if it has one,
+// and splays again only if [WithResplayOnReset] is given. To count the splay
+// against a [WithMaxDuration] budget, apply WithMaxDuration outside of it.
+//
+// It panics if max is less than or equal to zero or next is nil. It is safe
+// for concurrent use if next is safe for concurrent use.
+func WithStartupSplay(max time.Duration, next Backoff, opts ...BackoffOption) Backoff {
+	return must(WithStartupSplayE(max, next, opts...))
+}
+
+// WithStartupSplayE is like [WithStartupSplay], but returns an error instead of
+// panicking if the arguments are invalid.
+func WithStartupSplayE(max time.Duration, next Backoff, opts ...BackoffOption) (Backoff, error) {
+	if err := validatePositive("max", max); err != nil {
+		return nil, err
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	cfg := newBackoffConfig(opts)
+
+	b := &startupSplayBackoff{
+		max:     max,
+		next:    next,
+		resplay: cfg.resplay,
+		r:       cfg.random(),
+	}
+	b.armed.Store(true)
+	return b, nil
+}
+
+type startupSplayBackoff struct {
+	max     time.Duration
+	next    Backoff
+	resplay bool
+	r       *lockedSource
+
+	armed atomic.Bool
+}
+
+// Next implements Backoff.
+func (b *startupSplayBackoff) Next() (time.Duration, bool) {
+	val, stop := b.next.Next()
+	if stop {
+		return 0, true
+	}
+
+	if b.armed.CompareAndSwap(true, false) {
+		val = addDuration(val, time.Duration(b.r.Int63n(int64(b.max))))
+	}
+	return val, false
+}
+
+// Reset resets next, if it has a Reset method, and re-arms the splay if
+// configured with [WithResplayOnReset].
+func (b *startupSplayBackoff) Reset() {
+	if r, ok := b.next.(interface{ Reset() }); ok {
+		r.Reset()
+	}
+	if b.resplay {
+		b.armed.Store(true)
+	}
+}
+
+// Splay returns the configured maximum splay.
+func (b *startupSplayBackoff) Splay() time.Duration {
+	return b.max
+}
+
+// Unwrap implements Wrapper.
+func (b *startupSplayBackoff) Unwrap() Backoff {
+	return b.next
+}
@@ -0,0 +1,103 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithStartupSplay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first_only", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithStartupSplay(10*time.Second, retry.NewConstant(1*time.Second), retry.WithRandSeed(1))
+
+		first, _ := b.Next()
+		if first < 1*time.Second || first >= 11*time.Second {
+			t.Errorf("expected %v to be in [1s, 11s)", first)
+		}
+		if first == 1*time.Second {
+			t.Errorf("expected %v to be splayed", first)
+		}
+
+		for i := 0; i < 10; i++ {
+			if val, _ := b.Next(); val != 1*time.Second {
+				t.Errorf("%d: expected %v to be %v", i, val, 1*time.Second)
+			}
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		t.Parallel()
+
+		first := func() time.Duration {
+			b := retry.WithStartupSplay(10*time.Second, retry.NewConstant(1*time.Second), retry.WithRandSeed(42))
+			val, _ := b.Next()
+			return val
+		}
+		if got, want := first(), first(); got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		t.Parallel()
+
+		type resetter interface {
+			retry.Backoff
+			Reset()
+		}
+
+		newBackoff := func(opts ...retry.BackoffOption) resetter {
+			next := retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+				return time.Duration(retry) * time.Second, false
+			})
+			opts = append(opts, retry.WithRandSeed(1))
+			return retry.WithStartupSplay(10*time.Second, next, opts...).(resetter)
+		}
+
+		// Without resplay, the inner backoff is reset but the splay is not
+		// added again.
+		b := newBackoff()
+		splayed, _ := b.Next()
+		b.Next()
+		b.Reset()
+		if val, _ := b.Next(); val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+
+		// With resplay, the first delay after a reset is splayed again. The same
+		// seed draws the same first splay.
+		b = newBackoff(retry.WithResplayOnReset())
+		if val, _ := b.Next(); val != splayed {
+			t.Errorf("expected %v to be %v", val, splayed)
+		}
+		b.Reset()
+		if val, _ := b.Next(); val == 1*time.Second {
+			t.Errorf("expected %v to be splayed", val)
+		}
+		if val, _ := b.Next(); val != 2*time.Second {
+			t.Errorf("expected %v to be %v", val, 2*time.Second)
+		}
+	})
+
+	t.Run("max_duration_budget", func(t *testing.T) {
+		t.Parallel()
+
+		// The splay is truncated by the budget of an outer WithMaxDuration.
+		clock := newFakeClock()
+		b := retry.WithMaxDuration(2*time.Second, retry.WithStartupSplay(time.Hour, retry.NewConstant(1*time.Second), retry.WithRandSeed(1)), retry.WithNowFunc(clock.Now))
+
+		val, stop := b.Next()
+		if stop || val != 2*time.Second {
+			t.Errorf("expected (%v, false), got (%v, %v)", 2*time.Second, val, stop)
+		}
+		clock.Advance(val)
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop")
+		}
+	})
+}
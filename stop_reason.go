@@ -0,0 +1,141 @@
+package retry
+
+// StopReason describes why a backoff stopped.
+type StopReason int
+
+const (
+	// ReasonNone indicates the backoff has not stopped.
+	ReasonNone StopReason = iota
+
+	// ReasonStopped indicates the backoff stopped without reporting a more
+	// specific reason.
+	ReasonStopped
+
+	// ReasonMaxAttempts indicates the maximum number of local attempts was
+	// reached, for example due to [WithAmplificationGuard].
+	ReasonMaxAttempts
+
+	// ReasonMaxDuration indicates the total duration allowed by
+	// [WithMaxDuration] elapsed.
+	ReasonMaxDuration
+
+	// ReasonBudgetTruncatedFinalSleep indicates the total duration allowed by
+	// [WithMaxDuration], or the total delay allowed by [WithMaxSleep], was used
+	// up after the previous delay was truncated to fit the remaining budget.
+	// The final attempt ran with budget remaining, but there was no budget left
+	// to retry it.
+	ReasonBudgetTruncatedFinalSleep
+
+	// ReasonShutdown indicates retrying stopped because a
+	// [ShutdownCoordinator] was shut down.
+	ReasonShutdown
+
+	// ReasonRateLimited indicates retrying stopped because the [Limiter] set
+	// with [WithLimiter] will never grant another retry.
+	ReasonRateLimited
+
+	// ReasonGateClosed indicates retrying stopped because the gate set with
+	// [SetGlobalGate] was closed.
+	ReasonGateClosed
+
+	// ReasonNotLeader indicates retrying stopped because a backoff from
+	// [WithLeaderOnly] did not acquire its lock.
+	ReasonNotLeader
+
+	// ReasonLeaseExpiring indicates retrying stopped because the lease of a
+	// backoff from [WithLease] had too little time left.
+	ReasonLeaseExpiring
+
+	// ReasonMaxSleep indicates the next delay would have exceeded the total
+	// delay allowed by [WithMaxSleep].
+	ReasonMaxSleep
+
+	// ReasonRunaway indicates retrying stopped because the number of attempts
+	// reached the limit set with [MaxLoopIterations].
+	ReasonRunaway
+
+	// ReasonRetryBudget indicates retrying stopped because a backoff from
+	// [Budget.Wrap] found the shared retry budget empty.
+	ReasonRetryBudget
+
+	// ReasonDeadline indicates retrying stopped because the next delay would
+	// have ended after the context's deadline, with [WithDeadlineAware].
+	ReasonDeadline
+)
+
+// String returns the name of the reason.
+func (r StopReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonStopped:
+		return "stopped"
+	case ReasonMaxAttempts:
+		return "max_attempts"
+	case ReasonMaxDuration:
+		return "max_duration"
+	case ReasonBudgetTruncatedFinalSleep:
+		return "budget_truncated_final_sleep"
+	case ReasonShutdown:
+		return "shutdown"
+	case ReasonRateLimited:
+		return "rate_limited"
+	case ReasonGateClosed:
+		return "gate_closed"
+	case ReasonNotLeader:
+		return "not_leader"
+	case ReasonLeaseExpiring:
+		return "lease_expiring"
+	case ReasonMaxSleep:
+		return "max_sleep"
+	case ReasonRunaway:
+		return "runaway"
+	case ReasonRetryBudget:
+		return "retry_budget"
+	case ReasonDeadline:
+		return "deadline"
+	default:
+		return "unknown"
+	}
+}
+
+// stopReasonError returns the error wrapped around the final error when a
+// backoff stops for reason, or nil if the final error is returned as is.
+func stopReasonError(reason StopReason) error {
+	switch reason {
+	case ReasonNotLeader:
+		return ErrNotLeader
+	case ReasonLeaseExpiring:
+		return ErrLeaseExpiring
+	case ReasonDeadline:
+		return ErrDeadlineWouldExceed
+	default:
+		return nil
+	}
+}
+
+// StopReasoner is implemented by backoffs that report why they stopped. [Do]
+// reports the first reason found while walking the chain with [Walk], so the
+// reason of a StopReasoner is reported wherever it is in the chain, as long as
+// the middlewares around it implement [Wrapper].
+type StopReasoner interface {
+	// StopReason returns the reason the most recent call to Next stopped, or
+	// [ReasonNone] if it did not stop.
+	StopReason() StopReason
+}
+
+// stopReasonOf returns the reason the backoff b stopped, from the outermost
+// backoff in its chain that reports one.
+func stopReasonOf(b Backoff) StopReason {
+	reason := ReasonStopped
+	Walk(b, func(node Backoff) bool {
+		if sr, ok := node.(StopReasoner); ok {
+			if r := sr.StopReason(); r != ReasonNone {
+				reason = r
+				return false
+			}
+		}
+		return true
+	})
+	return reason
+}
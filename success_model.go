@@ -0,0 +1,183 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// minAdaptiveSamples is the number of outcomes that must be recorded for an
+// attempt number before [WithAdaptiveCutoff] trusts the model's estimate.
+const minAdaptiveSamples = 50
+
+// maxModelAttempts is the number of attempt numbers a [SuccessModel] tracks
+// separately. Later attempts share the last bucket, so the model stays bounded
+// when a retry loop runs for a very long time.
+const maxModelAttempts = 64
+
+// SuccessModel learns the probability that each attempt number succeeds, as an
+// exponentially weighted moving average of the observed outcomes. Feed it
+// outcomes by passing its Observe method to [WithOutcomeObserver], and use it
+// to stop retrying early with [WithAdaptiveCutoff]. Attempts after the 64th
+// are tracked together, as a single attempt number.
+//
+// A SuccessModel is safe for concurrent use and is typically shared by every
+// retry loop calling the same dependency.
+type SuccessModel struct {
+	decay float64
+
+	lock  sync.Mutex
+	stats []successStat
+}
+
+type successStat struct {
+	rate    float64
+	samples uint64
+}
+
+// NewSuccessModel creates a new success model. The decay is the weight given to
+// each new outcome, between 0 (exclusive) and 1 (inclusive); larger values
+// adapt faster but are noisier.
+//
+// It panics if decay is out of range; see [NewSuccessModelE].
+func NewSuccessModel(decay float64) *SuccessModel {
+	m, err := NewSuccessModelE(decay)
+	if err != nil {
+		panic(err.Error())
+	}
+	return m
+}
+
+// NewSuccessModelE is like [NewSuccessModel], but returns an error instead of
+// panicking if decay is out of range.
+func NewSuccessModelE(decay float64) (*SuccessModel, error) {
+	// The negated comparison also rejects NaN.
+	if !(decay > 0 && decay <= 1) {
+		return nil, &ValidationError{Field: "decay", Reason: "must be greater than 0 and at most 1"}
+	}
+	return &SuccessModel{decay: decay}, nil
+}
+
+// Observe records the outcome of an attempt. An attempt succeeded if its error
+// is nil.
+func (m *SuccessModel) Observe(o Outcome) {
+	if o.Attempt == 0 {
+		return
+	}
+
+	var outcome float64
+	if o.Err == nil {
+		outcome = 1
+	}
+
+	attempt := modelAttempt(o.Attempt)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for uint64(len(m.stats)) < attempt {
+		m.stats = append(m.stats, successStat{})
+	}
+
+	s := &m.stats[attempt-1]
+	if s.samples == 0 {
+		s.rate = outcome
+	} else {
+		s.rate = m.decay*outcome + (1-m.decay)*s.rate
+	}
+	s.samples++
+}
+
+// Estimate returns the estimated probability that attempt number attempt,
+// starting at 1, succeeds, and the number of outcomes recorded for it. Attempts
+// after the 64th share an estimate.
+func (m *SuccessModel) Estimate(attempt uint64) (prob float64, samples uint64) {
+	attempt = modelAttempt(attempt)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if attempt == 0 || attempt > uint64(len(m.stats)) {
+		return 0, 0
+	}
+	s := m.stats[attempt-1]
+	return s.rate, s.samples
+}
+
+// modelAttempt returns the attempt number under which attempt is tracked.
+func modelAttempt(attempt uint64) uint64 {
+	if attempt > maxModelAttempts {
+		return maxModelAttempts
+	}
+	return attempt
+}
+
+// WithAdaptiveCutoff stops retrying when the model estimates that the next
+// attempt succeeds with a probability below minProb. Until enough outcomes have
+// been recorded for the next attempt number, the decision is left to next.
+//
+// Each returned backoff counts its own attempts, so a new one must be built for
+// every retry loop, while the model is shared. It panics if m or next is nil,
+// or minProb is not between 0 and 1. It is safe for concurrent use if next is
+// safe for concurrent use.
+func WithAdaptiveCutoff(m *SuccessModel, minProb float64, next Backoff) Backoff {
+	return must(WithAdaptiveCutoffE(m, minProb, next))
+}
+
+// WithAdaptiveCutoffE is like [WithAdaptiveCutoff], but returns an error
+// instead of panicking if the arguments are invalid.
+func WithAdaptiveCutoffE(m *SuccessModel, minProb float64, next Backoff) (Backoff, error) {
+	if m == nil {
+		return nil, &ValidationError{Field: "m", Reason: "must not be nil"}
+	}
+	// The negated comparison also rejects NaN.
+	if !(minProb >= 0 && minProb <= 1) {
+		return nil, &ValidationError{Field: "minProb", Reason: "must be between 0 and 1"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &adaptiveCutoffBackoff{
+		m:       m,
+		minProb: minProb,
+		next:    next,
+		attempt: 1,
+	}, nil
+}
+
+type adaptiveCutoffBackoff struct {
+	m       *SuccessModel
+	minProb float64
+	next    Backoff
+
+	lock    sync.Mutex
+	attempt uint64
+	stopped bool
+}
+
+// Next implements Backoff.
+func (b *adaptiveCutoffBackoff) Next() (time.Duration, bool) {
+	b.lock.Lock()
+	if b.stopped {
+		b.lock.Unlock()
+		return 0, true
+	}
+	b.attempt++
+	n := b.attempt
+	b.lock.Unlock()
+
+	if prob, samples := b.m.Estimate(n); samples >= minAdaptiveSamples && prob < b.minProb {
+		// Later attempt numbers may have too few samples to stop on their own,
+		// so remember the decision.
+		b.lock.Lock()
+		b.stopped = true
+		b.lock.Unlock()
+		return 0, true
+	}
+	return b.next.Next()
+}
+
+// Unwrap implements Wrapper.
+func (b *adaptiveCutoffBackoff) Unwrap() Backoff {
+	return b.next
+}
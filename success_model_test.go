@@ -0,0 +1,137 @@
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestNewSuccessModelE(t *testing.T) {
+	t.Parallel()
+
+	for _, decay := range []float64{0, -1, 1.5} {
+		if _, err := retry.NewSuccessModelE(decay); err == nil {
+			t.Errorf("expected error for decay %v", decay)
+		}
+	}
+	if _, err := retry.NewSuccessModelE(1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSuccessModel(t *testing.T) {
+	t.Parallel()
+
+	m := retry.NewSuccessModel(0.5)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Observe(retry.Outcome{Attempt: 2, Err: fmt.Errorf("oops")})
+		}()
+	}
+	wg.Wait()
+
+	prob, samples := m.Estimate(2)
+	if got, want := samples, uint64(100); got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+	if got, want := prob, 0.0; got != want {
+		t.Errorf("expected %v to be %v", got, want)
+	}
+
+	m.Observe(retry.Outcome{Attempt: 2})
+	if prob, _ := m.Estimate(2); prob != 0.5 {
+		t.Errorf("expected %v to be %v", prob, 0.5)
+	}
+
+	if _, samples := m.Estimate(1); samples != 0 {
+		t.Errorf("expected %v to be %v", samples, 0)
+	}
+}
+
+func TestSuccessModel_longRunning(t *testing.T) {
+	t.Parallel()
+
+	m := retry.NewSuccessModel(0.5)
+
+	// Attempts after the 64th share a bucket, so the estimate of one is the
+	// estimate of all.
+	m.Observe(retry.Outcome{Attempt: 1 << 40})
+	m.Observe(retry.Outcome{Attempt: 100, Err: fmt.Errorf("oops")})
+
+	for _, attempt := range []uint64{64, 65, 1 << 40, 1<<64 - 1} {
+		prob, samples := m.Estimate(attempt)
+		if got, want := samples, uint64(2); got != want {
+			t.Errorf("attempt %d: expected %v to be %v", attempt, got, want)
+		}
+		if got, want := prob, 0.5; got != want {
+			t.Errorf("attempt %d: expected %v to be %v", attempt, got, want)
+		}
+	}
+	if _, samples := m.Estimate(63); samples != 0 {
+		t.Errorf("expected %v to be %v", samples, 0)
+	}
+}
+
+func TestWithAdaptiveCutoff(t *testing.T) {
+	t.Parallel()
+
+	m := retry.NewSuccessModel(0.1)
+	r := rand.New(rand.NewSource(1))
+
+	// Attempts up to 3 succeed half of the time; later attempts never succeed.
+	run := func() uint64 {
+		var attempts uint64
+		b := retry.WithAdaptiveCutoff(m, 0.05, retry.WithMaxRetries(9, retry.NewConstant(1*time.Nanosecond)))
+		_ = retry.Do(context.Background(), b, func(_ context.Context) error {
+			attempts++
+			if attempts <= 3 && r.Intn(2) == 0 {
+				return nil
+			}
+			return retry.RetryableError(fmt.Errorf("oops"))
+		}, retry.WithOutcomeObserver(m.Observe))
+		return attempts
+	}
+
+	for i := 0; i < 1000; i++ {
+		run()
+	}
+
+	var max uint64
+	for i := 0; i < 100; i++ {
+		if attempts := run(); attempts > max {
+			max = attempts
+		}
+	}
+	if got, want := max, uint64(3); got != want {
+		t.Errorf("expected cutoff %v to be %v", got, want)
+	}
+
+	if prob, samples := m.Estimate(4); samples < 50 || prob >= 0.05 {
+		t.Errorf("expected low estimate for attempt 4, got %v from %d samples", prob, samples)
+	}
+}
+
+func ExampleWithAdaptiveCutoff() {
+	// The model is shared by every retry loop calling the same dependency.
+	m := retry.NewSuccessModel(0.1)
+
+	ctx := context.Background()
+	b := retry.WithMaxRetries(5, retry.NewExponential(1*time.Second))
+	b = retry.WithAdaptiveCutoff(m, 0.05, b)
+
+	if err := retry.Do(ctx, b, func(_ context.Context) error {
+		// TODO: logic here
+		return nil
+	}, retry.WithOutcomeObserver(m.Observe)); err != nil {
+		// handle error
+	}
+}
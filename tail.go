@@ -0,0 +1,79 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// TailReader returns a reader that reads from r like tail -f, such as from a
+// file that a producer is still writing. When r returns [io.EOF], or no data
+// and no error, the reader waits according to b and reads again, and when r
+// returns data, b is reset if it has a Reset method, so the next wait starts
+// from the base delay. Read returns io.EOF once b stops, and the context's
+// error once ctx is done. Other errors from r are returned as is.
+//
+// Data is read directly into the caller's slice and never buffered. Of the
+// options, only [WithClock] applies, and sets the clock used to wait. The
+// reader is not safe for concurrent use.
+func TailReader(ctx context.Context, b Backoff, r io.Reader, opts ...DoOption) io.Reader {
+	return &tailReader{
+		ctx:   ctx,
+		b:     b,
+		r:     r,
+		clock: newDoConfig(opts).clock,
+	}
+}
+
+type tailReader struct {
+	ctx   context.Context
+	b     Backoff
+	r     io.Reader
+	clock Clock
+
+	// stopped is set once b stops, after which Read keeps returning io.EOF.
+	stopped bool
+}
+
+// Read implements io.Reader.
+func (t *tailReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if t.ctx.Err() != nil {
+			return 0, contextError(t.ctx)
+		}
+		if t.stopped {
+			return 0, io.EOF
+		}
+
+		n, err := t.r.Read(p)
+		if n > 0 {
+			if r, ok := t.b.(interface{ Reset() }); ok {
+				r.Reset()
+			}
+			// Any EOF is reported by the next call, once the backoff stops.
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return n, err
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		d, stop := t.b.Next()
+		if stop {
+			t.stopped = true
+			return 0, io.EOF
+		}
+		if err := t.clock.Sleep(t.ctx, d); err != nil {
+			if t.ctx.Err() != nil {
+				return 0, contextError(t.ctx)
+			}
+			return 0, err
+		}
+	}
+}
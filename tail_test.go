@@ -0,0 +1,201 @@
+package retry_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// growingBuffer is a buffer that a producer appends to while it is read. Like a
+// file that is still being written, it returns io.EOF when it has no data.
+type growingBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *growingBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *growingBuffer) Read(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Read(p)
+}
+
+// burstClock is a fake clock that writes the next burst of a producer to a
+// buffer on given sleeps, so tests can interleave writes and reads
+// deterministically.
+type burstClock struct {
+	*fakeClock
+	buf    *growingBuffer
+	bursts map[int]string
+	slept  []time.Duration
+}
+
+func (c *burstClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := c.fakeClock.Sleep(ctx, d); err != nil {
+		return err
+	}
+	c.slept = append(c.slept, d)
+	if burst, ok := c.bursts[len(c.slept)]; ok {
+		_, _ = c.buf.Write([]byte(burst))
+	}
+	return nil
+}
+
+func TestTailReader(t *testing.T) {
+	t.Parallel()
+
+	// Waits of 10ms, 20ms, and 30ms, then stop. Reset starts over.
+	newBackoff := func() retry.Backoff {
+		return retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+			return time.Duration(retry) * 10 * time.Millisecond, retry > 3
+		})
+	}
+
+	t.Run("bursts", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &growingBuffer{}
+		_, _ = buf.Write([]byte("hello "))
+		clock := &burstClock{
+			fakeClock: newFakeClock(),
+			buf:       buf,
+			bursts: map[int]string{
+				// After the second wait.
+				2: "tailing ",
+				// After the third wait following that burst.
+				5: "world",
+			},
+		}
+
+		r := retry.TailReader(context.Background(), newBackoff(), buf, retry.WithClock(clock))
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(got), "hello tailing world"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+
+		// Each burst resets the backoff, and the reader stops after the final
+		// schedule.
+		if got, want := fmt.Sprint(clock.slept), "[10ms 20ms 10ms 20ms 30ms 10ms 20ms 30ms]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+
+		// The reader keeps returning EOF.
+		if n, err := r.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+			t.Errorf("expected 0, EOF, got %d, %v", n, err)
+		}
+	})
+
+	t.Run("small_reads", func(t *testing.T) {
+		t.Parallel()
+
+		buf := &growingBuffer{}
+		_, _ = buf.Write([]byte("abcdef"))
+		clock := &burstClock{fakeClock: newFakeClock(), buf: buf, bursts: map[int]string{1: "ghi"}}
+		r := retry.TailReader(context.Background(), newBackoff(), buf, retry.WithClock(clock))
+
+		// Reads never return more than the caller's slice.
+		var out []string
+		p := make([]byte, 4)
+		for {
+			n, err := r.Read(p)
+			if n > 0 {
+				out = append(out, string(p[:n]))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got, want := strings.Join(out, "|"), "abcd|ef|ghi"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("read_error", func(t *testing.T) {
+		t.Parallel()
+
+		errBroken := errors.New("broken")
+		r := retry.TailReader(context.Background(), newBackoff(), io.MultiReader(
+			strings.NewReader("data"), errorReader{errBroken}), retry.WithClock(newFakeClock()))
+
+		got, err := io.ReadAll(r)
+		if got, want := err, errBroken; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := string(got), "data"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		buf := &growingBuffer{}
+		r := retry.TailReader(ctx, retry.NewConstant(1*time.Millisecond), buf)
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := io.ReadAll(r)
+			done <- err
+		}()
+
+		_, _ = buf.Write([]byte("partial"))
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			if got, want := err, context.Canceled; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("reader did not stop")
+		}
+	})
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func ExampleTailReader() {
+	ctx := context.Background()
+
+	var producer bytes.Buffer
+	producer.WriteString("line 1\nline 2\n")
+
+	// Wait up to about 3s in total for more data after the last write.
+	b := retry.NewAttemptBackoff(func(retry uint64) (time.Duration, bool) {
+		return time.Duration(retry) * 100 * time.Millisecond, retry > 7
+	})
+	r := retry.TailReader(ctx, b, &producer)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		// handle error
+	}
+	_ = data
+}
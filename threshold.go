@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+var _ ObserverBackoff = (*failureThresholdBackoff)(nil)
+
+// WithFailureThreshold tolerates flapping failures: for the first n-1
+// consecutive failures it retries immediately, with a delay of 0, without
+// consulting next. From the nth consecutive failure on, delays come from next.
+// A successful attempt resets the count, which the retry loop reports through
+// [ObserverBackoff], so the backoff is typically shared by every run of a
+// health check.
+//
+// Immediate retries are not counted by the budgets of next, such as
+// [WithMaxRetries], but are counted by budgets applied outside of the returned
+// backoff. Once next stops, the returned backoff keeps stopping.
+//
+// It panics if n is 0 or next is nil. It is safe for concurrent use if next is
+// safe for concurrent use.
+func WithFailureThreshold(n uint64, next Backoff) Backoff {
+	return must(WithFailureThresholdE(n, next))
+}
+
+// WithFailureThresholdE is like [WithFailureThreshold], but returns an error
+// instead of panicking if the arguments are invalid.
+func WithFailureThresholdE(n uint64, next Backoff) (Backoff, error) {
+	if n == 0 {
+		return nil, &ValidationError{Field: "n", Reason: "must be greater than 0"}
+	}
+	if err := validateNext(next); err != nil {
+		return nil, err
+	}
+
+	return &failureThresholdBackoff{
+		n:    n,
+		next: next,
+	}, nil
+}
+
+type failureThresholdBackoff struct {
+	n    uint64
+	next Backoff
+
+	failures atomic.Uint64
+	stopped  atomic.Bool
+}
+
+// Next implements Backoff.
+func (b *failureThresholdBackoff) Next() (time.Duration, bool) {
+	if b.stopped.Load() {
+		return 0, true
+	}
+	if b.failures.Add(1) < b.n {
+		return 0, false
+	}
+
+	val, stop := b.next.Next()
+	if stop {
+		b.stopped.Store(true)
+		return 0, true
+	}
+	return val, false
+}
+
+// Observe implements ObserverBackoff. A successful attempt resets the count of
+// consecutive failures.
+func (b *failureThresholdBackoff) Observe(o Outcome) {
+	if o.Err == nil {
+		b.failures.Store(0)
+	}
+}
+
+// Threshold returns the number of consecutive failures from which next is
+// consulted.
+func (b *failureThresholdBackoff) Threshold() uint64 {
+	return b.n
+}
+
+// Unwrap implements Wrapper.
+func (b *failureThresholdBackoff) Unwrap() Backoff {
+	return b.next
+}
@@ -0,0 +1,91 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestWithFailureThreshold(t *testing.T) {
+	t.Parallel()
+
+	errFlap := errors.New("flap")
+
+	t.Run("consults_next_from_nth_failure", func(t *testing.T) {
+		t.Parallel()
+
+		var consulted []uint64
+		var attempt uint64
+		next := retry.BackoffFunc(func() (time.Duration, bool) {
+			consulted = append(consulted, attempt)
+			return 1 * time.Nanosecond, false
+		})
+		b := retry.WithFailureThreshold(3, next)
+
+		// F, S, F, F, F, S across two checks sharing the backoff.
+		pattern := []bool{false, true, false, false, false, true}
+		var delays []time.Duration
+		check := func() error {
+			return retry.Do(context.Background(), b, func(_ context.Context) error {
+				ok := pattern[attempt]
+				attempt++
+				if ok {
+					return nil
+				}
+				return retry.RetryableError(errFlap)
+			}, retry.WithOutcomeObserver(func(o retry.Outcome) {
+				if o.Err != nil {
+					delays = append(delays, o.Delay)
+				}
+			}))
+		}
+		for i := 0; i < 2; i++ {
+			if err := check(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got, want := fmt.Sprint(consulted), "[5]"; got != want {
+			t.Errorf("expected next to be consulted after attempts %v, not %v", want, got)
+		}
+		if got, want := fmt.Sprint(delays), "[0s 0s 0s 1ns]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("not_counted_by_inner_max_retries", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		b := retry.WithFailureThreshold(3, retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)))
+		err := retry.Do(context.Background(), b, func(_ context.Context) error {
+			calls++
+			return retry.RetryableError(errFlap)
+		})
+		if !errors.Is(err, errFlap) {
+			t.Errorf("expected %v to be %v", err, errFlap)
+		}
+
+		// Two immediate retries, one from the inner budget, then a stop.
+		if got, want := calls, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("sticky_after_success", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithFailureThreshold(1, retry.WithMaxRetries(0, retry.NewConstant(1*time.Nanosecond)))
+		if _, stop := b.Next(); !stop {
+			t.Fatal("expected stop")
+		}
+		b.(retry.ObserverBackoff).Observe(retry.Outcome{Attempt: 1})
+		if _, stop := b.Next(); !stop {
+			t.Error("expected stop after success")
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Tier is one implementation passed to [DoTiered], along with the backoff of
+// its retry loop.
+type Tier[T any] struct {
+	// Backoff is the backoff of the tier's retry loop, typically with a small
+	// budget.
+	Backoff Backoff
+
+	// Func is the implementation.
+	Func RetryFuncValue[T]
+}
+
+// DoTiered degrades through tiers, an ordered list of increasingly degraded
+// implementations such as a primary region, a secondary region, and a stale
+// cache. Each tier runs under its own retry loop, like [DoValue], and the value
+// of the first tier to succeed is returned. A tier that exhausts its backoff or
+// fails with a permanent error moves on to the next tier.
+//
+// If every tier fails, DoTiered returns an error joining the final error of
+// each tier, in order, labeled with the tier's index. Errors are compacted
+// with the function set by [WithErrorCompaction]. If ctx is done, DoTiered
+// returns the context's error without trying the remaining tiers. If tiers is
+// empty, it returns [ErrNoFunctions]. The options apply to every tier.
+func DoTiered[T any](ctx context.Context, tiers []Tier[T], opts ...DoOption) (T, error) {
+	var zero T
+	if len(tiers) == 0 {
+		return zero, ErrNoFunctions
+	}
+
+	cfg := newDoConfig(opts)
+
+	errs := make([]error, 0, len(tiers))
+	for i, tier := range tiers {
+		val, err := DoValue(ctx, tier.Backoff, tier.Func, opts...)
+		if err == nil {
+			return val, nil
+		}
+		if ctx.Err() != nil {
+			return zero, contextError(ctx)
+		}
+		errs = append(errs, fmt.Errorf("tier %d: %w", i, cfg.compactError(err)))
+	}
+	return zero, errors.Join(errs...)
+}
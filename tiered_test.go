@@ -0,0 +1,164 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestDoTiered(t *testing.T) {
+	t.Parallel()
+
+	errPrimary := errors.New("primary unavailable")
+	errSecondary := errors.New("secondary unavailable")
+	errCache := errors.New("cache miss")
+
+	t.Run("degrades", func(t *testing.T) {
+		t.Parallel()
+
+		var calls [3]int
+		val, err := retry.DoTiered(context.Background(), []retry.Tier[string]{
+			{
+				Backoff: retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[0]++
+					return "", retry.RetryableError(errPrimary)
+				},
+			},
+			{
+				Backoff: retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[1]++
+					if calls[1] < 2 {
+						return "", retry.RetryableError(errSecondary)
+					}
+					return "secondary", nil
+				},
+			},
+			{
+				Backoff: retry.WithMaxRetries(2, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[2]++
+					return "stale", nil
+				},
+			},
+		}, retry.WithClock(newFakeClock()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := val, "secondary"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := fmt.Sprint(calls), "[3 2 0]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("all_fail", func(t *testing.T) {
+		t.Parallel()
+
+		var calls [3]int
+		_, err := retry.DoTiered(context.Background(), []retry.Tier[string]{
+			{
+				Backoff: retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[0]++
+					return "", retry.RetryableError(errPrimary)
+				},
+			},
+			{
+				// A permanent error also moves on.
+				Backoff: retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[1]++
+					return "", errSecondary
+				},
+			},
+			{
+				Backoff: retry.WithMaxRetries(1, retry.NewConstant(1*time.Second)),
+				Func: func(_ context.Context) (string, error) {
+					calls[2]++
+					return "", retry.RetryableError(errCache)
+				},
+			},
+		}, retry.WithClock(newFakeClock()))
+
+		for _, want := range []error{errPrimary, errSecondary, errCache} {
+			if !errors.Is(err, want) {
+				t.Errorf("expected %v to be %v", err, want)
+			}
+		}
+		if got, want := err.Error(), "tier 0: primary unavailable\ntier 1: secondary unavailable\ntier 2: cache miss"; got != want {
+			t.Errorf("expected %q to be %q", got, want)
+		}
+		if got, want := fmt.Sprint(calls), "[2 1 2]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls [2]int
+		_, err := retry.DoTiered(ctx, []retry.Tier[string]{
+			{
+				Backoff: retry.NewConstant(1 * time.Second),
+				Func: func(_ context.Context) (string, error) {
+					calls[0]++
+					cancel()
+					return "", retry.RetryableError(errPrimary)
+				},
+			},
+			{
+				Backoff: retry.NewConstant(1 * time.Second),
+				Func: func(_ context.Context) (string, error) {
+					calls[1]++
+					return "secondary", nil
+				},
+			},
+		}, retry.WithClock(newFakeClock()))
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := fmt.Sprint(calls), "[1 0]"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		if _, err := retry.DoTiered[string](context.Background(), nil); !errors.Is(err, retry.ErrNoFunctions) {
+			t.Errorf("expected %v to be %v", err, retry.ErrNoFunctions)
+		}
+	})
+}
+
+func ExampleDoTiered() {
+	ctx := context.Background()
+
+	val, err := retry.DoTiered(ctx, []retry.Tier[string]{
+		{
+			Backoff: retry.WithMaxRetries(2, retry.NewExponential(10*time.Millisecond)),
+			Func: func(_ context.Context) (string, error) {
+				return "", retry.RetryableError(errors.New("primary region unavailable"))
+			},
+		},
+		{
+			Backoff: retry.WithMaxRetries(2, retry.NewExponential(10*time.Millisecond)),
+			Func: func(_ context.Context) (string, error) {
+				return "from secondary region", nil
+			},
+		},
+	})
+	if err != nil {
+		// handle error
+	}
+	fmt.Println(val)
+	// Output: from secondary region
+}
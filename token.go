@@ -0,0 +1,78 @@
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Token is an explicit cancellation signal for callers that cannot pass a
+// [context.Context], such as host functions of a WebAssembly runtime. Cancel
+// closes the channel returned by Done. Calling Cancel more than once has no
+// effect.
+type Token interface {
+	Cancel()
+	Done() <-chan struct{}
+}
+
+// NewToken returns a Token that is not yet canceled. It is safe for concurrent
+// use.
+func NewToken() Token {
+	return &token{done: make(chan struct{})}
+}
+
+type token struct {
+	once sync.Once
+	done chan struct{}
+}
+
+func (t *token) Cancel() {
+	t.once.Do(func() {
+		close(t.done)
+	})
+}
+
+func (t *token) Done() <-chan struct{} {
+	return t.done
+}
+
+// DoWithToken wraps a function with a backoff to retry, like [Do], but stops
+// when tok is canceled instead of when a context is done. The function is
+// called without a context.
+//
+// Attempts, sleeps, and errors are the same as for Do. When tok is canceled,
+// the returned error matches [context.Canceled] with [errors.Is], and also the
+// error from the last attempt if one failed. For full control over waiting
+// between attempts, use an [Attempter] instead.
+func DoWithToken(tok Token, b Backoff, f func() error, opts ...DoOption) error {
+	return Do(tokenContext{tok}, b, func(context.Context) error {
+		return f()
+	}, opts...)
+}
+
+// tokenContext adapts a Token to a context.Context for the retry loop. Unlike
+// a context derived with context.WithCancel, it starts no goroutine.
+type tokenContext struct {
+	tok Token
+}
+
+func (c tokenContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c tokenContext) Done() <-chan struct{} {
+	return c.tok.Done()
+}
+
+func (c tokenContext) Err() error {
+	select {
+	case <-c.tok.Done():
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
+func (c tokenContext) Value(any) any {
+	return nil
+}
@@ -0,0 +1,152 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+
+	tok := retry.NewToken()
+	select {
+	case <-tok.Done():
+		t.Fatal("expected token not to be canceled")
+	default:
+	}
+
+	tok.Cancel()
+	tok.Cancel()
+	select {
+	case <-tok.Done():
+	default:
+		t.Fatal("expected token to be canceled")
+	}
+}
+
+func TestDoWithToken(t *testing.T) {
+	t.Parallel()
+
+	errOops := fmt.Errorf("oops")
+
+	t.Run("exit_on_max_attempt", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(func() (time.Duration, bool) {
+			return 1 * time.Nanosecond, false
+		}))
+
+		var i int
+		err := retry.DoWithToken(retry.NewToken(), b, func() error {
+			i++
+			return retry.RetryableError(errOops)
+		})
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
+		if got, want := i, 4; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("exit_on_non_retryable", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		err := retry.DoWithToken(retry.NewToken(), b, func() error {
+			i++
+			return errOops
+		})
+		if got, want := err, errOops; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewConstant(1 * time.Nanosecond)
+
+		var i int
+		if err := retry.DoWithToken(retry.NewToken(), b, func() error {
+			i++
+			if i < 3 {
+				return retry.RetryableError(errOops)
+			}
+			return nil
+		}); err != nil {
+			t.Errorf("expected %v to be nil", err)
+		}
+		if got, want := i, 3; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("pre_canceled", func(t *testing.T) {
+		t.Parallel()
+
+		tok := retry.NewToken()
+		tok.Cancel()
+
+		err := retry.DoWithToken(tok, retry.NewConstant(1*time.Nanosecond), func() error {
+			t.Error("expected no attempts")
+			return nil
+		})
+		if got, want := err, context.Canceled; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("canceled_during_sleep", func(t *testing.T) {
+		t.Parallel()
+
+		tok := retry.NewToken()
+
+		start := time.Now()
+		var i int
+		err := retry.DoWithToken(tok, retry.NewConstant(1*time.Hour), func() error {
+			i++
+			time.AfterFunc(5*time.Millisecond, tok.Cancel)
+			return retry.RetryableError(errOops)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v to be %v", err, context.Canceled)
+		}
+		if !errors.Is(err, errOops) {
+			t.Errorf("expected %v to be %v", err, errOops)
+		}
+		if got, want := i, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected cancellation to be prompt, took %v", elapsed)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		var reason retry.StopReason
+		err := retry.DoWithToken(retry.NewToken(), retry.WithMaxRetries(1, retry.NewConstant(1*time.Nanosecond)), func() error {
+			return retry.RetryableError(errOops)
+		}, retry.WithStopHook(func(r retry.StopReason, _ error) {
+			reason = r
+		}))
+		if !errors.Is(err, retry.ErrExhausted) {
+			t.Errorf("expected %v to be %v", err, retry.ErrExhausted)
+		}
+		if got, want := reason, retry.ReasonStopped; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package retry
+
+import "time"
+
+// UpcomingTimes returns the absolute times of the next k retries of b, if the
+// first attempt is made at now, ignoring the time spent in each attempt. It is
+// intended for schedulers that register wake-ups ahead of time. If b stops
+// before k retries, fewer than k times are returned.
+//
+// UpcomingTimes calls b.Next for each retry, so it consumes b's state: pass a
+// fresh backoff, and do not reuse it for retrying. Jittered delays are the
+// values actually drawn. Wrappers that measure elapsed time, such as
+// [WithMaxDuration], are not advanced to the returned times, and only stop once
+// their duration has passed in real time.
+func UpcomingTimes(b Backoff, now time.Time, k int) []time.Time {
+	if k <= 0 {
+		return nil
+	}
+
+	// b may stop long before k retries, so a large k is not allocated up front.
+	times := make([]time.Time, 0, min(k, 64))
+	for len(times) < k {
+		next, stop := b.Next()
+		if stop {
+			break
+		}
+		now = now.Add(next)
+		times = append(times, now)
+	}
+	return times
+}
@@ -0,0 +1,72 @@
+package retry_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestUpcomingTimes(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name string
+		b    retry.Backoff
+		k    int
+		exp  []time.Time
+	}{
+		{
+			name: "capped_exponential",
+			b:    retry.WithCappedDuration(5*time.Second, retry.NewExponential(1*time.Second)),
+			k:    5,
+			exp: []time.Time{
+				now.Add(1 * time.Second),
+				now.Add(3 * time.Second),
+				now.Add(7 * time.Second),
+				now.Add(12 * time.Second),
+				now.Add(17 * time.Second),
+			},
+		},
+		{
+			name: "stops_early",
+			b:    retry.WithMaxRetries(2, retry.NewExponential(1*time.Second)),
+			k:    5,
+			exp: []time.Time{
+				now.Add(1 * time.Second),
+				now.Add(3 * time.Second),
+			},
+		},
+		{
+			name: "large_k",
+			b:    retry.NewSchedule(1*time.Second, 2*time.Second),
+			k:    math.MaxInt,
+			exp: []time.Time{
+				now.Add(1 * time.Second),
+				now.Add(3 * time.Second),
+			},
+		},
+		{
+			name: "zero",
+			b:    retry.NewConstant(1 * time.Second),
+			k:    0,
+			exp:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got, want := retry.UpcomingTimes(tc.b, now, tc.k), tc.exp; !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+}
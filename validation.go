@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// ValidationError is returned by the error-returning constructors, such as
+// [NewConstantE], when an argument is invalid.
+type ValidationError struct {
+	// Field is the name of the invalid argument or policy field.
+	Field string
+
+	// Reason describes why the value is invalid.
+	Reason string
+}
+
+// Error returns the error string.
+func (e *ValidationError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+// must panics with the message of err if err is non-nil, and otherwise returns
+// v. The panicking constructors are implemented with it on top of their
+// error-returning twins.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err.Error())
+	}
+	return v
+}
+
+// validatePositive returns a validation error if d is not greater than 0.
+func validatePositive(field string, d time.Duration) error {
+	if d <= 0 {
+		return &ValidationError{Field: field, Reason: "must be greater than 0"}
+	}
+	return nil
+}
+
+// validateJitter returns a validation error if j is negative, or if the
+// jitter range 2*j would overflow.
+func validateJitter(field string, j time.Duration) error {
+	if j < 0 {
+		return &ValidationError{Field: field, Reason: "must not be negative"}
+	}
+	if j > math.MaxInt64/2 {
+		return &ValidationError{Field: field, Reason: "must not be greater than half the maximum duration"}
+	}
+	return nil
+}
+
+// validateNext returns a validation error if next is nil.
+func validateNext(next Backoff) error {
+	if next == nil {
+		return &ValidationError{Field: "next", Reason: "must not be nil"}
+	}
+	return nil
+}
+
+// TryBuild builds a new backoff from the policy p. Unlike [Policy.Backoff], it
+// never panics; instead it returns every validation error, joined, with the
+// name of the offending field.
+func TryBuild(p Policy) (Backoff, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p.build(), nil
+}
+
+// Validate returns every validation error in the policy, joined, or nil if the
+// policy is valid.
+func (p Policy) Validate() error {
+	var errs []error
+	if p.Algorithm < AlgorithmConstant || p.Algorithm > AlgorithmFibonacci {
+		errs = append(errs, &ValidationError{Field: "Algorithm", Reason: "is not a known algorithm"})
+	}
+	if err := validatePositive("Base", p.Base); err != nil {
+		errs = append(errs, err)
+	}
+	if p.Jitter != 0 {
+		if err := validateJitter("Jitter", p.Jitter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if p.JitterPercent > 100 {
+		errs = append(errs, &ValidationError{Field: "JitterPercent", Reason: "must not be greater than 100"})
+	}
+	if p.Cap < 0 {
+		errs = append(errs, &ValidationError{Field: "Cap", Reason: "must not be negative"})
+	}
+	if p.MaxDuration < 0 {
+		errs = append(errs, &ValidationError{Field: "MaxDuration", Reason: "must not be negative"})
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,300 @@
+package retry_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+func TestConstructorsE(t *testing.T) {
+	t.Parallel()
+
+	next := retry.NewConstant(1 * time.Second)
+
+	cases := []struct {
+		name  string
+		build func() (retry.Backoff, error)
+		field string
+	}{
+		{"constant_zero", func() (retry.Backoff, error) { return retry.NewConstantE(0) }, "t"},
+		{"constant_negative", func() (retry.Backoff, error) { return retry.NewConstantE(-1) }, "t"},
+		{"linear_zero", func() (retry.Backoff, error) { return retry.NewLinearE(0) }, "base"},
+		{"exponential_zero", func() (retry.Backoff, error) { return retry.NewExponentialE(0) }, "base"},
+		{"exponential_factor_base", func() (retry.Backoff, error) { return retry.NewExponentialWithFactorE(0, 2) }, "base"},
+		{"exponential_factor_one", func() (retry.Backoff, error) { return retry.NewExponentialWithFactorE(1, 1) }, "factor"},
+		{"decorrelated_jitter_zero", func() (retry.Backoff, error) { return retry.NewDecorrelatedJitterE(0, 1) }, "base"},
+		{"decorrelated_jitter_cap", func() (retry.Backoff, error) { return retry.NewDecorrelatedJitterE(2, 1) }, "cap"},
+		{"failure_threshold_zero", func() (retry.Backoff, error) { return retry.WithFailureThresholdE(0, next) }, "n"},
+		{"failure_threshold_nil", func() (retry.Backoff, error) { return retry.WithFailureThresholdE(1, nil) }, "next"},
+		{"local_refunds_negative", func() (retry.Backoff, error) { return retry.WithLocalRefundsE(1, -1, next) }, "delay"},
+		{"local_refunds_nil", func() (retry.Backoff, error) { return retry.WithLocalRefundsE(1, 0, nil) }, "next"},
+		{"fibonacci_negative", func() (retry.Backoff, error) { return retry.NewFibonacciE(-1) }, "base"},
+		{"fibonacci_capped_negative", func() (retry.Backoff, error) { return retry.NewFibonacciCappedE(-1, 1) }, "base"},
+		{"fibonacci_capped_below_base", func() (retry.Backoff, error) { return retry.NewFibonacciCappedE(2, 1) }, "max"},
+		{"adaptive_cutoff_model", func() (retry.Backoff, error) { return retry.WithAdaptiveCutoffE(nil, 0.5, next) }, "m"},
+		{"adaptive_cutoff_nan", func() (retry.Backoff, error) {
+			return retry.WithAdaptiveCutoffE(retry.NewSuccessModel(1), math.NaN(), next)
+		}, "minProb"},
+		{"adaptive_cutoff_negative", func() (retry.Backoff, error) { return retry.WithAdaptiveCutoffE(retry.NewSuccessModel(1), -0.1, next) }, "minProb"},
+		{"adaptive_cutoff_large", func() (retry.Backoff, error) { return retry.WithAdaptiveCutoffE(retry.NewSuccessModel(1), 1.1, next) }, "minProb"},
+		{"adaptive_cutoff_nil", func() (retry.Backoff, error) { return retry.WithAdaptiveCutoffE(retry.NewSuccessModel(1), 0.5, nil) }, "next"},
+		{"attempt_nil", func() (retry.Backoff, error) { return retry.NewAttemptBackoffE(nil) }, "f"},
+		{"schedule_empty", func() (retry.Backoff, error) { return retry.NewScheduleE() }, "durations"},
+		{"schedule_zero", func() (retry.Backoff, error) { return retry.NewScheduleE(1, 0) }, "durations[1]"},
+		{"schedule_repeat_last_negative", func() (retry.Backoff, error) { return retry.NewScheduleRepeatLastE(-1) }, "durations[0]"},
+		{"jitter_negative", func() (retry.Backoff, error) { return retry.WithJitterE(-1, next) }, "j"},
+		{"jitter_overflow", func() (retry.Backoff, error) { return retry.WithJitterE(math.MaxInt64, next) }, "j"},
+		{"jitter_nil", func() (retry.Backoff, error) { return retry.WithJitterE(1, nil) }, "next"},
+		{"jitter_percent_large", func() (retry.Backoff, error) { return retry.WithJitterPercentE(101, next) }, "j"},
+		{"full_jitter_nil", func() (retry.Backoff, error) { return retry.WithFullJitterE(nil) }, "next"},
+		{"equal_jitter_nil", func() (retry.Backoff, error) { return retry.WithEqualJitterE(nil) }, "next"},
+		{"max_retries_nil", func() (retry.Backoff, error) { return retry.WithMaxRetriesE(1, nil) }, "next"},
+		{"capped_nil", func() (retry.Backoff, error) { return retry.WithCappedDurationE(1, nil) }, "next"},
+		{"min_duration_negative", func() (retry.Backoff, error) { return retry.WithMinDurationE(-1, next) }, "min"},
+		{"min_duration_nil", func() (retry.Backoff, error) { return retry.WithMinDurationE(1, nil) }, "next"},
+		{"max_duration_nil", func() (retry.Backoff, error) { return retry.WithMaxDurationE(1, nil) }, "next"},
+		{"max_sleep_negative", func() (retry.Backoff, error) { return retry.WithMaxSleepE(-1, next) }, "budget"},
+		{"max_sleep_nil", func() (retry.Backoff, error) { return retry.WithMaxSleepE(1, nil) }, "next"},
+		{"quantized_zero", func() (retry.Backoff, error) { return retry.WithQuantizedDelayE(0, retry.RoundUp, next) }, "quantum"},
+		{"quantized_mode", func() (retry.Backoff, error) { return retry.WithQuantizedDelayE(1, retry.RoundMode(-1), next) }, "mode"},
+		{"error_budgets_classify", func() (retry.Backoff, error) { return retry.WithErrorBudgetsE(nil, nil, next) }, "classify"},
+		{"lease_remaining", func() (retry.Backoff, error) { return retry.WithLeaseE(nil, 0, next) }, "remaining"},
+		{"startup_splay_zero", func() (retry.Backoff, error) { return retry.WithStartupSplayE(0, next) }, "max"},
+		{"startup_splay_nil", func() (retry.Backoff, error) { return retry.WithStartupSplayE(1, nil) }, "next"},
+		{"auto_reset_zero", func() (retry.Backoff, error) { return retry.WithAutoResetE(0, retry.NewSchedule(1)) }, "idle"},
+		{"auto_reset_nil", func() (retry.Backoff, error) { return retry.WithAutoResetE(1, nil) }, "next"},
+		{"auto_reset_not_resettable", func() (retry.Backoff, error) {
+			return retry.WithAutoResetE(1, retry.BackoffFunc(func() (time.Duration, bool) { return 1, false }))
+		}, "next"},
+		{"auto_reset_not_resettable_middleware", func() (retry.Backoff, error) {
+			return retry.WithAutoResetE(1, retry.WithFailureThreshold(3, retry.NewExponential(1)))
+		}, "next"},
+		{"leader_only_lock", func() (retry.Backoff, error) { return retry.WithLeaderOnlyE(nil, next) }, "lock"},
+		{"leader_only_nil", func() (retry.Backoff, error) { return retry.WithLeaderOnlyE(retry.NewLocalLocker(), nil) }, "next"},
+		{"lease_margin", func() (retry.Backoff, error) { return retry.WithLeaseE(func() time.Duration { return 1 }, -1, next) }, "margin"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b, err := tc.build()
+			if b != nil {
+				t.Errorf("expected nil backoff, got %v", b)
+			}
+
+			var verr *retry.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if got, want := verr.Field, tc.field; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		})
+	}
+
+	t.Run("calendar", func(t *testing.T) {
+		t.Parallel()
+
+		for _, steps := range [][]retry.CalendarStep{
+			nil,
+			{{}},
+			{{Days: 1, Duration: -1}},
+		} {
+			b, err := retry.NewCalendarBackoffE(steps)
+			if b != nil {
+				t.Errorf("expected nil backoff, got %v", b)
+			}
+
+			var verr *retry.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if got, want := verr.Field, "steps"; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}
+	})
+
+	t.Run("error_compactor", func(t *testing.T) {
+		t.Parallel()
+
+		compact, err := retry.NewErrorCompactorE(-1)
+		if compact != nil {
+			t.Error("expected nil compactor")
+		}
+
+		var verr *retry.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if got, want := verr.Field, "maxLen"; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("panic_message", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			if got, want := recover(), "base must be greater than 0"; got != want {
+				t.Errorf("expected %v to be %v", got, want)
+			}
+		}()
+		retry.NewExponential(0)
+	})
+}
+
+func TestTryBuild(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := retry.TryBuild(retry.Policy{
+			Algorithm:   retry.AlgorithmExponential,
+			Base:        1 * time.Second,
+			MaxAttempts: 2,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if val, stop := b.Next(); stop || val != 1*time.Second {
+			t.Errorf("expected %v to be %v", val, 1*time.Second)
+		}
+		if _, stop := b.Next(); !stop {
+			t.Error("should stop")
+		}
+	})
+
+	t.Run("aggregates", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := retry.TryBuild(retry.Policy{
+			Algorithm:     retry.Algorithm(42),
+			Base:          -1,
+			Jitter:        -1,
+			JitterPercent: 200,
+			Cap:           -1,
+			MaxDuration:   -1,
+		})
+
+		fields := make(map[string]bool)
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var verr *retry.ValidationError
+			if errors.As(e, &verr) {
+				fields[verr.Field] = true
+			}
+		}
+		for _, field := range []string{"Algorithm", "Base", "Jitter", "JitterPercent", "Cap", "MaxDuration"} {
+			if !fields[field] {
+				t.Errorf("expected error for %s in %v", field, err)
+			}
+		}
+	})
+}
+
+func FuzzTryBuild(f *testing.F) {
+	f.Add(0, int64(0), int64(0), uint64(0), int64(0), uint64(0), int64(0))
+	f.Add(1, int64(time.Second), int64(time.Second), uint64(10), int64(time.Minute), uint64(5), int64(time.Hour))
+	f.Add(2, int64(math.MaxInt64), int64(math.MaxInt64), uint64(100), int64(math.MaxInt64), uint64(math.MaxUint64), int64(math.MaxInt64))
+	f.Add(-1, int64(-1), int64(math.MinInt64), uint64(math.MaxUint64), int64(-1), uint64(1), int64(math.MinInt64))
+
+	f.Fuzz(func(t *testing.T, algorithm int, base, jitter int64, jitterPercent uint64, cap int64, maxAttempts uint64, maxDuration int64) {
+		b, err := retry.TryBuild(retry.Policy{
+			Algorithm:     retry.Algorithm(algorithm),
+			Base:          time.Duration(base),
+			Jitter:        time.Duration(jitter),
+			JitterPercent: jitterPercent,
+			Cap:           time.Duration(cap),
+			MaxAttempts:   maxAttempts,
+			MaxDuration:   time.Duration(maxDuration),
+		})
+		if err != nil {
+			return
+		}
+
+		for i := 0; i < 100; i++ {
+			val, stop := b.Next()
+			if stop {
+				break
+			}
+			if val < 0 {
+				t.Fatalf("negative delay %v", val)
+			}
+		}
+	})
+}
+
+func FuzzConstructorsE(f *testing.F) {
+	f.Add(int64(0), uint64(0))
+	f.Add(int64(1), uint64(1))
+	f.Add(int64(-1), uint64(101))
+	f.Add(int64(math.MaxInt64), uint64(math.MaxUint64))
+	f.Add(int64(math.MinInt64), uint64(100))
+
+	f.Fuzz(func(t *testing.T, d int64, n uint64) {
+		dur := time.Duration(d)
+		next := retry.BackoffFunc(func() (time.Duration, bool) {
+			return dur, false
+		})
+
+		builders := []func() (retry.Backoff, error){
+			func() (retry.Backoff, error) { return retry.NewConstantE(dur) },
+			func() (retry.Backoff, error) { return retry.NewLinearE(dur) },
+			func() (retry.Backoff, error) { return retry.NewExponentialE(dur) },
+			func() (retry.Backoff, error) { return retry.NewFibonacciE(dur) },
+			func() (retry.Backoff, error) { return retry.NewFibonacciCappedE(dur, time.Duration(n)) },
+			func() (retry.Backoff, error) { return retry.NewScheduleE(dur, dur) },
+			func() (retry.Backoff, error) {
+				return retry.NewAttemptBackoffE(func(uint64) (time.Duration, bool) { return dur, false })
+			},
+			func() (retry.Backoff, error) { return retry.WithJitterE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithJitterPercentE(n, next) },
+			func() (retry.Backoff, error) { return retry.WithFullJitterE(next) },
+			func() (retry.Backoff, error) { return retry.WithEqualJitterE(next) },
+			func() (retry.Backoff, error) { return retry.WithMaxRetriesE(n, next) },
+			func() (retry.Backoff, error) { return retry.WithCappedDurationE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithMinDurationE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithMaxDurationE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithMaxSleepE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithQuantizedDelayE(dur, retry.RoundMode(n), next) },
+			func() (retry.Backoff, error) { return retry.WithStartupSplayE(dur, next) },
+			func() (retry.Backoff, error) { return retry.WithAutoResetE(dur, retry.NewScheduleRepeatLast(1, 2)) },
+			func() (retry.Backoff, error) { return retry.WithLeaseE(func() time.Duration { return dur }, dur, next) },
+			func() (retry.Backoff, error) { return retry.WithLeaderOnlyE(retry.NewLocalLocker(), next) },
+		}
+
+		for _, build := range builders {
+			b, err := build()
+			if err != nil {
+				continue
+			}
+			for i := 0; i < 10; i++ {
+				if _, stop := b.Next(); stop {
+					break
+				}
+			}
+		}
+	})
+}
+
+func ExampleTryBuild() {
+	_, err := retry.TryBuild(retry.Policy{
+		Algorithm:     retry.AlgorithmExponential,
+		Base:          0,
+		JitterPercent: 150,
+	})
+	fmt.Println(err)
+
+	// Output:
+	// Base must be greater than 0
+	// JitterPercent must not be greater than 100
+}
@@ -0,0 +1,74 @@
+package retry
+
+var (
+	_ Wrapper = (*jitterBackoff)(nil)
+	_ Wrapper = (*jitterPercentBackoff)(nil)
+	_ Wrapper = (*fullJitterBackoff)(nil)
+	_ Wrapper = (*maxRetriesBackoff)(nil)
+	_ Wrapper = (*cappedDurationBackoff)(nil)
+	_ Wrapper = (*minDurationBackoff)(nil)
+	_ Wrapper = (*maxDurationBackoff)(nil)
+	_ Wrapper = (*maxSleepBackoff)(nil)
+	_ Wrapper = (*quantizedDelayBackoff)(nil)
+	_ Wrapper = (*errorBudgetsBackoff)(nil)
+	_ Wrapper = (*adaptiveCutoffBackoff)(nil)
+	_ Wrapper = (*leaderBackoff)(nil)
+	_ Wrapper = (*leaseBackoff)(nil)
+	_ Wrapper = (*startupSplayBackoff)(nil)
+	_ Wrapper = (*failureThresholdBackoff)(nil)
+	_ Wrapper = (*localRefundsBackoff)(nil)
+	_ Wrapper = (*budgetBackoff)(nil)
+	_ Wrapper = (*autoResetBackoff)(nil)
+)
+
+// Wrapper is a Backoff that wraps another backoff. Every middleware in this
+// package implements Wrapper.
+//
+// Built-in backoffs also expose their configuration through typed getters,
+// which can be reached with an interface assertion while walking a chain with
+// [Walk]:
+//
+//   - Base() time.Duration on [NewConstant], [NewLinear], [NewExponential],
+//     [NewExponentialWithFactor], [NewFibonacci], [NewFibonacciCapped], and
+//     [NewDecorrelatedJitter]
+//   - Factor() float64 on [NewExponentialWithFactor]
+//   - Durations() []time.Duration on [NewSchedule] and [NewScheduleRepeatLast]
+//   - Jitter() time.Duration on [WithJitter]
+//   - JitterPercent() uint64 on [WithJitterPercent]
+//   - MaxRetries() uint64 on [WithMaxRetries]
+//   - Cap() time.Duration on [WithCappedDuration], [NewFibonacciCapped], and
+//     [NewDecorrelatedJitter]
+//   - MinDuration() time.Duration on [WithMinDuration]
+//   - MaxDuration() time.Duration on [WithMaxDuration]
+//   - MaxSleep() time.Duration on [WithMaxSleep]
+//   - Quantum() time.Duration and RoundMode() RoundMode on [WithQuantizedDelay]
+//   - Budgets() map[string]uint64 on [WithErrorBudgets]
+//   - Retries() uint64 on [NewAttemptBackoff]
+//   - Margin() time.Duration on [WithLease]
+//   - Splay() time.Duration on [WithStartupSplay]
+//   - Threshold() uint64 on [WithFailureThreshold]
+//   - MaxRefunds() uint64 on [WithLocalRefunds]
+//   - Idle() time.Duration on [WithAutoReset]
+type Wrapper interface {
+	Backoff
+
+	// Unwrap returns the wrapped backoff.
+	Unwrap() Backoff
+}
+
+// Walk calls fn for b and then for each backoff it wraps, from the outermost to
+// the innermost, following [Wrapper]. It stops early if fn returns false, or
+// at the first backoff that does not implement Wrapper.
+func Walk(b Backoff, fn func(node Backoff) bool) {
+	for b != nil {
+		if !fn(b) {
+			return
+		}
+
+		w, ok := b.(Wrapper)
+		if !ok {
+			return
+		}
+		b = w.Unwrap()
+	}
+}
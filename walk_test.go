@@ -0,0 +1,156 @@
+package retry_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+// lintPolicy flags backoff chains that allow more than maxAttempts attempts or
+// whose delays are capped below minCap.
+func lintPolicy(b retry.Backoff, maxAttempts uint64, minCap time.Duration) []string {
+	var problems []string
+	limited := false
+
+	retry.Walk(b, func(node retry.Backoff) bool {
+		if n, ok := node.(interface{ MaxRetries() uint64 }); ok {
+			limited = true
+			if attempts := n.MaxRetries() + 1; attempts > maxAttempts {
+				problems = append(problems, fmt.Sprintf("allows %d attempts, more than %d", attempts, maxAttempts))
+			}
+		}
+		if n, ok := node.(interface{ Cap() time.Duration }); ok && n.Cap() < minCap {
+			problems = append(problems, fmt.Sprintf("caps delays at %s, less than %s", n.Cap(), minCap))
+		}
+		return true
+	})
+
+	if !limited {
+		problems = append(problems, "does not limit attempts")
+	}
+	return problems
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	t.Run("order", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.NewFibonacci(1 * time.Second)
+		b = retry.WithJitter(100*time.Millisecond, b)
+		b = retry.WithCappedDuration(10*time.Second, b)
+		b = retry.WithMaxRetries(3, b)
+
+		var names []string
+		retry.Walk(b, func(node retry.Backoff) bool {
+			switch n := node.(type) {
+			case interface{ MaxRetries() uint64 }:
+				names = append(names, fmt.Sprintf("max_retries=%d", n.MaxRetries()))
+			case interface{ Cap() time.Duration }:
+				names = append(names, fmt.Sprintf("cap=%s", n.Cap()))
+			case interface{ Jitter() time.Duration }:
+				names = append(names, fmt.Sprintf("jitter=%s", n.Jitter()))
+			case interface{ Base() time.Duration }:
+				names = append(names, fmt.Sprintf("base=%s", n.Base()))
+			}
+			return true
+		})
+
+		if got, want := names, []string{"max_retries=3", "cap=10s", "jitter=100ms", "base=1s"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("stops_early", func(t *testing.T) {
+		t.Parallel()
+
+		b := retry.WithMaxRetries(3, retry.WithCappedDuration(1*time.Second, retry.NewConstant(1*time.Second)))
+
+		var visited int
+		retry.Walk(b, func(node retry.Backoff) bool {
+			visited++
+			return false
+		})
+		if got, want := visited, 1; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+
+	t.Run("opaque", func(t *testing.T) {
+		t.Parallel()
+
+		inner := retry.NewConstant(1 * time.Second)
+		b := retry.WithMaxRetries(3, retry.BackoffFunc(inner.Next))
+
+		var visited int
+		retry.Walk(b, func(node retry.Backoff) bool {
+			visited++
+			return true
+		})
+		if got, want := visited, 2; got != want {
+			t.Errorf("expected %v to be %v", got, want)
+		}
+	})
+}
+
+func TestLintPolicy(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		b        retry.Backoff
+		problems int
+	}{
+		{
+			name: "compliant",
+			b:    retry.WithMaxRetries(4, retry.WithCappedDuration(5*time.Second, retry.NewExponential(100*time.Millisecond))),
+		},
+		{
+			name:     "too_many_attempts",
+			b:        retry.WithMaxRetries(10, retry.WithCappedDuration(5*time.Second, retry.NewExponential(100*time.Millisecond))),
+			problems: 1,
+		},
+		{
+			name:     "cap_too_low",
+			b:        retry.WithCappedDuration(100*time.Millisecond, retry.WithMaxRetries(2, retry.NewExponential(10*time.Millisecond))),
+			problems: 1,
+		},
+		{
+			name:     "unlimited",
+			b:        retry.WithJitterPercent(10, retry.NewFibonacci(1*time.Second)),
+			problems: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			problems := lintPolicy(tc.b, 5, 1*time.Second)
+			if got, want := len(problems), tc.problems; got != want {
+				t.Errorf("expected %v to be %v: %v", got, want, problems)
+			}
+		})
+	}
+}
+
+func ExampleWalk() {
+	b := retry.NewExponential(100 * time.Millisecond)
+	b = retry.WithCappedDuration(500*time.Millisecond, b)
+	b = retry.WithMaxRetries(9, b)
+
+	// Flag chains that exceed the organization's limits.
+	for _, problem := range lintPolicy(b, 5, 1*time.Second) {
+		fmt.Println(problem)
+	}
+
+	// Output:
+	// allows 10 attempts, more than 5
+	// caps delays at 500ms, less than 1s
+}